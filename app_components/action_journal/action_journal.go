@@ -0,0 +1,91 @@
+package action_journal
+
+import "fmt"
+
+// Operation is a change to the journal that can be reversed
+type Operation interface {
+	// Do makes the change; it's called again to redo the change after it's been undone
+	Do() error
+
+	// Undo reverses the change made by Do
+	Undo() error
+
+	// GetDescription describes the change for the status line, e.g. "retag 3 entries to #work"
+	GetDescription() string
+}
+
+/*
+ActionJournal records the operations that have been done, so they can be undone & redone
+
+Once an operation is undone, doing a new one throws away the ability to redo it (like in most editors)
+*/
+type ActionJournal struct {
+	// Oldest first
+	undoStack []Operation
+
+	// Most recently undone last
+	redoStack []Operation
+
+	maxLength int
+}
+
+func New(maxLength int) *ActionJournal {
+	return &ActionJournal{
+		undoStack: []Operation{},
+		redoStack: []Operation{},
+		maxLength: maxLength,
+	}
+}
+
+// Do does the operation, and records it if it succeeded
+func (journal *ActionJournal) Do(operation Operation) error {
+	if err := operation.Do(); err != nil {
+		return fmt.Errorf("couldn't %s: %w", operation.GetDescription(), err)
+	}
+
+	journal.undoStack = append(journal.undoStack, operation)
+	if len(journal.undoStack) > journal.maxLength {
+		journal.undoStack = journal.undoStack[len(journal.undoStack)-journal.maxLength:]
+	}
+	journal.redoStack = []Operation{}
+	return nil
+}
+
+// Undo reverses the most recent operation, returning it
+// If undoing fails the operation stays where it is, so the user can try again after fixing the problem
+func (journal *ActionJournal) Undo() (Operation, error) {
+	if len(journal.undoStack) == 0 {
+		return nil, fmt.Errorf("there's nothing to undo")
+	}
+
+	operation := journal.undoStack[len(journal.undoStack)-1]
+	if err := operation.Undo(); err != nil {
+		return nil, fmt.Errorf("couldn't undo '%s': %w", operation.GetDescription(), err)
+	}
+	journal.undoStack = journal.undoStack[:len(journal.undoStack)-1]
+	journal.redoStack = append(journal.redoStack, operation)
+	return operation, nil
+}
+
+// Redo re-does the most recently undone operation, returning it
+func (journal *ActionJournal) Redo() (Operation, error) {
+	if len(journal.redoStack) == 0 {
+		return nil, fmt.Errorf("there's nothing to redo")
+	}
+
+	operation := journal.redoStack[len(journal.redoStack)-1]
+	if err := operation.Do(); err != nil {
+		return nil, fmt.Errorf("couldn't redo '%s': %w", operation.GetDescription(), err)
+	}
+	journal.redoStack = journal.redoStack[:len(journal.redoStack)-1]
+	journal.undoStack = append(journal.undoStack, operation)
+	return operation, nil
+}
+
+func (journal ActionJournal) GetNumUndoable() int {
+	return len(journal.undoStack)
+}
+
+func (journal ActionJournal) GetNumRedoable() int {
+	return len(journal.redoStack)
+}
@@ -0,0 +1,102 @@
+package action_journal
+
+import (
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"testing"
+	"time"
+)
+
+func TestBulkRetagIsUndoneInOneStep(t *testing.T) {
+	store := journal_store.New(t.TempDir())
+	journal := New(10)
+	timestamp := time.Date(2023, time.April, 15, 10, 15, 0, 0, time.Local)
+
+	operations := make([]Operation, 0)
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		item, err := store.Create(timestamp, name, "old", "body")
+		if err != nil {
+			t.Fatalf("Expected no error creating the entry but got: %v", err)
+		}
+		operation, err := NewMoveOperation(store, item.Path, "new", item.Name)
+		if err != nil {
+			t.Fatalf("Expected no error creating the operation but got: %v", err)
+		}
+		operations = append(operations, operation)
+	}
+	if err := journal.Do(NewBatchOperation("retag 3 entries to #new", operations)); err != nil {
+		t.Fatalf("Expected no error retagging but got: %v", err)
+	}
+	assertAllTagged(t, store, "new")
+
+	if _, err := journal.Undo(); err != nil {
+		t.Fatalf("Expected no error undoing but got: %v", err)
+	}
+	assertAllTagged(t, store, "old")
+
+	if _, err := journal.Redo(); err != nil {
+		t.Fatalf("Expected no error redoing but got: %v", err)
+	}
+	assertAllTagged(t, store, "new")
+}
+
+func TestUndoingDeleteRestoresBody(t *testing.T) {
+	store := journal_store.New(t.TempDir())
+	journal := New(10)
+	item, err := store.Create(time.Now(), "keep.md", "", "precious words")
+	if err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+
+	if err := journal.Do(NewDeleteOperation(store, item.Path)); err != nil {
+		t.Fatalf("Expected no error deleting but got: %v", err)
+	}
+	if _, err := journal.Undo(); err != nil {
+		t.Fatalf("Expected no error undoing but got: %v", err)
+	}
+	body, err := store.Read(item.Path)
+	if err != nil || body != "precious words" {
+		t.Errorf("Expected the entry to come back, but got body '%s' and error: %v", body, err)
+	}
+}
+
+func TestUndoingMoveIntoEncryptedTagDecryptsAgain(t *testing.T) {
+	store := journal_store.New(t.TempDir()).WithEncryptedTags([]string{"health"})
+	if err := store.GetKeyring().SetPassphrase("hunter2"); err != nil {
+		t.Fatalf("Expected no error setting the passphrase but got: %v", err)
+	}
+	journal := New(10)
+	item, err := store.Create(time.Now(), "checkup.md", "inbox", "Blood pressure is fine.")
+	if err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+	operation, err := NewMoveOperation(store, item.Path, "health", item.Name)
+	if err != nil {
+		t.Fatalf("Expected no error creating the operation but got: %v", err)
+	}
+	if err := journal.Do(operation); err != nil {
+		t.Fatalf("Expected no error retagging but got: %v", err)
+	}
+
+	if _, err := journal.Undo(); err != nil {
+		t.Fatalf("Expected no error undoing but got: %v", err)
+	}
+	restored, err := store.Get(item.Path)
+	if err != nil || restored.IsEncrypted {
+		t.Errorf("Expected the entry to be back in plaintext, but got %+v and error: %v", restored, err)
+	}
+}
+
+func assertAllTagged(t *testing.T, store journal_store.Store, expectedTag string) {
+	items, err := store.List()
+	if err != nil {
+		t.Fatalf("Expected no error listing entries but got: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 entries but got %d", len(items))
+	}
+	for _, item := range items {
+		if len(item.Tags) != 1 || item.Tags[0] != expectedTag {
+			t.Errorf("Expected '%s' to be tagged '%s' but had tags %v", item.Name, expectedTag, item.Tags)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package action_journal
+
+import (
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"time"
+)
+
+type createOperation struct {
+	store journal_store.Store
+
+	timestamp time.Time
+	name      string
+	tag       string
+
+	// What the entry contained when it was last removed by Undo, so that redoing brings it back as it was
+	body string
+
+	// Set once the entry has been created
+	entryPath string
+}
+
+// NewCreateOperation creates a new entry with the given body
+func NewCreateOperation(store journal_store.Store, timestamp time.Time, name string, tag string, body string) Operation {
+	return &createOperation{
+		store:     store,
+		timestamp: timestamp,
+		name:      name,
+		tag:       tag,
+		body:      body,
+		entryPath: "",
+	}
+}
+
+func (op *createOperation) Do() error {
+	item, err := op.store.Create(op.timestamp, op.name, op.tag, op.body)
+	if err != nil {
+		return err
+	}
+	op.entryPath = item.Path
+	return nil
+}
+
+func (op *createOperation) Undo() error {
+	body, err := op.store.Read(op.entryPath)
+	if err != nil {
+		return err
+	}
+	if err := op.store.Delete(op.entryPath); err != nil {
+		return err
+	}
+	op.body = body
+	return nil
+}
+
+func (op createOperation) GetDescription() string {
+	return fmt.Sprintf("create '%s'", op.name)
+}
+
+// GetEntryPath gets where the created entry lives
+func (op createOperation) GetEntryPath() string {
+	return op.entryPath
+}
+
+type moveOperation struct {
+	store journal_store.Store
+
+	oldPath string
+	oldTag  string
+	oldName string
+
+	// Moving into an encrypted tag encrypts the entry, which undoing has to reverse
+	wasEncrypted bool
+
+	newTag  string
+	newName string
+
+	// Set once the entry has been moved
+	newPath string
+}
+
+// NewMoveOperation gives the entry a new tag and/or name
+func NewMoveOperation(store journal_store.Store, entryPath string, newTag string, newName string) (Operation, error) {
+	item, err := store.Get(entryPath)
+	if err != nil {
+		return nil, err
+	}
+	oldTag := ""
+	if len(item.Tags) > 0 {
+		oldTag = item.Tags[0]
+	}
+	return &moveOperation{
+		store:        store,
+		oldPath:      entryPath,
+		oldTag:       oldTag,
+		oldName:      item.Name,
+		wasEncrypted: item.IsEncrypted,
+		newTag:       newTag,
+		newName:      newName,
+		newPath:      "",
+	}, nil
+}
+
+func (op *moveOperation) Do() error {
+	item, err := op.store.Move(op.oldPath, op.newTag, op.newName)
+	if err != nil {
+		return err
+	}
+	op.newPath = item.Path
+	return nil
+}
+
+func (op *moveOperation) Undo() error {
+	item, err := op.store.Get(op.newPath)
+	if err != nil {
+		return err
+	}
+	needsDecrypting := item.IsEncrypted && !op.wasEncrypted
+	// Checked before moving, so the entry isn't left back in its old tag but still encrypted
+	if needsDecrypting {
+		if err := op.store.GetKeyring().EnsureUnlocked(); err != nil {
+			return fmt.Errorf("the entry was encrypted when it was moved, so it can't be decrypted back: %w", err)
+		}
+	}
+
+	movedBackItem, err := op.store.Move(op.newPath, op.oldTag, op.oldName)
+	if err != nil {
+		return err
+	}
+	if needsDecrypting {
+		if _, err := op.store.SetEncrypted(movedBackItem.Path, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (op moveOperation) GetDescription() string {
+	if op.oldName != op.newName {
+		return fmt.Sprintf("rename '%s' to '%s'", op.oldName, op.newName)
+	}
+	return fmt.Sprintf("retag '%s' to %s", op.oldName, journal_store.DescribeTag(op.newTag))
+}
+
+type deleteOperation struct {
+	store journal_store.Store
+
+	entryPath string
+
+	// Captured when the entry is deleted, so that undoing brings it back as it was
+	lastModified time.Time
+	body         string
+	isEncrypted  bool
+}
+
+// NewDeleteOperation deletes the entry
+func NewDeleteOperation(store journal_store.Store, entryPath string) Operation {
+	return &deleteOperation{
+		store:     store,
+		entryPath: entryPath,
+	}
+}
+
+func (op *deleteOperation) Do() error {
+	item, err := op.store.Get(op.entryPath)
+	if err != nil {
+		return err
+	}
+	body, err := op.store.Read(op.entryPath)
+	if err != nil {
+		return err
+	}
+	if err := op.store.Delete(op.entryPath); err != nil {
+		return err
+	}
+
+	op.lastModified = item.LastModified
+	op.body = body
+	op.isEncrypted = item.IsEncrypted
+	return nil
+}
+
+func (op *deleteOperation) Undo() error {
+	create := op.store.CreateAtPath
+	if op.isEncrypted {
+		create = op.store.CreateEncryptedAtPath
+	}
+	if _, err := create(op.entryPath, op.body); err != nil {
+		return err
+	}
+	return op.store.SetLastModified(op.entryPath, op.lastModified)
+}
+
+func (op deleteOperation) GetDescription() string {
+	return fmt.Sprintf("delete '%s'", op.entryPath)
+}
+
+type writeOperation struct {
+	store journal_store.Store
+
+	entryPath   string
+	description string
+
+	newBody string
+
+	// Captured when the entry is written, so that undoing puts it back
+	oldBody string
+}
+
+// NewWriteOperation replaces the entry's body, described for the status line by the given description (e.g. "restore
+// 'standup' to its version from 2023-05-01")
+func NewWriteOperation(store journal_store.Store, entryPath string, body string, description string) Operation {
+	return &writeOperation{
+		store:       store,
+		entryPath:   entryPath,
+		description: description,
+		newBody:     body,
+		oldBody:     "",
+	}
+}
+
+func (op *writeOperation) Do() error {
+	oldBody, err := op.store.Read(op.entryPath)
+	if err != nil {
+		return err
+	}
+	if _, err := op.store.Write(op.entryPath, op.newBody); err != nil {
+		return err
+	}
+	op.oldBody = oldBody
+	return nil
+}
+
+func (op *writeOperation) Undo() error {
+	_, err := op.store.Write(op.entryPath, op.oldBody)
+	return err
+}
+
+func (op writeOperation) GetDescription() string {
+	return op.description
+}
+
+type setEncryptedOperation struct {
+	store journal_store.Store
+
+	entryPath   string
+	isEncrypted bool
+
+	// Captured when the operation is done, so that undoing puts the entry back as it was
+	wasEncrypted bool
+}
+
+// NewSetEncryptedOperation encrypts or decrypts the entry on disk
+func NewSetEncryptedOperation(store journal_store.Store, entryPath string, isEncrypted bool) Operation {
+	return &setEncryptedOperation{
+		store:        store,
+		entryPath:    entryPath,
+		isEncrypted:  isEncrypted,
+		wasEncrypted: false,
+	}
+}
+
+func (op *setEncryptedOperation) Do() error {
+	item, err := op.store.Get(op.entryPath)
+	if err != nil {
+		return err
+	}
+	if item.IsEncrypted == op.isEncrypted {
+		op.wasEncrypted = item.IsEncrypted
+		return nil
+	}
+	if _, err := op.store.SetEncrypted(op.entryPath, op.isEncrypted); err != nil {
+		return err
+	}
+	op.wasEncrypted = item.IsEncrypted
+	return nil
+}
+
+func (op *setEncryptedOperation) Undo() error {
+	if op.wasEncrypted == op.isEncrypted {
+		return nil
+	}
+	_, err := op.store.SetEncrypted(op.entryPath, op.wasEncrypted)
+	return err
+}
+
+func (op setEncryptedOperation) GetDescription() string {
+	verb := "decrypt"
+	if op.isEncrypted {
+		verb = "encrypt"
+	}
+	return fmt.Sprintf("%s '%s'", verb, op.entryPath)
+}
+
+type batchOperation struct {
+	description string
+
+	operations []Operation
+}
+
+// NewBatchOperation groups operations so they're done & undone all at once (e.g. retagging every selected entry)
+// If one of the operations fails, the ones before it are undone so the batch is all-or-nothing
+func NewBatchOperation(description string, operations []Operation) Operation {
+	return &batchOperation{
+		description: description,
+		operations:  operations,
+	}
+}
+
+func (op *batchOperation) Do() error {
+	for idx, operation := range op.operations {
+		if err := operation.Do(); err != nil {
+			op.rollBack(op.operations[:idx], true)
+			return fmt.Errorf("couldn't %s: %w", operation.GetDescription(), err)
+		}
+	}
+	return nil
+}
+
+func (op *batchOperation) Undo() error {
+	for idx := len(op.operations) - 1; idx >= 0; idx-- {
+		operation := op.operations[idx]
+		if err := operation.Undo(); err != nil {
+			op.rollBack(op.operations[idx+1:], false)
+			return fmt.Errorf("couldn't undo '%s': %w", operation.GetDescription(), err)
+		}
+	}
+	return nil
+}
+
+func (op batchOperation) GetDescription() string {
+	return op.description
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// rollBack puts back operations that were (un)done before one in the batch failed, on a best-effort basis
+func (op *batchOperation) rollBack(completedOperations []Operation, wereDone bool) {
+	if wereDone {
+		for idx := len(completedOperations) - 1; idx >= 0; idx-- {
+			_ = completedOperations[idx].Undo()
+		}
+		return
+	}
+	for _, operation := range completedOperations {
+		_ = operation.Do()
+	}
+}
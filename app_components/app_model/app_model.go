@@ -1,26 +1,46 @@
 package app_model
 
 import (
+	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/app_components/command_line"
 	"github.com/mieubrisse/cli-journal-go/app_components/entry_item"
 	"github.com/mieubrisse/cli-journal-go/app_components/entry_list"
 	"github.com/mieubrisse/cli-journal-go/app_components/filter_pane"
+	"github.com/mieubrisse/cli-journal-go/app_components/history_viewer"
 	"github.com/mieubrisse/cli-journal-go/app_components/new_entry_form"
+	"github.com/mieubrisse/cli-journal-go/app_components/option_modal"
+	"github.com/mieubrisse/cli-journal-go/app_components/task_viewer"
+	"github.com/mieubrisse/cli-journal-go/components"
 	"github.com/mieubrisse/cli-journal-go/components/filterable_list"
 	"github.com/mieubrisse/cli-journal-go/components/filterable_list_item"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/git_journal"
 	"github.com/mieubrisse/cli-journal-go/global_styles"
 	"github.com/mieubrisse/cli-journal-go/helpers"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
 	"github.com/mieubrisse/vim-bubble/vim"
 	"github.com/sahilm/fuzzy"
 	"sort"
+	"strings"
+	"time"
 )
 
 const (
 	maxCreateContentModalWidth  = 50
 	maxCreateContentModalHeight = 3
 
-	filterPaneHeight = 6
+	commandLineHeight = 1
+
+	// How many changes to entries can be undone
+	maxUndoHistoryLength = 100
+
+	invalidNameErrorMsg = "names can only contain letters, numbers, '.', and '-'"
+
+	// Bound to the pick command in pick mode
+	pickKey = "enter"
 )
 
 // "Constants"
@@ -39,63 +59,189 @@ var filtersLabelLine = lipgloss.NewStyle().
 	Render("FILTERS")
 
 type Model struct {
+	store journal_store.Store
+
+	// Changes made to entries, so they can be undone
+	actionJournal *action_journal.ActionJournal
+
 	createContentForm new_entry_form.Component
 
+	renameEntryForm new_entry_form.Component
+
+	moveEntryForm new_entry_form.Component
+
+	// The entry that the rename or move modal is acting on
+	modalEntryPath string
+
 	filterPane filter_pane.Model
 
 	filterTabCompletionPane filterable_list.Component[filterable_list_item.Component]
 
 	contentList entry_list.Model
 
+	commandLine command_line.Component
+
+	// Maps keys pressed while the content list is focused to the command line text they run
+	keyBindings map[string]string
+
 	tags []string
 
+	filterPaneHeight int
+
+	// The journals that can be switched between, and where the user was in the ones that aren't in use
+	workspaces          []Workspace
+	currentWorkspaceIdx int
+	workspaceStates     map[string]workspaceState
+	workspaceSwitcher   option_modal.Component
+
+	historyViewer history_viewer.Component
+
+	// The entry whose history is being viewed
+	historyEntryPath string
+
+	// Lists entries to jump to (an entry's links or backlinks), whose paths are kept in the same order as the options;
+	// broken links have empty paths
+	linksModal           option_modal.Component
+	linksModalEntryPaths []string
+
+	// Asks whether to rewrite the links to an entry that was just renamed
+	rewriteLinksModal  option_modal.Component
+	pendingLinkRewrite linkRewrite
+
+	// Lists the checkboxes from the shown entries, to check them off
+	taskViewer task_viewer.Component
+
+	// Commits the changes made to the current journal; nil if the journal isn't kept in git
+	gitRepo *git_journal.Repo
+
+	// Shown in the list's footer; empty if the journal isn't kept in git (or its status hasn't been checked yet)
+	gitStatusIndicator string
+
+	// Set when something (e.g. a commit) changes the git status, so it gets re-checked once the message is handled
+	isGitStatusStale bool
+
+	// When the check behind the indicator was started, so that slower, older checks don't overwrite newer ones
+	gitStatusCheckTime time.Time
+
+	// For choosing the journal's passphrase (typed twice, the first time being kept until it's confirmed) or unlocking
+	// the journal with it
+	newPassphraseForm     new_entry_form.Component
+	confirmPassphraseForm new_entry_form.Component
+	unlockForm            new_entry_form.Component
+	pendingPassphrase     string
+
+	// In pick mode, the user is choosing entries for another program rather than browsing the journal
+	isPickMode bool
+
+	// Set when the user picks entries, so they can be read once the program exits
+	pickedEntryPaths []string
+
 	height int
 	width  int
 }
 
 func New(
-	content []entry_item.Component,
+	store journal_store.Store,
+	content []content_item.ContentItem,
+	initialFilterText string,
+	sortMode entry_list.SortMode,
+	groupingMode entry_list.GroupingMode,
+	columns []entry_item.ColumnSpec,
+	filterPaneHeight int,
 ) Model {
-	createContentForm := new_entry_form.New()
-
-	contentList := entry_list.New(content)
+	createContentForm := new_entry_form.New("Create Content", "Name: ", new_entry_form.IsValidEntryName)
+	renameEntryForm := new_entry_form.New("Rename Entry", "Name: ", new_entry_form.IsValidEntryName)
+	moveEntryForm := new_entry_form.New("Move Entry", "Tag: ", func(tag string) bool {
+		return journal_store.ValidateTag(tag) == nil
+	})
+
+	contentItems := createEntryItems(content)
+	contentList := entry_list.New(contentItems, sortMode, groupingMode, columns)
 	contentList.Focus()
 
-	filterPane := filter_pane.New()
+	filterPane := filter_pane.New(initialFilterText)
 
-	deduplicatedTags := make(map[string]bool, 0)
-	for _, contentItem := range content {
-		for _, tag := range contentItem.GetTags() {
-			deduplicatedTags[tag] = true
-		}
-	}
+	completionPane := filterable_list.New[filterable_list_item.Component]()
 
-	sortedTags := make([]string, 0, len(deduplicatedTags))
-	for tag := range deduplicatedTags {
-		sortedTags = append(sortedTags, tag)
+	keyBindings := make(map[string]string, len(defaultKeyBindings))
+	for key, commandText := range defaultKeyBindings {
+		keyBindings[key] = commandText
 	}
-	sort.Strings(sortedTags)
 
-	completionPane := filterable_list.New[filterable_list_item.Component]()
-
-	return Model{
+	model := Model{
+		store:                   store,
+		actionJournal:           action_journal.New(maxUndoHistoryLength),
 		createContentForm:       createContentForm,
+		renameEntryForm:         renameEntryForm,
+		moveEntryForm:           moveEntryForm,
+		modalEntryPath:          "",
 		filterPane:              filterPane,
 		filterTabCompletionPane: completionPane,
 		contentList:             contentList,
+		commandLine:             command_line.New(),
+		keyBindings:             keyBindings,
 		height:                  0,
 		width:                   0,
-		tags:                    sortedTags,
+		tags:                    getSortedTags(contentItems),
+		filterPaneHeight:        filterPaneHeight,
+		workspaces:              []Workspace{{Name: "", Store: store, GitRepo: nil, InitialFilterText: initialFilterText}},
+		currentWorkspaceIdx:     0,
+		workspaceStates:         map[string]workspaceState{},
+		workspaceSwitcher:       option_modal.New("Switch Journal"),
+		historyViewer:           history_viewer.New(),
+		historyEntryPath:        "",
+		linksModal:              option_modal.New(""),
+		linksModalEntryPaths:    []string{},
+		rewriteLinksModal:       option_modal.New(""),
+		pendingLinkRewrite:      linkRewrite{},
+		taskViewer:              task_viewer.New(),
+		gitRepo:                 nil,
+		gitStatusIndicator:      "",
+		isGitStatusStale:        false,
+		gitStatusCheckTime:      time.Time{},
+		newPassphraseForm:       new_entry_form.NewMasked("Set Journal Passphrase", "Passphrase: ", isValidPassphrase),
+		confirmPassphraseForm:   new_entry_form.NewMasked("Confirm Journal Passphrase", "Passphrase: ", isValidPassphrase),
+		unlockForm:              new_entry_form.NewMasked("Unlock Journal", "Passphrase: ", isValidPassphrase),
+		pendingPassphrase:       "",
+		isPickMode:              false,
+		pickedEntryPaths:        nil,
 	}
+
+	// Start out with the list showing only what the initial filters let through
+	model.propagateFilterChanges()
+	return model
+}
+
+// EnablePickMode makes the enter key exit the program, picking the selected entries (or the highlighted one)
+func (model *Model) EnablePickMode() {
+	model.isPickMode = true
+	model.keyBindings[pickKey] = "pick"
+}
+
+// GetPickedEntryPaths gets the entries the user picked in pick mode, returning false if they exited without picking
+func (model Model) GetPickedEntryPaths() ([]string, bool) {
+	return model.pickedEntryPaths, model.pickedEntryPaths != nil
 }
 
 func (model Model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(model.checkGitStatus(), model.startGitStatusPolling())
 }
 
 // NOTE: This returns a model because BubbleTea expects models to be passed by-value, so the way to "update" the model
 // is to return a new instance of it
 func (model Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updatedModel, cmd := model.handleMsg(msg)
+
+	// Changes get committed in many places, so the git status is re-checked once, after the message is handled
+	if updatedModel.isGitStatusStale {
+		updatedModel.isGitStatusStale = false
+		cmd = tea.Batch(cmd, updatedModel.checkGitStatus())
+	}
+	return updatedModel, cmd
+}
+
+// handleMsg does the work of Update
+func (model Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -104,42 +250,41 @@ func (model Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if model.contentList.Focused() {
-			switch msg.String() {
-			case "\\":
-				cmds := make([]tea.Cmd, 0)
-				cmds = append(cmds, model.contentList.Blur())
-
-				cmds = append(cmds, model.filterPane.Focus())
-				cmds = append(cmds, model.filterTabCompletionPane.Focus())
-				model.filterPane.SetMode(vim.InsertMode)
-
-				return model, tea.Batch(cmds...)
-			case "c":
-				// Clear all filters
-				model.filterPane.Clear()
-				nameFilterLines, tagFilterLines := model.filterPane.GetFilterLines()
-				model.contentList.SetFilters(nameFilterLines, tagFilterLines)
-				model.filterTabCompletionPane.SetItems([]filterable_list_item.Component{})
+			// Every action on the list goes through a named command, so that the keys can be remapped
+			commandText, found := model.keyBindings[msg.String()]
+			if !found {
+				return model, nil
+			}
+			cmd := model.runCommand(commandText)
+			return model, cmd
 
+		} else if model.commandLine.Focused() {
+			switch msg.String() {
+			case "esc":
+				cmd := model.focusContentList()
+				return model, cmd
+			case "enter":
+				commandText := model.commandLine.GetValue()
+				model.commandLine.AddToHistory(commandText)
+
+				// Focus goes back to the list first, because the command might move it elsewhere
+				focusCmd := model.focusContentList()
+				commandCmd := model.runCommand(commandText)
+				return model, tea.Batch(focusCmd, commandCmd)
+			case "tab":
+				model.commandLine.CycleCompletion(model.getCommandCompletions, 1)
+				return model, nil
+			case "shift+tab":
+				model.commandLine.CycleCompletion(model.getCommandCompletions, -1)
 				return model, nil
-			case "n":
-				cmds := make([]tea.Cmd, 0)
-				cmds = append(cmds, model.contentList.Blur())
-				cmds = append(cmds, model.createContentForm.Focus())
-				return model, tea.Batch(cmds...)
 			}
 
-			cmd := model.contentList.Update(msg)
+			cmd := model.commandLine.Update(msg)
 			return model, cmd
-
 		} else if model.filterPane.Focused() {
 			switch msg.String() {
 			case "\\":
-				// TODO switch to by-value
-				model.filterPane.Blur()
-				model.filterTabCompletionPane.Blur()
-
-				cmd := model.contentList.Focus()
+				cmd := model.focusContentList()
 				return model, cmd
 			case "ctrl+j":
 				model.filterTabCompletionPane.Scroll(1)
@@ -152,77 +297,109 @@ func (model Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			var cmd tea.Cmd
 			if msg.String() == "tab" {
 				// The user is tab-completing
-				filteredItemIndices := model.filterTabCompletionPane.GetFilteredItemIndices()
-				if len(filteredItemIndices) > 0 {
-					highlightedCompletionIdxInFilteredList := model.filterTabCompletionPane.GetHighlightedItemIndex()
-					highlightedCompletionIdxInOriginalList := filteredItemIndices[highlightedCompletionIdxInFilteredList]
-					selectedCompletion := model.filterTabCompletionPane.GetItems()[highlightedCompletionIdxInOriginalList]
-					model.filterPane.ReplaceCurrentFilter(selectedCompletion.GetValue(), true)
-				}
+				model.applyHighlightedCompletion()
 			} else {
 				cmd = model.filterPane.Update(msg)
 			}
 
-			// Make sure to let the content list know about the changes
-			nameFilterList, tagFilterList := model.filterPane.GetFilterLines()
-			model.contentList.SetFilters(nameFilterList, tagFilterList)
-
-			// Update the tab-contents pane with changes, displaying nothing if the line isn't a tag filter line
-			filterText, isTagFilter := model.filterPane.GetCurrentFilter()
-			tabCompletionItems := make([]filterable_list_item.Component, 0)
-			if isTagFilter {
-				if len(filterText) > 0 {
-					matches := fuzzy.Find(filterText, model.tags)
+			model.propagateFilterChanges()
+			return model, cmd
+		} else if modalForm, found := model.getFocusedModalForm(); found {
+			switch msg.String() {
+			case "esc":
+				cmd := model.closeModalForm(modalForm)
+				return model, cmd
+			case "enter":
+				cmd := model.submitModalForm(modalForm)
+				return model, cmd
+			}
 
-					tabCompletionItems = make([]filterable_list_item.Component, len(matches))
-					for idx, match := range matches {
-						tabCompletionItems[idx] = filterable_list_item.New(model.tags[match.Index])
-					}
-				} else {
-					tabCompletionItems = make([]filterable_list_item.Component, len(model.tags))
-					for idx, tag := range model.tags {
-						tabCompletionItems[idx] = filterable_list_item.New(tag)
+			cmd := modalForm.Update(msg)
+			return model, cmd
+		} else if model.workspaceSwitcher.Focused() {
+			switch msg.String() {
+			case "esc":
+				cmd := model.closeWorkspaceSwitcher()
+				return model, cmd
+			case "enter":
+				cmd := model.closeWorkspaceSwitcher()
+				if workspaceIdx, found := model.workspaceSwitcher.GetHighlightedOptionIndex(); found {
+					if err := model.switchWorkspace(workspaceIdx); err != nil {
+						model.commandLine.SetStatus(err.Error(), true)
 					}
 				}
+				return model, cmd
 			}
-			model.filterTabCompletionPane.SetItems(tabCompletionItems)
 
+			cmd := model.workspaceSwitcher.Update(msg)
 			return model, cmd
-		} else if model.createContentForm.Focused() {
+		} else if model.historyViewer.Focused() {
 			switch msg.String() {
 			case "esc":
-				// Back out of the create content modal
-				model.createContentForm.Clear()
+				cmd := model.closeHistoryViewer()
+				return model, cmd
+			case "r":
+				cmd, err := model.restoreHighlightedVersion()
+				if err != nil {
+					model.commandLine.SetStatus(err.Error(), true)
+				}
+				return model, cmd
+			}
 
-				cmds := make([]tea.Cmd, 0)
-				cmds = append(cmds, model.createContentForm.Blur())
-				cmds = append(cmds, model.contentList.Focus())
-				return model, tea.Batch(cmds...)
+			cmd := model.historyViewer.Update(msg)
+			return model, cmd
+		} else if model.taskViewer.Focused() {
+			switch msg.String() {
+			case "esc":
+				cmd := model.closeTaskViewer()
+				return model, cmd
+			case "x":
+				if err := model.toggleHighlightedTask(); err != nil {
+					model.commandLine.SetStatus(err.Error(), true)
+				}
+				return model, nil
 			case "enter":
-				// TODO reenable
-				/*
-					// Create the new content piece
-					content := content_item.ContentItem{
-						Timestamp: time.Now(),
-						Name:      model.createContentForm.GetNameValue(),
-						Tags:      []string{},
-					}
-					model.contentList.AddItem(content)
-				*/
+				cmd, err := model.goToHighlightedTask()
+				if err != nil {
+					model.commandLine.SetStatus(err.Error(), true)
+				}
+				return model, cmd
+			}
 
-				model.createContentForm.Clear()
+			cmd := model.taskViewer.Update(msg)
+			return model, cmd
+		} else if model.linksModal.Focused() {
+			switch msg.String() {
+			case "esc", "enter":
+				cmd, err := model.closeLinksModal(msg.String() == "enter")
+				if err != nil {
+					model.commandLine.SetStatus(err.Error(), true)
+				}
+				return model, cmd
+			}
 
-				cmds := make([]tea.Cmd, 0)
-				cmds = append(cmds, model.createContentForm.Blur())
-				cmds = append(cmds, model.contentList.Focus())
-				return model, tea.Batch(cmds...)
+			cmd := model.linksModal.Update(msg)
+			return model, cmd
+		} else if model.rewriteLinksModal.Focused() {
+			switch msg.String() {
+			case "esc", "enter":
+				cmd := model.closeRewriteLinksModal(msg.String() == "enter")
+				return model, cmd
 			}
 
-			cmd := model.createContentForm.Update(msg)
+			cmd := model.rewriteLinksModal.Update(msg)
 			return model, cmd
 		}
+	case tea.MouseMsg:
+		cmd := model.handleMouse(msg)
+		return model, cmd
 	case tea.WindowSizeMsg:
 		return model.Resize(msg.Width, msg.Height), nil
+	case gitStatusTickMsg:
+		return model, tea.Batch(model.checkGitStatus(), tickGitStatus())
+	case gitStatusMsg:
+		model.applyGitStatus(msg)
+		return model, nil
 	}
 
 	return model, nil
@@ -239,27 +416,48 @@ func (model Model) View() string {
 		model.contentList.View(),
 		filtersLabelLine,
 		filterView,
+		model.commandLine.View(),
 	}
 
 	contents := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
+	// We pad ourselves rather than using lipgloss, because lipgloss would rewrap lines containing emoji sequences
 	horizontalPad, verticalPad := getPadsForSize(model.width, model.height)
-	result := lipgloss.NewStyle().
-		Width(model.width).
-		Height(model.height).
-		MaxWidth(model.width).
-		MaxHeight(model.height).
-		Padding(verticalPad, horizontalPad, verticalPad, horizontalPad).
-		Render(contents)
-
-	if model.createContentForm.Focused() {
-		createContentFormStr := model.createContentForm.View()
+	displaySpaceWidth := helpers.GetMaxInt(0, model.width-2*horizontalPad)
+	displaySpaceHeight := helpers.GetMaxInt(0, model.height-2*verticalPad)
+	contents = helpers.FitToSize(contents, displaySpaceWidth, displaySpaceHeight)
 
-		createContentFormStr = lipgloss.NewStyle().
-			BorderStyle(lipgloss.NormalBorder()).
-			Render(createContentFormStr)
+	resultLines := make([]string, 0, model.height)
+	blankLine := strings.Repeat(" ", model.width)
+	for i := 0; i < verticalPad; i++ {
+		resultLines = append(resultLines, blankLine)
+	}
+	horizontalPadStr := strings.Repeat(" ", horizontalPad)
+	for _, line := range strings.Split(contents, "\n") {
+		resultLines = append(resultLines, horizontalPadStr+line+horizontalPadStr)
+	}
+	for i := 0; i < verticalPad; i++ {
+		resultLines = append(resultLines, blankLine)
+	}
+	result := strings.Join(resultLines, "\n")
 
-		result = helpers.OverlayString(result, createContentFormStr)
+	if modalForm, found := model.getFocusedModalForm(); found {
+		result = helpers.OverlayString(result, renderModal(modalForm))
+	}
+	if model.workspaceSwitcher.Focused() {
+		result = helpers.OverlayString(result, renderModal(model.workspaceSwitcher))
+	}
+	if model.historyViewer.Focused() {
+		result = helpers.OverlayString(result, renderModal(model.historyViewer))
+	}
+	if model.taskViewer.Focused() {
+		result = helpers.OverlayString(result, renderModal(model.taskViewer))
+	}
+	if model.linksModal.Focused() {
+		result = helpers.OverlayString(result, renderModal(model.linksModal))
+	}
+	if model.rewriteLinksModal.Focused() {
+		result = helpers.OverlayString(result, renderModal(model.rewriteLinksModal))
 	}
 
 	return result
@@ -274,24 +472,519 @@ func (model Model) Resize(width int, height int) Model {
 	displaySpaceHeight := helpers.GetMaxInt(0, model.height-2*verticalPad)
 
 	filterPaneWidth := int(0.5 * float64(displaySpaceWidth))
-	model.filterPane.Resize(filterPaneWidth, filterPaneHeight)
+	model.filterPane.Resize(filterPaneWidth, model.filterPaneHeight)
 
 	completionPaneWidth := displaySpaceWidth - filterPaneWidth
-	model.filterTabCompletionPane.Resize(completionPaneWidth, filterPaneHeight)
+	model.filterTabCompletionPane.Resize(completionPaneWidth, model.filterPaneHeight)
 
-	// Leave one blank line for filters label
-	contentListHeight := helpers.GetMaxInt(0, displaySpaceHeight-filterPaneHeight-1)
+	contentListHeight := model.getContentListHeight(displaySpaceHeight)
 
 	model.contentList.Resize(displaySpaceWidth, contentListHeight)
 
+	model.commandLine.Resize(displaySpaceWidth, commandLineHeight)
+
 	createContentModalWidth := helpers.GetMinInt(model.width, maxCreateContentModalWidth)
 	createContentModalHeight := helpers.GetMinInt(model.height, maxCreateContentModalHeight)
 	model.createContentForm.Resize(createContentModalWidth, createContentModalHeight)
+	model.renameEntryForm.Resize(createContentModalWidth, createContentModalHeight)
+	model.moveEntryForm.Resize(createContentModalWidth, createContentModalHeight)
+	model.newPassphraseForm.Resize(createContentModalWidth, createContentModalHeight)
+	model.confirmPassphraseForm.Resize(createContentModalWidth, createContentModalHeight)
+	model.unlockForm.Resize(createContentModalWidth, createContentModalHeight)
+	model.resizeWorkspaceSwitcher()
+	model.resizeHistoryViewer()
+	model.resizeLinkModals()
+	model.resizeTaskViewer()
 
 	return model
 }
 
 // =================================== Private Helper Functions ===================================
+func (model *Model) focusFilterPane() tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.contentList.Blur())
+	model.blurCommandLine()
+
+	cmds = append(cmds, model.filterPane.Focus())
+	cmds = append(cmds, model.filterTabCompletionPane.Focus())
+	model.filterPane.SetMode(vim.InsertMode)
+
+	return tea.Batch(cmds...)
+}
+
+func (model *Model) focusCommandLine() tea.Cmd {
+	model.commandLine.ClearStatus()
+
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.contentList.Blur())
+	cmds = append(cmds, model.commandLine.Focus())
+	return tea.Batch(cmds...)
+}
+
+func (model *Model) focusContentList() tea.Cmd {
+	// TODO switch to by-value
+	model.filterPane.Blur()
+	model.filterTabCompletionPane.Blur()
+	model.blurCommandLine()
+
+	return model.contentList.Focus()
+}
+
+// blurCommandLine abandons whatever command the user was typing
+func (model *Model) blurCommandLine() {
+	if !model.commandLine.Focused() {
+		return
+	}
+	model.commandLine.Clear()
+	model.commandLine.Blur()
+}
+
+func (model Model) getModalForms() []new_entry_form.Component {
+	return []new_entry_form.Component{
+		model.createContentForm,
+		model.renameEntryForm,
+		model.moveEntryForm,
+		model.newPassphraseForm,
+		model.confirmPassphraseForm,
+		model.unlockForm,
+	}
+}
+
+func (model Model) getFocusedModalForm() (new_entry_form.Component, bool) {
+	for _, form := range model.getModalForms() {
+		if form.Focused() {
+			return form, true
+		}
+	}
+	return nil, false
+}
+
+// openModalForm pops up the modal form, pre-filled with the given value
+func (model *Model) openModalForm(form new_entry_form.Component, initialValue string) tea.Cmd {
+	form.SetNameValue(initialValue)
+
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.contentList.Blur())
+	cmds = append(cmds, form.Focus())
+	return tea.Batch(cmds...)
+}
+
+// closeModalForm backs out of the modal form
+func (model *Model) closeModalForm(form new_entry_form.Component) tea.Cmd {
+	form.Clear()
+	if form != model.newPassphraseForm {
+		// The passphrase is only kept until it's been confirmed (or the confirmation is backed out of)
+		model.pendingPassphrase = ""
+	}
+
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, form.Blur())
+	cmds = append(cmds, model.contentList.Focus())
+	return tea.Batch(cmds...)
+}
+
+// submitModalForm acts on what was entered in the modal form, closing it if that worked and otherwise leaving it open
+// (with the problem in the status line) so the user can fix their input
+func (model *Model) submitModalForm(form new_entry_form.Component) tea.Cmd {
+	value := form.GetNameValue()
+
+	var err error
+	switch form {
+	case model.createContentForm:
+		if !form.IsNameValid() {
+			err = fmt.Errorf(invalidNameErrorMsg)
+			break
+		}
+
+		// New entries start out untagged
+		timestamp := time.Now()
+		body, templateErr := model.store.RenderTemplate(timestamp, value, "")
+		if templateErr != nil {
+			err = templateErr
+			break
+		}
+		operation := action_journal.NewCreateOperation(model.store, timestamp, value, "", body)
+		cmd := model.closeModalForm(form)
+		model.doOperation(operation)
+		return cmd
+	case model.renameEntryForm:
+		err = model.renameEntry(model.modalEntryPath, value)
+	case model.moveEntryForm:
+		err = model.moveEntry(model.modalEntryPath, value)
+	case model.newPassphraseForm:
+		if !form.IsNameValid() {
+			err = fmt.Errorf("the passphrase can't be empty")
+			break
+		}
+		// Nothing is set until the passphrase has been typed again, to catch typos
+		cmd := model.closeModalForm(form)
+		model.pendingPassphrase = value
+		return tea.Batch(cmd, model.openModalForm(model.confirmPassphraseForm, ""))
+	case model.confirmPassphraseForm:
+		if value != model.pendingPassphrase {
+			cmd := model.closeModalForm(form)
+			model.commandLine.SetStatus("the passphrases didn't match; choose one again", true)
+			return tea.Batch(cmd, model.openModalForm(model.newPassphraseForm, ""))
+		}
+		err = model.setPassphrase(value)
+	case model.unlockForm:
+		err = model.unlock(value)
+	}
+
+	if err != nil {
+		model.commandLine.SetStatus(err.Error(), true)
+		return nil
+	}
+	cmd := model.closeModalForm(form)
+	return tea.Batch(cmd, model.offerLinkRewrite())
+}
+
+// applyHighlightedCompletion replaces the filter under the cursor with the highlighted tab-completion
+func (model *Model) applyHighlightedCompletion() {
+	highlightedCompletionIdxInFilteredList, found := model.filterTabCompletionPane.GetHighlightedItemIndex()
+	if !found {
+		return
+	}
+	filteredItemIndices := model.filterTabCompletionPane.GetFilteredItemIndices()
+	highlightedCompletionIdxInOriginalList := filteredItemIndices[highlightedCompletionIdxInFilteredList]
+	selectedCompletion := model.filterTabCompletionPane.GetItems()[highlightedCompletionIdxInOriginalList]
+	model.filterPane.ReplaceCurrentFilter(selectedCompletion.GetValue(), true)
+}
+
+// propagateFilterChanges lets the content list and the tab-completion pane know that the filters changed
+func (model *Model) propagateFilterChanges() {
+	nameFilterList, tagFilterList := model.filterPane.GetFilterLines()
+	model.contentList.SetFilters(nameFilterList, tagFilterList)
+
+	// Update the tab-contents pane with changes, displaying nothing if the line isn't a tag filter line
+	filterText, isTagFilter := model.filterPane.GetCurrentFilter()
+	tabCompletionItems := make([]filterable_list_item.Component, 0)
+	if isTagFilter {
+		if len(filterText) > 0 {
+			matches := fuzzy.Find(filterText, model.tags)
+
+			tabCompletionItems = make([]filterable_list_item.Component, len(matches))
+			for idx, match := range matches {
+				tabCompletionItems[idx] = filterable_list_item.New(model.tags[match.Index])
+			}
+		} else {
+			tabCompletionItems = make([]filterable_list_item.Component, len(model.tags))
+			for idx, tag := range model.tags {
+				tabCompletionItems[idx] = filterable_list_item.New(tag)
+			}
+		}
+	}
+	model.filterTabCompletionPane.SetItems(tabCompletionItems)
+}
+
+// handleMouse routes the mouse event to whatever is under the pointer, translating the coordinates so they're relative
+// to that component's top-left corner
+func (model *Model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	// The modal sits on top of everything, so clicking outside of it backs out of it
+	if modalForm, found := model.getFocusedModalForm(); found {
+		if msg.Type == tea.MouseLeft && !model.isInModal(msg, modalForm) {
+			return model.closeModalForm(modalForm)
+		}
+		return nil
+	}
+	if model.workspaceSwitcher.Focused() {
+		if msg.Type == tea.MouseLeft && !model.isInModal(msg, model.workspaceSwitcher) {
+			return model.closeWorkspaceSwitcher()
+		}
+		return nil
+	}
+	if model.historyViewer.Focused() {
+		if msg.Type == tea.MouseLeft && !model.isInModal(msg, model.historyViewer) {
+			return model.closeHistoryViewer()
+		}
+		return nil
+	}
+	if model.taskViewer.Focused() {
+		if msg.Type == tea.MouseLeft && !model.isInModal(msg, model.taskViewer) {
+			return model.closeTaskViewer()
+		}
+		return nil
+	}
+	if model.linksModal.Focused() {
+		if msg.Type == tea.MouseLeft && !model.isInModal(msg, model.linksModal) {
+			cmd, _ := model.closeLinksModal(false)
+			return cmd
+		}
+		return nil
+	}
+	if model.rewriteLinksModal.Focused() {
+		if msg.Type == tea.MouseLeft && !model.isInModal(msg, model.rewriteLinksModal) {
+			return model.closeRewriteLinksModal(false)
+		}
+		return nil
+	}
+
+	horizontalPad, verticalPad := getPadsForSize(model.width, model.height)
+	displaySpaceHeight := helpers.GetMaxInt(0, model.height-2*verticalPad)
+	contentListHeight := model.getContentListHeight(displaySpaceHeight)
+
+	x := msg.X - horizontalPad
+	y := msg.Y - verticalPad
+
+	// The content list's footer and the filters label come between the list and the filter pane
+	filterPaneTop := contentListHeight + 2
+	if x < 0 || y < 0 {
+		return nil
+	}
+
+	var cmd tea.Cmd
+	switch {
+	case y < contentListHeight:
+		if msg.Type == tea.MouseLeft && !model.contentList.Focused() {
+			cmd = model.focusContentList()
+		}
+		msg.X, msg.Y = x, y
+		model.contentList.Update(msg)
+	case y >= filterPaneTop && y < filterPaneTop+model.filterPaneHeight:
+		if x < model.filterPane.GetWidth() {
+			if msg.Type == tea.MouseLeft && !model.filterPane.Focused() {
+				cmd = model.focusFilterPane()
+			}
+			return cmd
+		}
+
+		// The tab-completion pane
+		msg.X, msg.Y = x-model.filterPane.GetWidth(), y-filterPaneTop
+		if msg.Type != tea.MouseLeft {
+			model.filterTabCompletionPane.Update(msg)
+			return nil
+		}
+		if _, found := model.filterTabCompletionPane.GetFilteredItemIndexAtLine(msg.Y); !found {
+			return nil
+		}
+		model.filterTabCompletionPane.Update(msg)
+		model.applyHighlightedCompletion()
+		model.propagateFilterChanges()
+	}
+	return cmd
+}
+
+func renderModal(modal components.Component) string {
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Render(modal.View())
+}
+
+// isInModal gets whether the mouse event happened over the modal, which is overlaid in the middle of the screen
+func (model Model) isInModal(msg tea.MouseMsg, modal components.Component) bool {
+	modalWidth, modalHeight := lipgloss.Size(renderModal(modal))
+	modalTop, modalLeft := helpers.GetOverlayPosition(model.width, model.height, modalWidth, modalHeight)
+	return msg.X >= modalLeft && msg.X < modalLeft+modalWidth &&
+		msg.Y >= modalTop && msg.Y < modalTop+modalHeight
+}
+
+// doOperation makes a change to the entries that can be undone, and updates what's displayed to match
+func (model *Model) doOperation(operation action_journal.Operation) {
+	if err := model.actionJournal.Do(operation); err != nil {
+		model.commandLine.SetStatus(err.Error(), true)
+		model.reloadContent()
+		return
+	}
+	model.reloadContent()
+	if err := model.commitChanges(operation.GetDescription()); err != nil {
+		model.commandLine.SetStatus(err.Error(), true)
+		return
+	}
+	model.commandLine.ClearStatus()
+}
+
+// reloadContent re-reads the entries from the journal, keeping the highlight & selection where possible
+func (model *Model) reloadContent() {
+	content, err := model.store.List()
+	if err != nil {
+		model.commandLine.SetStatus(err.Error(), true)
+		return
+	}
+	contentItems := createEntryItems(content)
+	model.contentList.SetContent(contentItems)
+	model.tags = getSortedTags(contentItems)
+}
+
+// renameEntry gives the entry a new name, updating it in place in the list
+func (model *Model) renameEntry(entryPath string, newName string) error {
+	if !new_entry_form.IsValidEntryName(newName) {
+		return fmt.Errorf(invalidNameErrorMsg)
+	}
+	item, err := model.store.Get(entryPath)
+	if err != nil {
+		return err
+	}
+
+	// The links to the entry are found before it's renamed, as they won't point at it afterwards
+	model.findLinksToRename(entryPath)
+	newEntryPath, err := journal_store.GetMovedEntryPath(entryPath, getTag(item), newName)
+	if err == nil && newEntryPath == entryPath {
+		model.pendingLinkRewrite = linkRewrite{}
+	}
+	if err := model.moveEntryInPlace(entryPath, getTag(item), newName); err != nil {
+		model.pendingLinkRewrite = linkRewrite{}
+		return err
+	}
+	model.setRenamedEntry(newEntryPath)
+	return nil
+}
+
+// moveEntry gives the entry a new tag (i.e. moves it to a different directory), updating it in place in the list
+func (model *Model) moveEntry(entryPath string, newTag string) error {
+	newTag = strings.TrimPrefix(newTag, "#")
+	if err := journal_store.ValidateTag(newTag); err != nil {
+		return err
+	}
+	item, err := model.store.Get(entryPath)
+	if err != nil {
+		return err
+	}
+	return model.moveEntryInPlace(entryPath, newTag, item.Name)
+}
+
+func (model *Model) moveEntryInPlace(entryPath string, newTag string, newName string) error {
+	newEntryPath, err := journal_store.GetMovedEntryPath(entryPath, newTag, newName)
+	if err != nil {
+		return err
+	}
+	if newEntryPath == entryPath {
+		return nil
+	}
+	if model.store.Exists(newEntryPath) {
+		return fmt.Errorf("there's already an entry named '%s' tagged %s", newName, journal_store.DescribeTag(newTag))
+	}
+
+	operation, err := action_journal.NewMoveOperation(model.store, entryPath, newTag, newName)
+	if err != nil {
+		return err
+	}
+	if err := model.actionJournal.Do(operation); err != nil {
+		return err
+	}
+	// The entry has moved whether or not that could be committed, so the list is updated either way
+	commitErr := model.commitChanges(operation.GetDescription())
+
+	newItem, err := model.store.Get(newEntryPath)
+	if err != nil {
+		return err
+	}
+	if err := model.contentList.UpdateItem(entryPath, newItem); err != nil {
+		// The list is out of step with the journal, so fall back to re-reading everything
+		model.reloadContent()
+		return commitErr
+	}
+	model.tags = getSortedTags(model.contentList.GetChecklist().GetItems())
+	if commitErr != nil {
+		return commitErr
+	}
+	model.commandLine.ClearStatus()
+	return nil
+}
+
+// getHighlightedEntryPath gets the path of the highlighted entry, returning false if no entry is highlighted (none are
+// shown, or the highlight is on a folded group's header)
+func (model Model) getHighlightedEntryPath() (string, bool) {
+	filterableList := model.contentList.GetChecklist().GetFilterableList()
+	highlightedFilteredIdx, found := filterableList.GetHighlightedItemIndex()
+	if !found {
+		return "", false
+	}
+	return filterableList.GetItems()[filterableList.GetFilteredItemIndices()[highlightedFilteredIdx]].GetPath(), true
+}
+
+// highlightEntry moves the highlight to the shown entry at the given path, returning false if it isn't shown
+func (model *Model) highlightEntry(entryPath string) bool {
+	filterableList := model.contentList.GetChecklist().GetFilterableList()
+	items := filterableList.GetItems()
+	for filteredIdx, originalIdx := range filterableList.GetFilteredItemIndices() {
+		if items[originalIdx].GetPath() == entryPath {
+			filterableList.HighlightItem(filteredIdx)
+			return true
+		}
+	}
+	return false
+}
+
+// getTargetEntryPaths gets the entries that an action should apply to: the selected ones, or the highlighted one if
+// nothing is selected
+func (model Model) getTargetEntryPaths() []string {
+	result := model.getSelectedEntryPaths()
+	if len(result) > 0 {
+		return result
+	}
+
+	if highlightedPath, found := model.getHighlightedEntryPath(); found {
+		result = append(result, highlightedPath)
+	}
+	return result
+}
+
+// getSelectedEntryPaths gets the paths of the selected entries, whether or not they're shown
+func (model Model) getSelectedEntryPaths() []string {
+	checklist := model.contentList.GetChecklist()
+	items := checklist.GetItems()
+
+	selectedOriginalIndices := make([]int, 0)
+	for originalIdx := range checklist.GetSelectedItemOriginalIndices() {
+		selectedOriginalIndices = append(selectedOriginalIndices, originalIdx)
+	}
+	sort.Ints(selectedOriginalIndices)
+
+	result := make([]string, 0, len(selectedOriginalIndices))
+	for _, originalIdx := range selectedOriginalIndices {
+		result = append(result, items[originalIdx].GetPath())
+	}
+	return result
+}
+
+// getShownEntryPaths gets the paths of the entries that pass the filters, in the order they're shown
+func (model Model) getShownEntryPaths() []string {
+	filterableList := model.contentList.GetChecklist().GetFilterableList()
+	items := filterableList.GetItems()
+	result := make([]string, 0)
+	for _, originalIdx := range filterableList.GetFilteredItemIndices() {
+		result = append(result, items[originalIdx].GetPath())
+	}
+	return result
+}
+
+func createEntryItems(content []content_item.ContentItem) []entry_item.Component {
+	result := make([]entry_item.Component, len(content))
+	for idx, contentItem := range content {
+		result[idx] = entry_item.New(contentItem)
+	}
+	return result
+}
+
+func getSortedTags(items []entry_item.Component) []string {
+	deduplicatedTags := make(map[string]bool, 0)
+	for _, item := range items {
+		for _, tag := range item.GetTags() {
+			deduplicatedTags[tag] = true
+		}
+	}
+
+	sortedTags := make([]string, 0, len(deduplicatedTags))
+	for tag := range deduplicatedTags {
+		sortedTags = append(sortedTags, tag)
+	}
+	sort.Strings(sortedTags)
+	return sortedTags
+}
+
+// Entries have at most one tag, since their tag is the directory they're in
+func getTag(item content_item.ContentItem) string {
+	if len(item.Tags) == 0 {
+		return ""
+	}
+	return item.Tags[0]
+}
+
+// Leaves room below the list for the list's footer, the filters label, the filter pane, and the command line
+func (model Model) getContentListHeight(displaySpaceHeight int) int {
+	return helpers.GetMaxInt(0, displaySpaceHeight-model.filterPaneHeight-commandLineHeight-2)
+}
+
 func getPadsForSize(width int, height int) (int, int) {
 	actualHorizontalPad := 0
 	for threshold, trialHorizontalPad := range horizontalPadThresholdsByTerminalWidth {
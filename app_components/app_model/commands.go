@@ -0,0 +1,1039 @@
+package app_model
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/app_components/command_line"
+	"github.com/mieubrisse/cli-journal-go/app_components/entry_list"
+	"github.com/mieubrisse/cli-journal-go/exporter"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"sort"
+	"strings"
+)
+
+// Means the command takes any number of arguments
+const unlimitedArgs = -1
+
+// Makes the "tasks" command read every entry, rather than the shown ones
+const allTasksArg = "all"
+
+// A key always stays bound to this, since commands can't be run (and so keys can't be remapped) without it
+const commandLineCommandName = "command-line"
+
+var exportFormatNames = []string{
+	string(exporter.MarkdownFormat),
+	string(exporter.HTMLFormat),
+	string(exporter.TarFormat),
+	string(exporter.ZipFormat),
+}
+
+// A named action that can be run from the command line, or bound to a key
+type command struct {
+	name string
+
+	// E.g. "[MODE]"
+	argsUsage string
+
+	description string
+
+	minArgs int
+	maxArgs int
+
+	// Gets the candidates for tab-completing the argument at the given index; nil means the arguments aren't completed
+	completeArg func(model Model, argIdx int) []string
+
+	run func(model *Model, args []string) (tea.Cmd, error)
+}
+
+// The keys that run commands while the entry list is focused, mapped to the command line text they run
+// These can be changed at runtime with the "map" and "unmap" commands
+var defaultKeyBindings = map[string]string{
+	"j":      "down",
+	"k":      "up",
+	"J":      "page-down",
+	"K":      "page-up",
+	"z":      "toggle-fold",
+	"Z":      "unfold-all",
+	"V":      "visual-mode",
+	"esc":    "exit-visual-mode",
+	"x":      "toggle-selection",
+	"s":      "select-shown",
+	"d":      "deselect-shown",
+	"S":      "select-all",
+	"D":      "deselect-all",
+	"a":      "select-group",
+	"A":      "deselect-group",
+	"o":      "sort",
+	"O":      "sort-previous",
+	"g":      "group",
+	"G":      "group-previous",
+	"w":      "toggle-wrap",
+	"p":      "toggle-previews",
+	"\\":     "focus-filters",
+	"c":      "clear-filters",
+	"u":      "undo",
+	"ctrl+r": "redo",
+	"ctrl+o": "switch-journal",
+	"n":      "new",
+	"r":      "rename",
+	"m":      "move",
+	"H":      "history",
+	"f":      "follow-link",
+	"b":      "backlinks",
+	"T":      "tasks",
+	"U":      "unlock",
+	"L":      "lock",
+	":":      commandLineCommandName,
+}
+
+// getAllCommands gets every command that can be run
+// This is a function rather than a variable because the commands refer back to the list of commands (e.g. for
+// completion)
+func getAllCommands() []command {
+	return []command{
+		{
+			name:        "down",
+			argsUsage:   "",
+			description: "Move the highlight down",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().GetFilterableList().Scroll(1)
+				return nil, nil
+			},
+		},
+		{
+			name:        "up",
+			argsUsage:   "",
+			description: "Move the highlight up",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().GetFilterableList().Scroll(-1)
+				return nil, nil
+			},
+		},
+		{
+			name:        "page-down",
+			argsUsage:   "",
+			description: "Move the highlight down a screen",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().GetFilterableList().Scroll(model.contentList.GetHeight())
+				return nil, nil
+			},
+		},
+		{
+			name:        "page-up",
+			argsUsage:   "",
+			description: "Move the highlight up a screen",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().GetFilterableList().Scroll(-model.contentList.GetHeight())
+				return nil, nil
+			},
+		},
+		{
+			name:        "jump",
+			argsUsage:   "NAME",
+			description: "Highlight the shown entry with the given name",
+			minArgs:     1,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				return model.getShownEntryNames()
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				list := model.contentList.GetChecklist().GetFilterableList()
+				items := list.GetItems()
+				for filteredIdx, originalIdx := range list.GetFilteredItemIndices() {
+					if items[originalIdx].GetName() == args[0] {
+						list.HighlightItem(filteredIdx)
+						return nil, nil
+					}
+				}
+				return nil, fmt.Errorf("no shown entry is named '%s'", args[0])
+			},
+		},
+		{
+			name:        "toggle-fold",
+			argsUsage:   "",
+			description: "Fold or unfold the highlighted group",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().GetFilterableList().ToggleHighlightedGroupFold()
+				return nil, nil
+			},
+		},
+		{
+			name:        "unfold-all",
+			argsUsage:   "",
+			description: "Unfold every group",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().GetFilterableList().UnfoldAllGroups()
+				return nil, nil
+			},
+		},
+		{
+			name:        "toggle-selection",
+			argsUsage:   "",
+			description: "Select or deselect the highlighted entry, or the visual range",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				checklist := model.contentList.GetChecklist()
+				if checklist.IsInVisualMode() {
+					checklist.ToggleVisualRangeSelection()
+					return nil, nil
+				}
+				checklist.ToggleHighlightedItemSelection()
+				return nil, nil
+			},
+		},
+		{
+			name:        "select-shown",
+			argsUsage:   "",
+			description: "Select every entry matching the filters, or every entry in the visual range",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				checklist := model.contentList.GetChecklist()
+				if checklist.IsInVisualMode() {
+					checklist.SetVisualRangeSelection(true)
+					return nil, nil
+				}
+				checklist.SetAllViewableItemsSelection(true)
+				return nil, nil
+			},
+		},
+		{
+			name:        "deselect-shown",
+			argsUsage:   "",
+			description: "Deselect every entry matching the filters, or every entry in the visual range",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				checklist := model.contentList.GetChecklist()
+				if checklist.IsInVisualMode() {
+					checklist.SetVisualRangeSelection(false)
+					return nil, nil
+				}
+				checklist.SetAllViewableItemsSelection(false)
+				return nil, nil
+			},
+		},
+		{
+			name:        "visual-mode",
+			argsUsage:   "",
+			description: "Start (or stop) selecting the range of entries between here and wherever the highlight moves to",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				checklist := model.contentList.GetChecklist()
+				if checklist.IsInVisualMode() {
+					checklist.StopVisualMode()
+					return nil, nil
+				}
+				checklist.StartVisualMode()
+				return nil, nil
+			},
+		},
+		{
+			name:        "exit-visual-mode",
+			argsUsage:   "",
+			description: "Stop selecting a range of entries without changing the selection",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().StopVisualMode()
+				return nil, nil
+			},
+		},
+		{
+			name:        "select-all",
+			argsUsage:   "",
+			description: "Select every entry, including ones not matching the filters",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().SetAllItemsSelection(true)
+				return nil, nil
+			},
+		},
+		{
+			name:        "deselect-all",
+			argsUsage:   "",
+			description: "Deselect every entry, including ones not matching the filters",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().SetAllItemsSelection(false)
+				return nil, nil
+			},
+		},
+		{
+			name:        "select-group",
+			argsUsage:   "",
+			description: "Select every shown entry in the highlighted group",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().SetHighlightedGroupSelection(true)
+				return nil, nil
+			},
+		},
+		{
+			name:        "deselect-group",
+			argsUsage:   "",
+			description: "Deselect every shown entry in the highlighted group",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.GetChecklist().SetHighlightedGroupSelection(false)
+				return nil, nil
+			},
+		},
+		{
+			name:        "sort",
+			argsUsage:   "[MODE]",
+			description: "Sort by the given mode, or by the next one if no mode is given",
+			minArgs:     0,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				result := make([]string, 0)
+				for _, mode := range entry_list.GetAllSortModes() {
+					result = append(result, mode.String())
+				}
+				return result
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				if len(args) == 0 {
+					model.contentList.SetSortMode(model.contentList.GetSortMode().Next(1))
+					return nil, nil
+				}
+				sortMode, err := entry_list.ParseSortMode(args[0])
+				if err != nil {
+					return nil, err
+				}
+				model.contentList.SetSortMode(sortMode)
+				return nil, nil
+			},
+		},
+		{
+			name:        "sort-previous",
+			argsUsage:   "",
+			description: "Sort by the previous mode",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.SetSortMode(model.contentList.GetSortMode().Next(-1))
+				return nil, nil
+			},
+		},
+		{
+			name:        "group",
+			argsUsage:   "[MODE]",
+			description: "Group by the given mode, or by the next one if no mode is given",
+			minArgs:     0,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				result := make([]string, 0)
+				for _, mode := range entry_list.GetAllGroupingModes() {
+					result = append(result, mode.String())
+				}
+				return result
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				if len(args) == 0 {
+					model.contentList.SetGroupingMode(model.contentList.GetGroupingMode().Next(1))
+					return nil, nil
+				}
+				groupingMode, err := entry_list.ParseGroupingMode(args[0])
+				if err != nil {
+					return nil, err
+				}
+				model.contentList.SetGroupingMode(groupingMode)
+				return nil, nil
+			},
+		},
+		{
+			name:        "group-previous",
+			argsUsage:   "",
+			description: "Group by the previous mode",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.SetGroupingMode(model.contentList.GetGroupingMode().Next(-1))
+				return nil, nil
+			},
+		},
+		{
+			name:        "toggle-wrap",
+			argsUsage:   "",
+			description: "Switch between wrapping and truncating long names & tags",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.SetWrapping(!model.contentList.IsWrapping())
+				return nil, nil
+			},
+		},
+		{
+			name:        "toggle-previews",
+			argsUsage:   "",
+			description: "Show or hide the first line of each entry",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.contentList.SetShowingPreviews(!model.contentList.IsShowingPreviews())
+				return nil, nil
+			},
+		},
+		{
+			name:        "focus-filters",
+			argsUsage:   "",
+			description: "Start editing the filters",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				return model.focusFilterPane(), nil
+			},
+		},
+		{
+			name:        "filter",
+			argsUsage:   "TEXT",
+			description: "Add a filter on entry names",
+			minArgs:     1,
+			maxArgs:     unlimitedArgs,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.filterPane.AddFilter(strings.Join(args, " "), false)
+				model.propagateFilterChanges()
+				return nil, nil
+			},
+		},
+		{
+			name:        "tag",
+			argsUsage:   "TAG",
+			description: "Add a filter on entry tags",
+			minArgs:     1,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				return model.tags
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.filterPane.AddFilter(args[0], true)
+				model.propagateFilterChanges()
+				return nil, nil
+			},
+		},
+		{
+			name:        "clear-filters",
+			argsUsage:   "",
+			description: "Remove all the filters",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				model.filterPane.Clear()
+				model.propagateFilterChanges()
+				return nil, nil
+			},
+		},
+		{
+			name:        "new",
+			argsUsage:   "",
+			description: "Create a new entry",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				return model.openModalForm(model.createContentForm, ""), nil
+			},
+		},
+		{
+			name:        "retag",
+			argsUsage:   "TAG",
+			description: "Move the selected entries (or the highlighted one) to the tag; an empty tag (\"\") untags them",
+			minArgs:     1,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				return model.tags
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				newTag := strings.TrimPrefix(args[0], "#")
+				if err := journal_store.ValidateTag(newTag); err != nil {
+					return nil, err
+				}
+
+				entryPaths := model.getTargetEntryPaths()
+				if len(entryPaths) == 0 {
+					return nil, fmt.Errorf("there are no entries to retag")
+				}
+				operations := make([]action_journal.Operation, 0, len(entryPaths))
+				for _, entryPath := range entryPaths {
+					item, err := model.store.Get(entryPath)
+					if err != nil {
+						return nil, err
+					}
+					operation, err := action_journal.NewMoveOperation(model.store, entryPath, newTag, item.Name)
+					if err != nil {
+						return nil, err
+					}
+					operations = append(operations, operation)
+				}
+
+				// All the entries go in one operation, so that a single undo puts them all back
+				description := fmt.Sprintf("retag %s to %s", pluralizeEntries(len(entryPaths)), journal_store.DescribeTag(newTag))
+				model.doOperation(action_journal.NewBatchOperation(description, operations))
+				return nil, nil
+			},
+		},
+		{
+			name:        "rename",
+			argsUsage:   "[NAME]",
+			description: "Rename the highlighted entry, asking for the new name if it isn't given",
+			minArgs:     0,
+			maxArgs:     1,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				entryPath, found := model.getHighlightedEntryPath()
+				if !found {
+					return nil, fmt.Errorf("there's no entry to rename")
+				}
+				if len(args) > 0 {
+					if err := model.renameEntry(entryPath, args[0]); err != nil {
+						return nil, err
+					}
+					return model.offerLinkRewrite(), nil
+				}
+
+				item, err := model.store.Get(entryPath)
+				if err != nil {
+					return nil, err
+				}
+				model.modalEntryPath = entryPath
+				return model.openModalForm(model.renameEntryForm, item.Name), nil
+			},
+		},
+		{
+			name:        "move",
+			argsUsage:   "[TAG]",
+			description: "Move the highlighted entry to the tag's directory, asking for the tag if it isn't given",
+			minArgs:     0,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				return model.tags
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				entryPath, found := model.getHighlightedEntryPath()
+				if !found {
+					return nil, fmt.Errorf("there's no entry to move")
+				}
+				if len(args) > 0 {
+					return nil, model.moveEntry(entryPath, args[0])
+				}
+
+				item, err := model.store.Get(entryPath)
+				if err != nil {
+					return nil, err
+				}
+				model.modalEntryPath = entryPath
+				return model.openModalForm(model.moveEntryForm, getTag(item)), nil
+			},
+		},
+		{
+			name:        "delete",
+			argsUsage:   "",
+			description: "Delete the selected entries (or the highlighted one)",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				entryPaths := model.getTargetEntryPaths()
+				if len(entryPaths) == 0 {
+					return nil, fmt.Errorf("there are no entries to delete")
+				}
+				operations := make([]action_journal.Operation, 0, len(entryPaths))
+				for _, entryPath := range entryPaths {
+					operations = append(operations, action_journal.NewDeleteOperation(model.store, entryPath))
+				}
+
+				description := fmt.Sprintf("delete %s", pluralizeEntries(len(entryPaths)))
+				model.doOperation(action_journal.NewBatchOperation(description, operations))
+				return nil, nil
+			},
+		},
+		{
+			name:        "export",
+			argsUsage:   "FILE [FORMAT]",
+			description: "Export the selected entries (or all the shown ones) to the file, as markdown, html, tar or zip (from the file's extension if not given)",
+			minArgs:     1,
+			maxArgs:     2,
+			completeArg: func(model Model, argIdx int) []string {
+				if argIdx != 1 {
+					return nil
+				}
+				return exportFormatNames
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				filepath := args[0]
+				format, found := exporter.GetFormatForFilepath(filepath)
+				if len(args) > 1 {
+					var err error
+					if format, err = exporter.ParseFormat(args[1]); err != nil {
+						return nil, err
+					}
+				} else if !found {
+					return nil, fmt.Errorf("can't tell the format from the file's extension; give one of: %s", strings.Join(exportFormatNames, ", "))
+				}
+
+				entryPaths := model.getSelectedEntryPaths()
+				if len(entryPaths) == 0 {
+					entryPaths = model.getShownEntryPaths()
+				}
+				if len(entryPaths) == 0 {
+					return nil, fmt.Errorf("there are no entries to export")
+				}
+				if err := exporter.ExportToFile(model.store, entryPaths, format, exporter.DefaultTitle, filepath); err != nil {
+					return nil, err
+				}
+				model.commandLine.SetStatus(fmt.Sprintf("Exported %s to %s", pluralizeEntries(len(entryPaths)), filepath), false)
+				return nil, nil
+			},
+		},
+		{
+			name:        "undo",
+			argsUsage:   "",
+			description: "Undo the last change to the entries",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				operation, err := model.actionJournal.Undo()
+				model.reloadContent()
+				if err != nil {
+					return nil, err
+				}
+				if err := model.commitChanges("undo " + operation.GetDescription()); err != nil {
+					return nil, err
+				}
+				model.commandLine.SetStatus("Undid: "+operation.GetDescription(), false)
+				return nil, nil
+			},
+		},
+		{
+			name:        "redo",
+			argsUsage:   "",
+			description: "Redo the last undone change to the entries",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				operation, err := model.actionJournal.Redo()
+				model.reloadContent()
+				if err != nil {
+					return nil, err
+				}
+				if err := model.commitChanges("redo " + operation.GetDescription()); err != nil {
+					return nil, err
+				}
+				model.commandLine.SetStatus("Redid: "+operation.GetDescription(), false)
+				return nil, nil
+			},
+		},
+		{
+			name:        commandLineCommandName,
+			argsUsage:   "",
+			description: "Start typing a command",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				return model.focusCommandLine(), nil
+			},
+		},
+		{
+			name:        "map",
+			argsUsage:   "KEY COMMAND [ARGS...]",
+			description: "Make the key run the command",
+			minArgs:     2,
+			maxArgs:     unlimitedArgs,
+			completeArg: func(model Model, argIdx int) []string {
+				if argIdx == 1 {
+					return getCommandNames()
+				}
+				return nil
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				if _, found := getCommand(args[1]); !found {
+					return nil, fmt.Errorf("unknown command '%s'", args[1])
+				}
+				quotedArgs := make([]string, len(args)-1)
+				for idx, arg := range args[1:] {
+					quotedArgs[idx] = command_line.QuoteWord(arg)
+				}
+				commandText := strings.Join(quotedArgs, " ")
+				if commandText != commandLineCommandName && model.isOnlyCommandLineKey(args[0]) {
+					return nil, fmt.Errorf("%s can't be remapped, since it's the only key that runs '%s'", args[0], commandLineCommandName)
+				}
+				model.keyBindings[args[0]] = commandText
+				model.commandLine.SetStatus(fmt.Sprintf("%s runs '%s'", args[0], commandText), false)
+				return nil, nil
+			},
+		},
+		{
+			name:        "unmap",
+			argsUsage:   "KEY",
+			description: "Make the key do nothing",
+			minArgs:     1,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				result := make([]string, 0, len(model.keyBindings))
+				for key := range model.keyBindings {
+					result = append(result, key)
+				}
+				sort.Strings(result)
+				return result
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				if _, found := model.keyBindings[args[0]]; !found {
+					return nil, fmt.Errorf("%s isn't mapped to anything", args[0])
+				}
+				if model.isOnlyCommandLineKey(args[0]) {
+					return nil, fmt.Errorf("%s can't be unmapped, since it's the only key that runs '%s'", args[0], commandLineCommandName)
+				}
+				delete(model.keyBindings, args[0])
+				return nil, nil
+			},
+		},
+		{
+			name:        "help",
+			argsUsage:   "[COMMAND]",
+			description: "Describe the command, or list all the commands",
+			minArgs:     0,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				return getCommandNames()
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				if len(args) == 0 {
+					model.commandLine.SetStatus("Commands: "+strings.Join(getCommandNames(), " "), false)
+					return nil, nil
+				}
+				cmd, found := getCommand(args[0])
+				if !found {
+					return nil, fmt.Errorf("unknown command '%s'", args[0])
+				}
+				model.commandLine.SetStatus(fmt.Sprintf("%s - %s", cmd.getUsage(), cmd.description), false)
+				return nil, nil
+			},
+		},
+		{
+			name:        "pick",
+			argsUsage:   "",
+			description: "In pick mode, exit and print the selected entries (or the highlighted one)",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				if !model.isPickMode {
+					return nil, fmt.Errorf("picking only works when the journal was started with 'cli-journal pick'")
+				}
+				entryPaths := model.getTargetEntryPaths()
+				if len(entryPaths) == 0 {
+					return nil, fmt.Errorf("there are no entries to pick")
+				}
+				model.pickedEntryPaths = entryPaths
+				return tea.Quit, nil
+			},
+		},
+		{
+			name:        "switch-journal",
+			argsUsage:   "[NAME]",
+			description: "Switch to another journal, picking it from a list if it isn't given",
+			minArgs:     0,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				return model.getWorkspaceNames()
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				if len(model.workspaces) < 2 {
+					return nil, fmt.Errorf("there are no other journals to switch to; they can be added under 'journals' in the config")
+				}
+				if len(args) == 0 {
+					return model.openWorkspaceSwitcher(), nil
+				}
+
+				for workspaceIdx, workspace := range model.workspaces {
+					if workspace.Name == args[0] {
+						return nil, model.switchWorkspace(workspaceIdx)
+					}
+				}
+				return nil, fmt.Errorf("there's no journal named '%s'", args[0])
+			},
+		},
+		{
+			name:        "history",
+			argsUsage:   "",
+			description: "Show the highlighted entry's past versions, what changed between them, and restore one",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				entryPath, found := model.getHighlightedEntryPath()
+				if !found {
+					return nil, fmt.Errorf("there's no entry to show the history of")
+				}
+				return model.openHistoryViewer(entryPath)
+			},
+		},
+		{
+			name:        "follow-link",
+			argsUsage:   "",
+			description: "Jump to the entry that the highlighted entry [[links]] to, choosing one if it links to several",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				entryPath, found := model.getHighlightedEntryPath()
+				if !found {
+					return nil, fmt.Errorf("there's no entry to follow the links of")
+				}
+				return model.followLink(entryPath)
+			},
+		},
+		{
+			name:        "backlinks",
+			argsUsage:   "",
+			description: "List the entries that link to the highlighted entry, to jump to one of them",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				entryPath, found := model.getHighlightedEntryPath()
+				if !found {
+					return nil, fmt.Errorf("there's no entry to show the backlinks of")
+				}
+				return model.openBacklinks(entryPath)
+			},
+		},
+		{
+			name:        "broken-links",
+			argsUsage:   "",
+			description: "Select the entries with links to entries that don't exist",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				return nil, model.selectEntriesWithBrokenLinks()
+			},
+		},
+		{
+			name:        "tasks",
+			argsUsage:   "[all]",
+			description: "List the '- [ ]' tasks in the shown entries (or all entries), to check them off",
+			minArgs:     0,
+			maxArgs:     1,
+			completeArg: func(model Model, argIdx int) []string {
+				return []string{allTasksArg}
+			},
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				if len(args) == 0 {
+					return model.openTaskViewer(model.getShownEntryPaths())
+				}
+				if args[0] != allTasksArg {
+					return nil, fmt.Errorf("'%s' isn't a valid argument; give '%s' to list the tasks in all entries", args[0], allTasksArg)
+				}
+
+				entries, err := model.store.List()
+				if err != nil {
+					return nil, err
+				}
+				entryPaths := make([]string, 0, len(entries))
+				for _, entry := range entries {
+					entryPaths = append(entryPaths, entry.Path)
+				}
+				return model.openTaskViewer(entryPaths)
+			},
+		},
+		{
+			name:        "unlock",
+			argsUsage:   "",
+			description: "Ask for the journal's passphrase so encrypted entries can be read & written (or choose one, if it has none)",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				return model.openUnlockForm()
+			},
+		},
+		{
+			name:        "lock",
+			argsUsage:   "",
+			description: "Forget the journal's passphrase, hiding encrypted entries until it's unlocked again",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				return nil, model.lock()
+			},
+		},
+		{
+			name:        "encrypt",
+			argsUsage:   "",
+			description: "Encrypt the selected entries (or the highlighted one) on disk",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				return nil, model.setEncrypted(true)
+			},
+		},
+		{
+			name:        "decrypt",
+			argsUsage:   "",
+			description: "Store the selected entries (or the highlighted one) as plaintext again",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				return nil, model.setEncrypted(false)
+			},
+		},
+		{
+			name:        "quit",
+			argsUsage:   "",
+			description: "Exit the journal",
+			minArgs:     0,
+			maxArgs:     0,
+			completeArg: nil,
+			run: func(model *Model, args []string) (tea.Cmd, error) {
+				return tea.Quit, nil
+			},
+		},
+	}
+}
+
+// runCommand runs the given command line text, displaying any error in the status line
+func (model *Model) runCommand(commandText string) tea.Cmd {
+	cmd, err := model.runCommandWithoutStatus(commandText)
+	if err != nil {
+		model.commandLine.SetStatus(err.Error(), true)
+	}
+	return cmd
+}
+
+func (model *Model) runCommandWithoutStatus(commandText string) (tea.Cmd, error) {
+	words, err := command_line.SplitWords(commandText)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	cmd, found := getCommand(words[0])
+	if !found {
+		return nil, fmt.Errorf("unknown command '%s'", words[0])
+	}
+
+	args := words[1:]
+	if len(args) < cmd.minArgs || (cmd.maxArgs != unlimitedArgs && len(args) > cmd.maxArgs) {
+		return nil, fmt.Errorf("usage: %s", cmd.getUsage())
+	}
+	return cmd.run(model, args)
+}
+
+// getCommandCompletions gets the tab-completion candidates for the word after the given ones
+func (model Model) getCommandCompletions(precedingWords []string) []string {
+	if len(precedingWords) == 0 {
+		return getCommandNames()
+	}
+
+	cmd, found := getCommand(precedingWords[0])
+	if !found || cmd.completeArg == nil {
+		return nil
+	}
+	return cmd.completeArg(model, len(precedingWords)-1)
+}
+
+// isOnlyCommandLineKey checks whether the key is the only one bound to the command line, so it can't be taken away
+func (model Model) isOnlyCommandLineKey(key string) bool {
+	if model.keyBindings[key] != commandLineCommandName {
+		return false
+	}
+	for otherKey, commandText := range model.keyBindings {
+		if otherKey != key && commandText == commandLineCommandName {
+			return false
+		}
+	}
+	return true
+}
+
+func (model Model) getShownEntryNames() []string {
+	list := model.contentList.GetChecklist().GetFilterableList()
+	items := list.GetItems()
+	result := make([]string, 0)
+	for _, originalIdx := range list.GetFilteredItemIndices() {
+		result = append(result, items[originalIdx].GetName())
+	}
+	return result
+}
+
+func (cmd command) getUsage() string {
+	if len(cmd.argsUsage) == 0 {
+		return cmd.name
+	}
+	return cmd.name + " " + cmd.argsUsage
+}
+
+func getCommand(name string) (command, bool) {
+	for _, cmd := range getAllCommands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func getCommandNames() []string {
+	result := make([]string, 0)
+	for _, cmd := range getAllCommands() {
+		result = append(result, cmd.name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func pluralizeEntries(count int) string {
+	if count == 1 {
+		return "1 entry"
+	}
+	return fmt.Sprintf("%d entries", count)
+}
@@ -0,0 +1,97 @@
+package app_model
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/entry_crypto"
+	"github.com/mieubrisse/cli-journal-go/global_styles"
+)
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func isValidPassphrase(passphrase string) bool {
+	return len(passphrase) > 0
+}
+
+// openUnlockForm asks for the journal's passphrase, or for a new one if it doesn't have one yet
+func (model *Model) openUnlockForm() (tea.Cmd, error) {
+	keyring := model.store.GetKeyring()
+	if keyring.IsUnlocked() {
+		return nil, fmt.Errorf("the journal is already unlocked")
+	}
+	if !keyring.HasPassphrase() {
+		return model.openModalForm(model.newPassphraseForm, ""), nil
+	}
+	return model.openModalForm(model.unlockForm, ""), nil
+}
+
+func (model *Model) unlock(passphrase string) error {
+	if err := model.store.GetKeyring().Unlock(passphrase); err != nil {
+		return err
+	}
+	// Encrypted entries' previews can be shown now
+	model.reloadContent()
+	model.refreshFooterIndicators()
+	return nil
+}
+
+func (model *Model) setPassphrase(passphrase string) error {
+	if err := model.store.GetKeyring().SetPassphrase(passphrase); err != nil {
+		return err
+	}
+	model.refreshFooterIndicators()
+	return nil
+}
+
+func (model *Model) lock() error {
+	keyring := model.store.GetKeyring()
+	if !keyring.IsUnlocked() {
+		return fmt.Errorf("the journal is already locked")
+	}
+	keyring.Lock()
+	// Takes the decrypted previews off the screen
+	model.reloadContent()
+	model.refreshFooterIndicators()
+	return nil
+}
+
+// setEncrypted encrypts or decrypts the selected entries (or the highlighted one), as a single change that can be undone
+func (model *Model) setEncrypted(isEncrypted bool) error {
+	entryPaths := model.getTargetEntryPaths()
+	if len(entryPaths) == 0 {
+		return fmt.Errorf("there are no entries to change")
+	}
+	if !model.store.GetKeyring().IsUnlocked() {
+		return entry_crypto.ErrLocked
+	}
+
+	operations := make([]action_journal.Operation, 0, len(entryPaths))
+	for _, entryPath := range entryPaths {
+		operations = append(operations, action_journal.NewSetEncryptedOperation(model.store, entryPath, isEncrypted))
+	}
+	verb := "decrypt"
+	if isEncrypted {
+		verb = "encrypt"
+	}
+	model.doOperation(action_journal.NewBatchOperation(fmt.Sprintf("%s %s", verb, pluralizeEntries(len(entryPaths))), operations))
+	return nil
+}
+
+// refreshFooterIndicators shows whether the journal is locked (if it uses encryption) and its git status in the list's
+// footer
+func (model *Model) refreshFooterIndicators() {
+	lockIndicator := ""
+	if keyring := model.store.GetKeyring(); keyring.HasPassphrase() {
+		if keyring.IsUnlocked() {
+			lockIndicator = lipgloss.NewStyle().Foreground(global_styles.Orange).Render("unlocked")
+		} else {
+			lockIndicator = lipgloss.NewStyle().Faint(true).Render("locked")
+		}
+	}
+	model.contentList.SetFooterIndicators(lockIndicator, model.gitStatusIndicator)
+}
@@ -0,0 +1,118 @@
+package app_model
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/git_journal"
+	"github.com/mieubrisse/cli-journal-go/global_styles"
+	"time"
+)
+
+// How often the journal is checked for changes made outside the app, since nothing tells us about them
+const gitStatusPollInterval = 5 * time.Second
+
+// Sent periodically to re-check the journal's git status
+type gitStatusTickMsg struct{}
+
+// The result of checking a journal's git status, which is done in the background as it can be slow on big journals
+type gitStatusMsg struct {
+	repo *git_journal.Repo
+
+	// When the check was started
+	checkTime time.Time
+
+	changedPaths []string
+	err          error
+}
+
+// SetGitRepo makes every change to the current journal a commit in the repo (whose changes the model's store must
+// report to it), and shows the journal's git status in the list footer once Init has checked it
+func (model *Model) SetGitRepo(repo *git_journal.Repo) {
+	model.gitRepo = repo
+	model.workspaces[model.currentWorkspaceIdx].GitRepo = repo
+	model.gitStatusIndicator = ""
+	model.refreshFooterIndicators()
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// commitChanges commits the changes just made to the entries, described like an operation (e.g. "retag 3 entries to
+// #work"), doing nothing if the journal isn't kept in git
+func (model *Model) commitChanges(description string) error {
+	if model.gitRepo == nil {
+		return nil
+	}
+	err := model.gitRepo.CommitPending(description)
+	model.isGitStatusStale = true
+	if err != nil {
+		return fmt.Errorf("the change was made but couldn't be committed: %w", err)
+	}
+	return nil
+}
+
+// resetGitStatus clears the footer's git status indicator (e.g. because the journal changed), and has it re-checked
+func (model *Model) resetGitStatus() {
+	model.gitStatusIndicator = ""
+	model.gitStatusCheckTime = time.Time{}
+	model.isGitStatusStale = true
+	model.refreshFooterIndicators()
+}
+
+// checkGitStatus gets the current journal's git status in the background, as a gitStatusMsg
+func (model Model) checkGitStatus() tea.Cmd {
+	repo := model.gitRepo
+	if repo == nil {
+		return nil
+	}
+	checkTime := time.Now()
+	return func() tea.Msg {
+		changedPaths, err := repo.GetChangedPaths()
+		return gitStatusMsg{repo: repo, checkTime: checkTime, changedPaths: changedPaths, err: err}
+	}
+}
+
+// applyGitStatus updates the footer's indicator of changes made to the journal outside the app, unless the status is
+// for another journal or older than the one shown
+func (model *Model) applyGitStatus(msg gitStatusMsg) {
+	if msg.repo != model.gitRepo || msg.checkTime.Before(model.gitStatusCheckTime) {
+		return
+	}
+	model.gitStatusCheckTime = msg.checkTime
+
+	// Counted here rather than in the background, since it depends on what the app has changed but not yet committed
+	numOutsideChanges := 0
+	if msg.err == nil {
+		numOutsideChanges = model.gitRepo.CountOutsideChanges(msg.changedPaths)
+	}
+	switch {
+	case msg.err != nil:
+		model.gitStatusIndicator = lipgloss.NewStyle().Foreground(global_styles.Red).Render("git status unknown")
+	case numOutsideChanges == 0:
+		model.gitStatusIndicator = lipgloss.NewStyle().Faint(true).Render("git up to date")
+	default:
+		numberStr := lipgloss.NewStyle().Foreground(global_styles.Orange).Render(fmt.Sprintf("%d", numOutsideChanges))
+		textStr := lipgloss.NewStyle().Foreground(global_styles.White).Render(" uncommitted outside changes")
+		model.gitStatusIndicator = numberStr + textStr
+	}
+	model.refreshFooterIndicators()
+}
+
+// startGitStatusPolling starts re-checking the git status if any of the journals are kept in git
+func (model Model) startGitStatusPolling() tea.Cmd {
+	for _, workspace := range model.workspaces {
+		if workspace.GitRepo != nil {
+			return tickGitStatus()
+		}
+	}
+	return nil
+}
+
+func tickGitStatus() tea.Cmd {
+	return tea.Tick(gitStatusPollInterval, func(time.Time) tea.Msg {
+		return gitStatusTickMsg{}
+	})
+}
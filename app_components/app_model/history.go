@@ -0,0 +1,130 @@
+package app_model
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/app_components/history_viewer"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"time"
+)
+
+const (
+	maxHistoryViewerWidth = 120
+
+	// Room left around the history viewer, so it's clearly on top of the list
+	historyViewerMargin = 4
+
+	restoredVersionDateFormat = "2006-01-02 15:04"
+)
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// openHistoryViewer shows the entry's versions, as committed to the journal's git repo
+func (model *Model) openHistoryViewer(entryPath string) (tea.Cmd, error) {
+	if model.gitRepo == nil {
+		return nil, fmt.Errorf("entries only have a history when the journal is kept in git; set 'git.auto_commit' in the config to turn that on")
+	}
+	item, err := model.store.Get(entryPath)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := model.getEntryVersions(entryPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("entry '%s' has no history yet", item.Name)
+	}
+
+	model.historyEntryPath = entryPath
+	model.historyViewer.SetVersions(fmt.Sprintf("History of '%s'", item.Name), versions)
+	model.resizeHistoryViewer()
+
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.contentList.Blur())
+	cmds = append(cmds, model.historyViewer.Focus())
+	return tea.Batch(cmds...), nil
+}
+
+func (model *Model) closeHistoryViewer() tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.historyViewer.Blur())
+	cmds = append(cmds, model.contentList.Focus())
+	return tea.Batch(cmds...)
+}
+
+// restoreHighlightedVersion writes the highlighted version over the entry, closing the viewer if that worked
+func (model *Model) restoreHighlightedVersion() (tea.Cmd, error) {
+	version, found := model.historyViewer.GetHighlightedVersion()
+	if !found {
+		return nil, fmt.Errorf("there's no version to restore")
+	}
+	if version.IsCurrent {
+		return nil, fmt.Errorf("that's the entry as it is now; pick an older version to restore")
+	}
+	item, err := model.store.Get(model.historyEntryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf(
+		"restore '%s' to its version from %s",
+		item.Name,
+		version.Timestamp.Format(restoredVersionDateFormat),
+	)
+	cmd := model.closeHistoryViewer()
+	model.doOperation(action_journal.NewWriteOperation(model.store, model.historyEntryPath, version.Body, description))
+	return cmd, nil
+}
+
+// getEntryVersions gets the entry's committed versions, newest first, preceded by the entry as it is on disk if that
+// differs from the newest commit
+func (model Model) getEntryVersions(entryPath string) ([]history_viewer.Version, error) {
+	committedVersions, err := model.gitRepo.GetHistory(entryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]history_viewer.Version, 0, len(committedVersions)+1)
+	for _, committedVersion := range committedVersions {
+		contents, err := model.gitRepo.ReadVersion(committedVersion)
+		if err != nil {
+			return nil, err
+		}
+		body, err := model.store.DecryptContents(entryPath, contents)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, history_viewer.Version{
+			Timestamp:   committedVersion.Timestamp,
+			Description: committedVersion.Description,
+			Body:        body,
+			IsCurrent:   false,
+		})
+	}
+
+	currentBody, err := model.store.Read(entryPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 || result[0].Body != currentBody {
+		currentVersion := history_viewer.Version{
+			Timestamp:   time.Now(),
+			Description: "",
+			Body:        currentBody,
+			IsCurrent:   true,
+		}
+		result = append([]history_viewer.Version{currentVersion}, result...)
+	}
+	return result, nil
+}
+
+func (model *Model) resizeHistoryViewer() {
+	width := helpers.GetMinInt(helpers.GetMaxInt(0, model.width-historyViewerMargin), maxHistoryViewerWidth)
+	height := helpers.GetMaxInt(0, model.height-historyViewerMargin)
+	model.historyViewer.Resize(width, height)
+}
@@ -0,0 +1,327 @@
+package app_model
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"github.com/mieubrisse/cli-journal-go/wiki_links"
+)
+
+const (
+	maxLinksModalWidth = 70
+
+	// The title and padding around the links modal's options
+	linksModalChromeHeight = 4
+
+	linkedEntryDateFormat = "2006-01-02"
+
+	rewriteLinksOptionIdx = 0
+)
+
+var rewriteLinksOptions = []string{"Rewrite the links", "Leave the links as they are"}
+
+// The links to an entry that was just renamed, which the user is asked whether to rewrite
+type linkRewrite struct {
+	oldItem content_item.ContentItem
+	newItem content_item.ContentItem
+
+	// The entries with links to the renamed one
+	sourcePaths []string
+
+	// Built before the rename, so it knows which links went to the renamed entry
+	index *wiki_links.Index
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (model *Model) buildLinkIndex() (*wiki_links.Index, error) {
+	index, err := wiki_links.BuildIndex(model.store)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred reading the links between entries: %w", err)
+	}
+	return index, nil
+}
+
+// openBacklinks lists the entries that link to the entry, to jump to one of them
+func (model *Model) openBacklinks(entryPath string) (tea.Cmd, error) {
+	index, err := model.buildLinkIndex()
+	if err != nil {
+		return nil, err
+	}
+	item, found := index.GetItem(entryPath)
+	if !found {
+		return nil, fmt.Errorf("entry '%s' no longer exists", entryPath)
+	}
+
+	backlinks := index.GetBacklinks(entryPath)
+	if len(backlinks) == 0 {
+		return nil, fmt.Errorf("no entries link to '%s'%s", item.Name, describeUnreadableEntries(index))
+	}
+	options := make([]string, 0, len(backlinks))
+	entryPaths := make([]string, 0, len(backlinks))
+	for _, backlink := range backlinks {
+		options = append(options, describeLinkedEntry(backlink))
+		entryPaths = append(entryPaths, backlink.Path)
+	}
+	return model.openLinksModal(fmt.Sprintf("Backlinks to '%s'", item.Name), options, entryPaths), nil
+}
+
+// followLink jumps to the entry that the entry links to, asking which one if it links to more than one
+func (model *Model) followLink(entryPath string) (tea.Cmd, error) {
+	index, err := model.buildLinkIndex()
+	if err != nil {
+		return nil, err
+	}
+	item, found := index.GetItem(entryPath)
+	if !found {
+		return nil, fmt.Errorf("entry '%s' no longer exists", entryPath)
+	}
+
+	// Each target is only listed once, however many times it's linked to
+	options := make([]string, 0)
+	entryPaths := make([]string, 0)
+	seenTargets := map[string]bool{}
+	for _, link := range index.GetLinks(entryPath) {
+		targetKey := link.TargetPath
+		if link.IsBroken() {
+			targetKey = link.Target
+		}
+		if seenTargets[targetKey] {
+			continue
+		}
+		seenTargets[targetKey] = true
+
+		if link.IsBroken() {
+			options = append(options, fmt.Sprintf("%s (missing)", link.Target))
+			entryPaths = append(entryPaths, "")
+			continue
+		}
+		target, _ := index.GetItem(link.TargetPath)
+		options = append(options, describeLinkedEntry(target))
+		entryPaths = append(entryPaths, link.TargetPath)
+	}
+
+	switch {
+	case len(options) == 0:
+		if item.IsEncrypted && !model.store.GetKeyring().IsUnlocked() {
+			return nil, fmt.Errorf("'%s' is encrypted; unlock the journal to follow its links", item.Name)
+		}
+		return nil, fmt.Errorf("'%s' has no [[links]]", item.Name)
+	case len(options) == 1 && len(entryPaths[0]) > 0:
+		return nil, model.jumpToEntry(entryPaths[0])
+	}
+	return model.openLinksModal(fmt.Sprintf("Links from '%s'", item.Name), options, entryPaths), nil
+}
+
+// selectEntriesWithBrokenLinks selects the entries with links to entries that don't exist, so they can be found & fixed
+func (model *Model) selectEntriesWithBrokenLinks() error {
+	index, err := model.buildLinkIndex()
+	if err != nil {
+		return err
+	}
+	brokenLinks := index.GetBrokenLinks()
+	if len(brokenLinks) == 0 {
+		model.commandLine.SetStatus("No broken links"+describeUnreadableEntries(index), false)
+		return nil
+	}
+
+	sourcePaths := map[string]bool{}
+	for _, brokenLink := range brokenLinks {
+		sourcePaths[brokenLink.SourcePath] = true
+	}
+	checklist := model.contentList.GetChecklist()
+	checklist.SetAllItemsSelection(false)
+	for originalIdx, item := range checklist.GetItems() {
+		if sourcePaths[item.GetPath()] {
+			checklist.SetItemSelection(originalIdx, true)
+		}
+	}
+
+	numLinksStr := "1 broken link"
+	if len(brokenLinks) != 1 {
+		numLinksStr = fmt.Sprintf("%d broken links", len(brokenLinks))
+	}
+	model.commandLine.SetStatus(
+		fmt.Sprintf("Selected the %s with %s%s", pluralizeEntries(len(sourcePaths)), numLinksStr, describeUnreadableEntries(index)),
+		false,
+	)
+	return nil
+}
+
+// jumpToEntry highlights the entry, clearing the filters if they hide it
+func (model *Model) jumpToEntry(entryPath string) error {
+	if model.highlightEntry(entryPath) {
+		return nil
+	}
+
+	model.filterPane.Clear()
+	model.propagateFilterChanges()
+	model.contentList.GetChecklist().GetFilterableList().UnfoldAllGroups()
+	if !model.highlightEntry(entryPath) {
+		return fmt.Errorf("entry '%s' isn't in the list", entryPath)
+	}
+	model.commandLine.SetStatus("Cleared the filters to show the linked entry", false)
+	return nil
+}
+
+func (model *Model) openLinksModal(title string, options []string, entryPaths []string) tea.Cmd {
+	model.linksModal.SetTitle(title)
+	model.linksModal.SetOptions(options, 0)
+	model.linksModalEntryPaths = entryPaths
+	model.resizeLinkModals()
+
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.contentList.Blur())
+	cmds = append(cmds, model.linksModal.Focus())
+	return tea.Batch(cmds...)
+}
+
+// closeLinksModal closes the links modal, jumping to the highlighted entry if asked to
+func (model *Model) closeLinksModal(isJumping bool) (tea.Cmd, error) {
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.linksModal.Blur())
+	cmds = append(cmds, model.contentList.Focus())
+	cmd := tea.Batch(cmds...)
+
+	optionIdx, found := model.linksModal.GetHighlightedOptionIndex()
+	if !isJumping || !found {
+		return cmd, nil
+	}
+	entryPath := model.linksModalEntryPaths[optionIdx]
+	if len(entryPath) == 0 {
+		return cmd, fmt.Errorf("there's no entry with that name to go to")
+	}
+	return cmd, model.jumpToEntry(entryPath)
+}
+
+// findLinksToRename remembers the entries linking to the entry that's about to be renamed, so they can be offered to
+// be rewritten once it has been (by offerLinkRewrite)
+func (model *Model) findLinksToRename(entryPath string) {
+	model.pendingLinkRewrite = linkRewrite{}
+
+	// Links are a nicety, so not being able to read them doesn't stop the rename
+	index, err := wiki_links.BuildIndex(model.store)
+	if err != nil {
+		return
+	}
+	item, found := index.GetItem(entryPath)
+	if !found {
+		return
+	}
+	sourcePaths := make([]string, 0)
+	for _, backlink := range index.GetBacklinks(entryPath) {
+		sourcePaths = append(sourcePaths, backlink.Path)
+	}
+	model.pendingLinkRewrite = linkRewrite{
+		oldItem:     item,
+		newItem:     content_item.ContentItem{},
+		sourcePaths: sourcePaths,
+		index:       index,
+	}
+}
+
+// setRenamedEntry completes the pending link rewrite with what the entry is now called
+func (model *Model) setRenamedEntry(newEntryPath string) {
+	newItem, err := model.store.Get(newEntryPath)
+	if err != nil {
+		model.pendingLinkRewrite = linkRewrite{}
+		return
+	}
+	model.pendingLinkRewrite.newItem = newItem
+}
+
+// offerLinkRewrite asks whether to rewrite the links to the entry that was just renamed, if any entries link to it
+func (model *Model) offerLinkRewrite() tea.Cmd {
+	if len(model.pendingLinkRewrite.sourcePaths) == 0 {
+		return nil
+	}
+	newItem := model.pendingLinkRewrite.newItem
+
+	model.rewriteLinksModal.SetTitle(fmt.Sprintf(
+		"%s link to '%s'; point the links at '%s'?",
+		pluralizeEntries(len(model.pendingLinkRewrite.sourcePaths)),
+		model.pendingLinkRewrite.oldItem.Name,
+		newItem.Name,
+	))
+	model.rewriteLinksModal.SetOptions(rewriteLinksOptions, rewriteLinksOptionIdx)
+	model.resizeLinkModals()
+
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.contentList.Blur())
+	cmds = append(cmds, model.rewriteLinksModal.Focus())
+	return tea.Batch(cmds...)
+}
+
+// closeRewriteLinksModal closes the modal, rewriting the links if that's what was chosen
+func (model *Model) closeRewriteLinksModal(isConfirmed bool) tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.rewriteLinksModal.Blur())
+	cmds = append(cmds, model.contentList.Focus())
+
+	rewrite := model.pendingLinkRewrite
+	model.pendingLinkRewrite = linkRewrite{}
+	optionIdx, found := model.rewriteLinksModal.GetHighlightedOptionIndex()
+	if isConfirmed && found && optionIdx == rewriteLinksOptionIdx {
+		if err := model.rewriteLinks(rewrite); err != nil {
+			model.commandLine.SetStatus(err.Error(), true)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// rewriteLinks points the links at the renamed entry's new name, as a single change that can be undone
+func (model *Model) rewriteLinks(rewrite linkRewrite) error {
+	operations := make([]action_journal.Operation, 0, len(rewrite.sourcePaths))
+	for _, sourcePath := range rewrite.sourcePaths {
+		links := rewrite.index.GetLinks(sourcePath)
+		// The renamed entry can link to itself
+		if sourcePath == rewrite.oldItem.Path {
+			sourcePath = rewrite.newItem.Path
+		}
+		body, err := model.store.Read(sourcePath)
+		if err != nil {
+			return err
+		}
+		newBody, numRewritten := wiki_links.Rewrite(body, links, rewrite.oldItem.Path, rewrite.newItem)
+		if numRewritten == 0 {
+			continue
+		}
+		operations = append(operations, action_journal.NewWriteOperation(model.store, sourcePath, newBody, ""))
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+
+	description := fmt.Sprintf("rewrite links to '%s' in %s", rewrite.newItem.Name, pluralizeEntries(len(operations)))
+	model.doOperation(action_journal.NewBatchOperation(description, operations))
+	return nil
+}
+
+func (model *Model) resizeLinkModals() {
+	width := helpers.GetMinInt(model.width, maxLinksModalWidth)
+	height := helpers.GetMinInt(model.height, len(model.linksModalEntryPaths)+linksModalChromeHeight)
+	model.linksModal.Resize(width, height)
+	model.rewriteLinksModal.Resize(width, helpers.GetMinInt(model.height, len(rewriteLinksOptions)+linksModalChromeHeight))
+}
+
+func describeLinkedEntry(item content_item.ContentItem) string {
+	result := item.Name
+	if tag := getTag(item); len(tag) > 0 {
+		result += "  #" + tag
+	}
+	return result + "  " + item.Timestamp.Format(linkedEntryDateFormat)
+}
+
+// describeUnreadableEntries notes that encrypted entries' links are missing because the journal is locked, if they are
+func describeUnreadableEntries(index *wiki_links.Index) string {
+	numUnreadable := index.GetNumUnreadableEntries()
+	if numUnreadable == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s couldn't be read while the journal is locked)", pluralizeEntries(numUnreadable))
+}
@@ -0,0 +1,140 @@
+package app_model
+
+import (
+	"errors"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/app_components/task_viewer"
+	"github.com/mieubrisse/cli-journal-go/entry_crypto"
+	"github.com/mieubrisse/cli-journal-go/entry_tasks"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+)
+
+const (
+	maxTaskViewerWidth = 120
+
+	// Room left around the task viewer, so it's clearly on top of the list
+	taskViewerMargin = 4
+)
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// openTaskViewer lists the tasks in the entries, in the order the entries are given
+func (model *Model) openTaskViewer(entryPaths []string) (tea.Cmd, error) {
+	tasks := make([]task_viewer.Task, 0)
+	numUnreadableEntries := 0
+	for _, entryPath := range entryPaths {
+		entryTasks, err := model.getEntryTasks(entryPath)
+		if errors.Is(err, entry_crypto.ErrLocked) {
+			numUnreadableEntries++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, entryTasks...)
+	}
+
+	unreadableEntriesDescription := ""
+	if numUnreadableEntries > 0 {
+		unreadableEntriesDescription = fmt.Sprintf(
+			" (%s couldn't be read while the journal is locked)",
+			pluralizeEntries(numUnreadableEntries),
+		)
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("there are no tasks in the %s%s", pluralizeEntries(len(entryPaths)), unreadableEntriesDescription)
+	}
+
+	title := fmt.Sprintf("Tasks in %s%s", pluralizeEntries(len(entryPaths)), unreadableEntriesDescription)
+	model.taskViewer.SetTasks(title, tasks)
+	model.resizeTaskViewer()
+
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.contentList.Blur())
+	cmds = append(cmds, model.taskViewer.Focus())
+	return tea.Batch(cmds...), nil
+}
+
+func (model *Model) closeTaskViewer() tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.taskViewer.Blur())
+	cmds = append(cmds, model.contentList.Focus())
+	return tea.Batch(cmds...)
+}
+
+// toggleHighlightedTask checks or unchecks the highlighted task's box in its entry, as a change that can be undone
+func (model *Model) toggleHighlightedTask() error {
+	task, found := model.taskViewer.GetHighlightedTask()
+	if !found {
+		return fmt.Errorf("there's no task to check off")
+	}
+	body, err := model.store.Read(task.EntryPath)
+	if err != nil {
+		return err
+	}
+	newBody, err := entry_tasks.SetDone(body, task.Task, !task.IsDone)
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("check off '%s' in '%s'", task.Text, task.EntryName)
+	if task.IsDone {
+		description = fmt.Sprintf("uncheck '%s' in '%s'", task.Text, task.EntryName)
+	}
+	model.doOperation(action_journal.NewWriteOperation(model.store, task.EntryPath, newBody, description))
+
+	// The box is shown as it ended up in the entry, in case writing it failed
+	entryTasks, err := model.getEntryTasks(task.EntryPath)
+	if err != nil {
+		return err
+	}
+	for _, entryTask := range entryTasks {
+		if entryTask.LineIdx == task.LineIdx {
+			model.taskViewer.SetHighlightedTaskDone(entryTask.IsDone)
+		}
+	}
+	return nil
+}
+
+// goToHighlightedTask closes the viewer and highlights the entry that the highlighted task is in
+func (model *Model) goToHighlightedTask() (tea.Cmd, error) {
+	task, found := model.taskViewer.GetHighlightedTask()
+	cmd := model.closeTaskViewer()
+	if !found {
+		return cmd, nil
+	}
+	return cmd, model.jumpToEntry(task.EntryPath)
+}
+
+func (model Model) getEntryTasks(entryPath string) ([]task_viewer.Task, error) {
+	item, err := model.store.Get(entryPath)
+	if err != nil {
+		return nil, err
+	}
+	body, err := model.store.Read(entryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]task_viewer.Task, 0)
+	for _, task := range entry_tasks.Parse(body) {
+		result = append(result, task_viewer.Task{
+			Task:           task,
+			EntryPath:      entryPath,
+			EntryName:      item.Name,
+			EntryTimestamp: item.Timestamp,
+		})
+	}
+	return result, nil
+}
+
+func (model *Model) resizeTaskViewer() {
+	width := helpers.GetMinInt(helpers.GetMaxInt(0, model.width-taskViewerMargin), maxTaskViewerWidth)
+	height := helpers.GetMaxInt(0, model.height-taskViewerMargin)
+	model.taskViewer.Resize(width, height)
+}
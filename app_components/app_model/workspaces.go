@@ -0,0 +1,164 @@
+package app_model
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/git_journal"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"sort"
+)
+
+const (
+	maxWorkspaceSwitcherWidth = 50
+
+	// The title and padding around the switcher's list of workspaces
+	workspaceSwitcherChromeHeight = 4
+)
+
+// Workspace is a journal that can be switched to
+type Workspace struct {
+	Name  string
+	Store journal_store.Store
+
+	// Nil if the journal isn't kept in git; otherwise the store should tell it about changes
+	GitRepo *git_journal.Repo
+
+	// What the filter pane has the first time the journal is switched to
+	InitialFilterText string
+}
+
+// What's kept of a workspace while it's switched away from, so that switching back restores where the user was
+type workspaceState struct {
+	filterText string
+
+	actionJournal *action_journal.ActionJournal
+
+	selectedEntryPaths []string
+
+	// Empty if no entry was highlighted
+	highlightedEntryPath string
+}
+
+// SetWorkspaces sets the journals that can be switched between, the one at the given index being the one whose store
+// the model was created with
+func (model *Model) SetWorkspaces(workspaces []Workspace, currentWorkspaceIdx int) {
+	model.workspaces = workspaces
+	model.currentWorkspaceIdx = currentWorkspaceIdx
+	model.workspaceStates = make(map[string]workspaceState, len(workspaces))
+	model.SetGitRepo(workspaces[currentWorkspaceIdx].GitRepo)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (model *Model) openWorkspaceSwitcher() tea.Cmd {
+	names := model.getWorkspaceNames()
+	model.workspaceSwitcher.SetOptions(names, model.currentWorkspaceIdx)
+	model.resizeWorkspaceSwitcher()
+
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.contentList.Blur())
+	cmds = append(cmds, model.workspaceSwitcher.Focus())
+	return tea.Batch(cmds...)
+}
+
+func (model *Model) closeWorkspaceSwitcher() tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+	cmds = append(cmds, model.workspaceSwitcher.Blur())
+	cmds = append(cmds, model.contentList.Focus())
+	return tea.Batch(cmds...)
+}
+
+// switchWorkspace puts the workspace's journal behind the list, saving where the user was in the current one and
+// restoring where they were in the new one
+func (model *Model) switchWorkspace(workspaceIdx int) error {
+	if workspaceIdx == model.currentWorkspaceIdx {
+		return nil
+	}
+	workspace := model.workspaces[workspaceIdx]
+	content, err := workspace.Store.List()
+	if err != nil {
+		return fmt.Errorf("can't switch to journal '%s': %w", workspace.Name, err)
+	}
+
+	model.workspaceStates[model.workspaces[model.currentWorkspaceIdx].Name] = model.captureWorkspaceState()
+
+	state, found := model.workspaceStates[workspace.Name]
+	if !found {
+		state = workspaceState{
+			filterText:           workspace.InitialFilterText,
+			actionJournal:        action_journal.New(maxUndoHistoryLength),
+			selectedEntryPaths:   []string{},
+			highlightedEntryPath: "",
+		}
+	}
+	model.currentWorkspaceIdx = workspaceIdx
+	model.store = workspace.Store
+	model.actionJournal = state.actionJournal
+	model.gitRepo = workspace.GitRepo
+	model.resetGitStatus()
+
+	// Paths can repeat between journals, so nothing can be carried over from the old journal's selection
+	checklist := model.contentList.GetChecklist()
+	checklist.SetAllItemsSelection(false)
+	items := createEntryItems(content)
+	model.contentList.SetContent(items)
+	model.tags = getSortedTags(items)
+
+	model.filterPane.SetValue(state.filterText)
+	model.propagateFilterChanges()
+
+	selectedPaths := make(map[string]bool, len(state.selectedEntryPaths))
+	for _, entryPath := range state.selectedEntryPaths {
+		selectedPaths[entryPath] = true
+	}
+	for originalIdx, item := range checklist.GetItems() {
+		if selectedPaths[item.GetPath()] {
+			checklist.SetItemSelection(originalIdx, true)
+		}
+	}
+	if !model.highlightEntry(state.highlightedEntryPath) {
+		filterableList := checklist.GetFilterableList()
+		// Back to the top, which is never more places away than there are items
+		filterableList.Scroll(-len(filterableList.GetItems()))
+	}
+
+	model.commandLine.SetStatus(fmt.Sprintf("Switched to journal '%s'", workspace.Name), false)
+	return nil
+}
+
+func (model Model) captureWorkspaceState() workspaceState {
+	checklist := model.contentList.GetChecklist()
+	items := checklist.GetItems()
+	selectedEntryPaths := make([]string, 0)
+	for originalIdx := range checklist.GetSelectedItemOriginalIndices() {
+		selectedEntryPaths = append(selectedEntryPaths, items[originalIdx].GetPath())
+	}
+	sort.Strings(selectedEntryPaths)
+
+	highlightedEntryPath, _ := model.getHighlightedEntryPath()
+	return workspaceState{
+		filterText:           model.filterPane.GetValue(),
+		actionJournal:        model.actionJournal,
+		selectedEntryPaths:   selectedEntryPaths,
+		highlightedEntryPath: highlightedEntryPath,
+	}
+}
+
+func (model Model) getWorkspaceNames() []string {
+	result := make([]string, 0, len(model.workspaces))
+	for _, workspace := range model.workspaces {
+		result = append(result, workspace.Name)
+	}
+	return result
+}
+
+func (model *Model) resizeWorkspaceSwitcher() {
+	width := helpers.GetMinInt(model.width, maxWorkspaceSwitcherWidth)
+	height := helpers.GetMinInt(model.height, len(model.workspaces)+workspaceSwitcherChromeHeight)
+	model.workspaceSwitcher.Resize(width, height)
+}
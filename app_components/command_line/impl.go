@@ -0,0 +1,299 @@
+package command_line
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/components/text_input"
+	"github.com/mieubrisse/cli-journal-go/global_styles"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"strings"
+)
+
+const (
+	prompt = ":"
+
+	maxHistoryLength = 100
+)
+
+var errorStatusStyle = lipgloss.NewStyle().Foreground(global_styles.Red).Bold(true)
+var infoStatusStyle = lipgloss.NewStyle().Faint(true)
+
+type implementation struct {
+	input text_input.Model
+
+	// Oldest first
+	history []string
+
+	// Index into the history of the command being displayed; len(history) means the user's in-progress command
+	historyIdx int
+
+	// What the user had typed before they started going back through the history
+	draft string
+
+	// The state of an in-progress tab completion; nil when the user isn't tab-completing
+	completion *completionState
+
+	statusMessage string
+	isStatusError bool
+
+	isFocused bool
+	width     int
+	height    int
+}
+
+type completionState struct {
+	// The text before the word being completed
+	textBeforeWord string
+
+	candidates []string
+
+	// -1 means the word as the user typed it
+	candidateIdx int
+
+	// The word as the user typed it
+	originalWord string
+}
+
+func New() Component {
+	return &implementation{
+		input:         text_input.New(prompt),
+		history:       []string{},
+		historyIdx:    0,
+		draft:         "",
+		completion:    nil,
+		statusMessage: "",
+		isStatusError: false,
+		isFocused:     false,
+		width:         0,
+		height:        0,
+	}
+}
+
+func (impl *implementation) Update(msg tea.Msg) tea.Cmd {
+	if !impl.isFocused {
+		return nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return impl.input.Update(msg)
+	}
+
+	switch keyMsg.String() {
+	case "up", "ctrl+p":
+		impl.scrollHistory(-1)
+		return nil
+	case "down", "ctrl+n":
+		impl.scrollHistory(1)
+		return nil
+	}
+
+	// Any editing ends the tab completion, so that the next tab completes whatever the user has typed
+	impl.completion = nil
+	return impl.input.Update(msg)
+}
+
+func (impl implementation) View() string {
+	if impl.isFocused {
+		return impl.input.View()
+	}
+
+	style := infoStatusStyle
+	if impl.isStatusError {
+		style = errorStatusStyle
+	}
+	return style.Render(helpers.TruncateToWidth(impl.statusMessage, impl.width))
+}
+
+func (impl implementation) GetValue() string {
+	return impl.input.GetValue()
+}
+
+func (impl *implementation) Clear() {
+	impl.setValue("")
+	impl.historyIdx = len(impl.history)
+	impl.draft = ""
+}
+
+func (impl *implementation) CycleCompletion(getCandidates func(precedingWords []string) []string, offset int) {
+	if impl.completion == nil {
+		value := impl.input.GetValue()
+
+		wordStartIdx := getLastWordStartIdx(value)
+		textBeforeWord := value[:wordStartIdx]
+		originalWord := value[wordStartIdx:]
+
+		precedingWords, err := SplitWords(textBeforeWord)
+		if err != nil {
+			return
+		}
+		partialWord := strings.Trim(originalWord, "\"")
+
+		candidates := make([]string, 0)
+		for _, candidate := range getCandidates(precedingWords) {
+			if strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(partialWord)) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		if len(candidates) == 0 {
+			return
+		}
+
+		impl.completion = &completionState{
+			textBeforeWord: textBeforeWord,
+			candidates:     candidates,
+			candidateIdx:   -1,
+			originalWord:   originalWord,
+		}
+	}
+
+	// Cycle through the candidates plus the original word, so the user can get back to what they typed
+	numChoices := len(impl.completion.candidates) + 1
+	choiceIdx := impl.completion.candidateIdx + 1
+	choiceIdx = ((choiceIdx+offset)%numChoices + numChoices) % numChoices
+	impl.completion.candidateIdx = choiceIdx - 1
+
+	word := impl.completion.originalWord
+	if impl.completion.candidateIdx >= 0 {
+		word = QuoteWord(impl.completion.candidates[impl.completion.candidateIdx])
+	}
+	impl.setValue(impl.completion.textBeforeWord + word)
+}
+
+func (impl *implementation) AddToHistory(commandText string) {
+	commandText = strings.TrimSpace(commandText)
+	if len(commandText) == 0 {
+		return
+	}
+	if len(impl.history) == 0 || impl.history[len(impl.history)-1] != commandText {
+		impl.history = append(impl.history, commandText)
+	}
+	if len(impl.history) > maxHistoryLength {
+		impl.history = impl.history[len(impl.history)-maxHistoryLength:]
+	}
+	impl.historyIdx = len(impl.history)
+}
+
+func (impl *implementation) SetStatus(message string, isError bool) {
+	impl.statusMessage = message
+	impl.isStatusError = isError
+}
+
+func (impl *implementation) ClearStatus() {
+	impl.SetStatus("", false)
+}
+
+func (impl *implementation) Resize(width int, height int) {
+	impl.width = width
+	impl.height = height
+	impl.input.Resize(width, height)
+}
+
+func (impl implementation) GetHeight() int {
+	return impl.height
+}
+
+func (impl implementation) GetWidth() int {
+	return impl.width
+}
+
+func (impl *implementation) Focus() tea.Cmd {
+	impl.isFocused = true
+	return impl.input.Focus()
+}
+
+func (impl *implementation) Blur() tea.Cmd {
+	impl.isFocused = false
+	impl.completion = nil
+	return impl.input.Blur()
+}
+
+func (impl implementation) Focused() bool {
+	return impl.isFocused
+}
+
+// SplitWords splits a command into its words, where double quotes can be used to put spaces inside a word
+func SplitWords(text string) ([]string, error) {
+	words := make([]string, 0)
+	currentWord := strings.Builder{}
+	isInWord := false
+	isInQuotes := false
+	for _, char := range text {
+		switch {
+		case char == '"':
+			isInQuotes = !isInQuotes
+			isInWord = true
+		case (char == ' ' || char == '\t') && !isInQuotes:
+			if isInWord {
+				words = append(words, currentWord.String())
+				currentWord.Reset()
+				isInWord = false
+			}
+		default:
+			currentWord.WriteRune(char)
+			isInWord = true
+		}
+	}
+	if isInQuotes {
+		return nil, fmt.Errorf("unclosed quote in '%s'", text)
+	}
+	if isInWord {
+		words = append(words, currentWord.String())
+	}
+	return words, nil
+}
+
+// QuoteWord quotes the word if it needs it to survive SplitWords as a single word
+func QuoteWord(word string) string {
+	if len(word) == 0 || strings.ContainsAny(word, " \t") {
+		return "\"" + word + "\""
+	}
+	return word
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (impl *implementation) scrollHistory(offset int) {
+	newHistoryIdx := helpers.GetMaxInt(0, helpers.GetMinInt(len(impl.history), impl.historyIdx+offset))
+	if newHistoryIdx == impl.historyIdx {
+		return
+	}
+
+	// Hang onto what the user was typing so they can come back to it
+	if impl.historyIdx == len(impl.history) {
+		impl.draft = impl.input.GetValue()
+	}
+	impl.historyIdx = newHistoryIdx
+
+	if impl.historyIdx == len(impl.history) {
+		impl.setValue(impl.draft)
+	} else {
+		impl.setValue(impl.history[impl.historyIdx])
+	}
+	impl.completion = nil
+}
+
+func (impl *implementation) setValue(value string) {
+	impl.input.SetValue(value)
+	impl.input.CursorEnd()
+}
+
+// getLastWordStartIdx gets the index where the last word of the text starts (which is the end of the text if it ends in
+// whitespace), treating quoted spaces as part of the word like SplitWords does
+func getLastWordStartIdx(text string) int {
+	wordStartIdx := 0
+	isInQuotes := false
+	for idx, char := range text {
+		switch {
+		case char == '"':
+			isInQuotes = !isInQuotes
+		case (char == ' ' || char == '\t') && !isInQuotes:
+			wordStartIdx = idx + 1
+		}
+	}
+	return wordStartIdx
+}
@@ -0,0 +1,48 @@
+package command_line
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitWordsKeepsQuotedSpaces(t *testing.T) {
+	words, err := SplitWords(`jump  "my entry.md"  now`)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	expected := []string{"jump", "my entry.md", "now"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("Expected words %q but got %q", expected, words)
+	}
+
+	if _, err := SplitWords(`jump "my entry`); err == nil {
+		t.Errorf("Expected an error for an unclosed quote")
+	}
+}
+
+func TestCycleCompletionCyclesBackToTypedWord(t *testing.T) {
+	commandLine := New()
+	commandLine.Focus()
+	impl := commandLine.(*implementation)
+	impl.setValue("jump my")
+
+	getCandidates := func(precedingWords []string) []string {
+		if len(precedingWords) == 0 {
+			return []string{"jump"}
+		}
+		return []string{"my entry.md", "mystery.md", "other.md"}
+	}
+
+	expectedValues := []string{`jump "my entry.md"`, "jump mystery.md", "jump my", `jump "my entry.md"`}
+	for _, expectedValue := range expectedValues {
+		commandLine.CycleCompletion(getCandidates, 1)
+		if commandLine.GetValue() != expectedValue {
+			t.Fatalf("Expected value '%s' but got '%s'", expectedValue, commandLine.GetValue())
+		}
+	}
+
+	commandLine.CycleCompletion(getCandidates, -1)
+	if commandLine.GetValue() != "jump my" {
+		t.Errorf("Expected cycling backwards to get back to the typed word, but got '%s'", commandLine.GetValue())
+	}
+}
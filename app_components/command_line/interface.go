@@ -0,0 +1,24 @@
+package command_line
+
+import "github.com/mieubrisse/cli-journal-go/components"
+
+// Component is the vim-style ":" line at the bottom of the screen: while focused the user types a command into it, and
+// while blurred it shows the result of the last command
+type Component interface {
+	components.InteractiveComponent
+
+	GetValue() string
+	Clear()
+
+	// CycleCompletion completes the word under the cursor, cycling through the candidates on repeated calls (backwards if
+	// the offset is negative)
+	// The candidates are gotten by passing the words that come before the one being completed
+	CycleCompletion(getCandidates func(precedingWords []string) []string, offset int)
+
+	// AddToHistory records a submitted command, so the user can get back to it with the up & down keys
+	AddToHistory(commandText string)
+
+	// SetStatus sets the message displayed while the command line isn't focused
+	SetStatus(message string, isError bool)
+	ClearStatus()
+}
@@ -0,0 +1,319 @@
+package entry_item
+
+import (
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ColumnType int
+
+const (
+	CheckmarkColumn ColumnType = iota
+	RelativeTimeColumn
+	TimestampColumn
+	NameColumn
+	TagsColumn
+	WordCountColumn
+	LastModifiedColumn
+	PathColumn
+)
+
+const (
+	// How the timestamp column shows timestamps unless told otherwise
+	DefaultTimestampFormat    = "2006-01-02 15:04:05"
+	defaultLastModifiedFormat = "2006-01-02"
+
+	// Blank cells after each fixed-width column
+	fixedColumnPadding = 2
+
+	// "11 months ago" is the longest relative time
+	relativeTimeWidth = 13 + fixedColumnPadding
+
+	// Enough for "99999 words"
+	wordCountWidth = 11 + fixedColumnPadding
+
+	// Widest the name column grows unless told otherwise
+	DefaultMaxNameWidth = 45
+)
+
+// Names used to refer to the column types (e.g. in config)
+var columnTypeNames = map[ColumnType]string{
+	CheckmarkColumn:    "checkmark",
+	RelativeTimeColumn: "relative-time",
+	TimestampColumn:    "timestamp",
+	NameColumn:         "name",
+	TagsColumn:         "tags",
+	WordCountColumn:    "word-count",
+	LastModifiedColumn: "last-modified",
+	PathColumn:         "path",
+}
+
+// How the columns whose width depends on the space available get sized
+type flexibleColumnSizing struct {
+	// Below this, the column isn't worth showing and so columns start getting dropped
+	minWidth int
+
+	// 0 means no maximum
+	maxWidth int
+
+	// How much of the leftover space the column gets, relative to the other flexible columns
+	weight int
+}
+
+var flexibleColumnSizings = map[ColumnType]flexibleColumnSizing{
+	NameColumn: {minWidth: 20, maxWidth: DefaultMaxNameWidth, weight: 3},
+	TagsColumn: {minWidth: 10, maxWidth: 0, weight: 2},
+	PathColumn: {minWidth: 15, maxWidth: 0, weight: 2},
+}
+
+// Used to get the widest that a formatted time can be: Wednesday & September are the longest day & month names
+var widestReferenceTime = time.Date(2000, time.September, 27, 23, 59, 59, 999999999, time.UTC)
+
+type ColumnSpec struct {
+	Type ColumnType
+
+	// When there isn't room for every column, the lowest-priority columns get dropped first
+	Priority int
+
+	// Go time layout used by the timestamp & last-modified columns; the default is used if empty
+	TimeFormat string
+
+	// Widest that the name, tags & path columns can grow; the column type's default is used if 0
+	MaxWidth int
+}
+
+// Mirrors the layout from before columns were configurable
+var DefaultColumnSpecs = []ColumnSpec{
+	{Type: CheckmarkColumn, Priority: 100, TimeFormat: "", MaxWidth: 0},
+	{Type: TimestampColumn, Priority: 1, TimeFormat: DefaultTimestampFormat, MaxWidth: 0},
+	{Type: NameColumn, Priority: 50, TimeFormat: "", MaxWidth: 0},
+	{Type: TagsColumn, Priority: 10, TimeFormat: "", MaxWidth: 0},
+}
+
+func ParseColumnType(name string) (ColumnType, error) {
+	for columnType, columnTypeName := range columnTypeNames {
+		if columnTypeName == name {
+			return columnType, nil
+		}
+	}
+
+	validNames := make([]string, 0, len(columnTypeNames))
+	for _, columnTypeName := range columnTypeNames {
+		validNames = append(validNames, columnTypeName)
+	}
+	sort.Strings(validNames)
+	return 0, fmt.Errorf("unrecognized column type '%s'; valid column types are: %s", name, strings.Join(validNames, ", "))
+}
+
+func (columnType ColumnType) String() string {
+	return columnTypeNames[columnType]
+}
+
+// ValidateColumnSpecs checks that the columns can be laid out
+func ValidateColumnSpecs(specs []ColumnSpec) error {
+	if len(specs) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+
+	seenTypes := map[ColumnType]bool{}
+	for idx, spec := range specs {
+		if _, found := columnTypeNames[spec.Type]; !found {
+			return fmt.Errorf("column #%d has unrecognized type '%d'", idx+1, spec.Type)
+		}
+		if seenTypes[spec.Type] && spec.Type != TimestampColumn && spec.Type != LastModifiedColumn {
+			return fmt.Errorf("column #%d is a duplicate '%s' column; only time columns can be repeated", idx+1, spec.Type)
+		}
+		seenTypes[spec.Type] = true
+
+		if len(spec.TimeFormat) > 0 && spec.Type != TimestampColumn && spec.Type != LastModifiedColumn {
+			return fmt.Errorf("column #%d is a '%s' column, which doesn't take a time format", idx+1, spec.Type)
+		}
+
+		if spec.MaxWidth != 0 {
+			if err := ValidateMaxWidth(spec.Type, spec.MaxWidth); err != nil {
+				return fmt.Errorf("column #%d is invalid: %w", idx+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateMaxWidth checks that columns of the given type can be capped at the given width
+func ValidateMaxWidth(columnType ColumnType, maxWidth int) error {
+	sizing, isFlexible := flexibleColumnSizings[columnType]
+	if !isFlexible {
+		return fmt.Errorf("'%s' columns don't take a max width", columnType)
+	}
+	if maxWidth < sizing.minWidth {
+		return fmt.Errorf("'%s' columns need a max width of at least %d, but got %d", columnType, sizing.minWidth, maxWidth)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// A column that made it into the layout, with the width it got
+type laidOutColumn struct {
+	spec  ColumnSpec
+	width int
+}
+
+// layOutColumns picks which columns fit in the given width (dropping the lowest-priority ones until the rest fit),
+// and how wide each one is
+func layOutColumns(specs []ColumnSpec, totalWidth int) []laidOutColumn {
+	minWidths := make([]int, len(specs))
+	for idx, spec := range specs {
+		minWidths[idx] = getMinColumnWidth(spec, totalWidth)
+	}
+
+	// Drop columns lowest-priority first (and, amongst equal priorities, right-most first) until everything fits
+	isDropped := make([]bool, len(specs))
+	dropOrder := make([]int, len(specs))
+	for idx := range specs {
+		dropOrder[idx] = idx
+	}
+	sort.SliceStable(dropOrder, func(i, j int) bool {
+		if specs[dropOrder[i]].Priority != specs[dropOrder[j]].Priority {
+			return specs[dropOrder[i]].Priority < specs[dropOrder[j]].Priority
+		}
+		return dropOrder[i] > dropOrder[j]
+	})
+	totalMinWidth := 0
+	for _, minWidth := range minWidths {
+		totalMinWidth += minWidth
+	}
+	for _, idxToDrop := range dropOrder {
+		// Always keep the highest-priority column, even if it doesn't fit
+		if totalMinWidth <= totalWidth || idxToDrop == dropOrder[len(dropOrder)-1] {
+			break
+		}
+		isDropped[idxToDrop] = true
+		totalMinWidth -= minWidths[idxToDrop]
+	}
+
+	result := make([]laidOutColumn, 0, len(specs))
+	for idx, spec := range specs {
+		if isDropped[idx] {
+			continue
+		}
+		result = append(result, laidOutColumn{spec: spec, width: minWidths[idx]})
+	}
+
+	// If even the highest-priority column doesn't fit, squeeze the columns (right-most first) so the row doesn't
+	// overflow
+	overflowWidth := totalMinWidth - totalWidth
+	for idx := len(result) - 1; idx >= 0 && overflowWidth > 0; idx-- {
+		reduction := helpers.GetMinInt(result[idx].width, overflowWidth)
+		result[idx].width -= reduction
+		overflowWidth -= reduction
+	}
+
+	// Share out the leftover space between the flexible columns according to their weights, handing whatever a
+	// column can't take (because of its max width) to the others
+	leftoverWidth := totalWidth - totalMinWidth
+	for leftoverWidth > 0 {
+		totalWeight := 0
+		for _, column := range result {
+			if canColumnGrow(column) {
+				totalWeight += flexibleColumnSizings[column.spec.Type].weight
+			}
+		}
+		if totalWeight == 0 {
+			break
+		}
+
+		widthGiven := 0
+		for idx, column := range result {
+			if !canColumnGrow(column) {
+				continue
+			}
+			sizing := flexibleColumnSizings[column.spec.Type]
+			share := helpers.GetMaxInt(1, leftoverWidth*sizing.weight/totalWeight)
+			share = helpers.GetMinInt(share, leftoverWidth-widthGiven)
+			if maxWidth := getMaxColumnWidth(column.spec); maxWidth > 0 {
+				share = helpers.GetMinInt(share, maxWidth-column.width)
+			}
+			result[idx].width += share
+			widthGiven += share
+		}
+		if widthGiven == 0 {
+			break
+		}
+		leftoverWidth -= widthGiven
+	}
+
+	return result
+}
+
+func getMinColumnWidth(spec ColumnSpec, totalWidth int) int {
+	switch spec.Type {
+	case CheckmarkColumn:
+		return getCheckmarkWidth(totalWidth)
+	case RelativeTimeColumn:
+		return relativeTimeWidth
+	case TimestampColumn, LastModifiedColumn:
+		return helpers.GetDisplayWidth(widestReferenceTime.Format(getTimeFormat(spec))) + fixedColumnPadding
+	case WordCountColumn:
+		return wordCountWidth
+	default:
+		return flexibleColumnSizings[spec.Type].minWidth
+	}
+}
+
+func canColumnGrow(column laidOutColumn) bool {
+	if _, found := flexibleColumnSizings[column.spec.Type]; !found {
+		return false
+	}
+	maxWidth := getMaxColumnWidth(column.spec)
+	return maxWidth == 0 || column.width < maxWidth
+}
+
+// getMaxColumnWidth gets the widest a flexible column can grow, with 0 meaning no maximum
+func getMaxColumnWidth(spec ColumnSpec) int {
+	if spec.MaxWidth > 0 {
+		return spec.MaxWidth
+	}
+	return flexibleColumnSizings[spec.Type].maxWidth
+}
+
+func getTimeFormat(spec ColumnSpec) string {
+	if len(spec.TimeFormat) > 0 {
+		return spec.TimeFormat
+	}
+	if spec.Type == LastModifiedColumn {
+		return defaultLastModifiedFormat
+	}
+	return DefaultTimestampFormat
+}
+
+// getRelativeTimeString describes how long ago the time was, e.g. "3 days ago"
+func getRelativeTimeString(timestamp time.Time, now time.Time) string {
+	elapsed := now.Sub(timestamp)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%d min ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%d hr ago", int(elapsed.Hours()))
+	case elapsed < 30*24*time.Hour:
+		return pluralize(int(elapsed.Hours()/24), "day") + " ago"
+	case elapsed < 365*24*time.Hour:
+		return pluralize(int(elapsed.Hours()/(24*30)), "month") + " ago"
+	default:
+		return pluralize(int(elapsed.Hours()/(24*365)), "year") + " ago"
+	}
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
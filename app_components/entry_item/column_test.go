@@ -0,0 +1,65 @@
+package entry_item
+
+import "testing"
+
+func TestLowPriorityColumnsAreDroppedFirst(t *testing.T) {
+	specs := []ColumnSpec{
+		{Type: CheckmarkColumn, Priority: 100},
+		{Type: RelativeTimeColumn, Priority: 2},
+		{Type: NameColumn, Priority: 50},
+		{Type: TagsColumn, Priority: 10},
+		{Type: WordCountColumn, Priority: 1},
+		{Type: PathColumn, Priority: 5},
+	}
+
+	expectedColumnsByWidth := map[int][]ColumnType{
+		200: {CheckmarkColumn, RelativeTimeColumn, NameColumn, TagsColumn, WordCountColumn, PathColumn},
+		70:  {CheckmarkColumn, RelativeTimeColumn, NameColumn, TagsColumn, PathColumn},
+		50:  {CheckmarkColumn, NameColumn, TagsColumn, PathColumn},
+		40:  {CheckmarkColumn, NameColumn, TagsColumn},
+		25:  {CheckmarkColumn, NameColumn},
+		10:  {CheckmarkColumn},
+	}
+	for width, expectedColumns := range expectedColumnsByWidth {
+		laidOut := layOutColumns(specs, width)
+		if len(laidOut) != len(expectedColumns) {
+			t.Fatalf("Expected %d columns at width %d but got %+v", len(expectedColumns), width, laidOut)
+		}
+
+		totalWidth := 0
+		for idx, column := range laidOut {
+			if column.spec.Type != expectedColumns[idx] {
+				t.Errorf("Expected column #%d at width %d to be '%s' but was '%s'", idx+1, width, expectedColumns[idx], column.spec.Type)
+			}
+			totalWidth += column.width
+		}
+		if totalWidth > width {
+			t.Errorf("Expected columns at width %d to fit, but they took up %d cells", width, totalWidth)
+		}
+	}
+}
+
+func TestNameColumnIsCappedAndTheRestGoesToOtherFlexibleColumns(t *testing.T) {
+	laidOut := layOutColumns([]ColumnSpec{{Type: NameColumn, Priority: 2}, {Type: TagsColumn, Priority: 1}}, 200)
+	if laidOut[0].width != DefaultMaxNameWidth {
+		t.Errorf("Expected the name column to be capped at %d cells but was %d", DefaultMaxNameWidth, laidOut[0].width)
+	}
+	if laidOut[1].width != 200-DefaultMaxNameWidth {
+		t.Errorf("Expected the tags column to get the remaining %d cells but got %d", 200-DefaultMaxNameWidth, laidOut[1].width)
+	}
+}
+
+func TestValidateColumnSpecs(t *testing.T) {
+	if err := ValidateColumnSpecs(DefaultColumnSpecs); err != nil {
+		t.Fatalf("Expected the default columns to be valid but got: %v", err)
+	}
+	if err := ValidateColumnSpecs([]ColumnSpec{}); err == nil {
+		t.Error("Expected an empty column list to be invalid")
+	}
+	if err := ValidateColumnSpecs([]ColumnSpec{{Type: NameColumn}, {Type: NameColumn}}); err == nil {
+		t.Error("Expected duplicate name columns to be invalid")
+	}
+	if err := ValidateColumnSpecs([]ColumnSpec{{Type: TagsColumn, TimeFormat: "2006"}}); err == nil {
+		t.Error("Expected a time format on a non-time column to be invalid")
+	}
+}
@@ -2,6 +2,7 @@ package entry_item
 
 import (
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
 	"github.com/mieubrisse/cli-journal-go/global_styles"
 	"github.com/mieubrisse/cli-journal-go/helpers"
 	"strings"
@@ -11,16 +12,10 @@ import (
 type componentSize int
 
 const (
-	contentTimestampFormat = "2006-01-02 15:04:05"
-
 	checkmarkChar = '•'
 
-	// Used when a line is too small
-	continuationChar = '…'
-
-	maxNameWidth = 45
-
-	minimumNameAndTagWidth = 5
+	// Put in front of the names of encrypted entries
+	encryptedMarker = "🔒 "
 
 	wide componentSize = iota
 	medium
@@ -41,36 +36,56 @@ var desiredCheckmarkWidthsByComponentSize = map[componentSize]int{
 	narrow: 3,
 	sliver: 2,
 }
-var timestampWidthsByComponentSize = map[componentSize]int{
-	wide:   len(contentTimestampFormat) + 4,
-	medium: len(contentTimestampFormat) + 2,
-	narrow: 0,
-	sliver: 0,
-}
 
 type implementation struct {
 	// TODO something about the value
 
-	timestamp time.Time
-	name      string
-	tags      []string // Maybe make this a map??
+	timestamp    time.Time
+	lastModified time.Time
+	name         string
+	tags         []string // Maybe make this a map??
+
+	// First bit of the entry's body, to give an idea of what's inside
+	preview string
+
+	wordCount int
+
+	isEncrypted bool
+
+	// Location of the entry, relative to the journal root
+	path string
+
+	columns []ColumnSpec
 
 	isHighlighted bool
 	isSelected    bool
 
+	// Whether long names & tags wrap onto more lines (rather than being cut off)
+	isWrapping bool
+
+	isShowingPreview bool
+
 	width  int
 	height int
 }
 
-func New(timestamp time.Time, name string, tags []string) Component {
+func New(content content_item.ContentItem) Component {
 	return &implementation{
-		timestamp:     timestamp,
-		name:          name,
-		tags:          tags,
-		isHighlighted: false,
-		isSelected:    false,
-		width:         0,
-		height:        0,
+		timestamp:        content.Timestamp,
+		lastModified:     content.LastModified,
+		name:             content.Name,
+		tags:             content.Tags,
+		preview:          content.Preview,
+		wordCount:        content.WordCount,
+		isEncrypted:      content.IsEncrypted,
+		path:             content.Path,
+		columns:          DefaultColumnSpecs,
+		isHighlighted:    false,
+		isSelected:       false,
+		isWrapping:       true,
+		isShowingPreview: false,
+		width:            0,
+		height:           0,
 	}
 }
 
@@ -87,6 +102,10 @@ func (impl implementation) GetTimestamp() time.Time {
 	return impl.timestamp
 }
 
+func (impl implementation) GetLastModified() time.Time {
+	return impl.lastModified
+}
+
 func (impl implementation) GetName() string {
 	return impl.name
 }
@@ -95,6 +114,10 @@ func (impl implementation) GetTags() []string {
 	return impl.tags
 }
 
+func (impl implementation) GetPath() string {
+	return impl.path
+}
+
 func (impl implementation) GetWidth() int {
 	return impl.width
 }
@@ -103,6 +126,18 @@ func (impl implementation) GetHeight() int {
 	return impl.height
 }
 
+func (impl implementation) GetNumLines() int {
+	columns := make([]renderedColumn, 0, len(impl.columns))
+	for _, laidOut := range layOutColumns(impl.columns, impl.width) {
+		columns = append(columns, impl.renderColumn(laidOut, lipgloss.NewStyle()))
+	}
+	numLines := impl.getRowHeight(columns)
+	if impl.hasPreviewLine() {
+		numLines++
+	}
+	return numLines
+}
+
 func (impl implementation) GetValue() string {
 	panic("Implement me!")
 }
@@ -123,108 +158,201 @@ func (impl implementation) IsHighlighted() bool {
 	return impl.isHighlighted
 }
 
+func (impl implementation) GetCheckmarkWidth() int {
+	laidOutColumns := layOutColumns(impl.columns, impl.width)
+	if len(laidOutColumns) == 0 || laidOutColumns[0].spec.Type != CheckmarkColumn {
+		return 0
+	}
+	return laidOutColumns[0].width
+}
+
+func (impl *implementation) SetContent(content content_item.ContentItem) {
+	impl.timestamp = content.Timestamp
+	impl.lastModified = content.LastModified
+	impl.name = content.Name
+	impl.tags = content.Tags
+	impl.preview = content.Preview
+	impl.wordCount = content.WordCount
+	impl.isEncrypted = content.IsEncrypted
+	impl.path = content.Path
+}
+
+func (impl *implementation) SetColumns(columns []ColumnSpec) {
+	impl.columns = columns
+}
+
+func (impl *implementation) SetWrapping(isWrapping bool) {
+	impl.isWrapping = isWrapping
+}
+
+func (impl *implementation) SetShowingPreview(isShowingPreview bool) {
+	impl.isShowingPreview = isShowingPreview
+}
+
 // ====================================================================================================
 //
 //	Private Helper Functions
 //
 // ====================================================================================================
-// TODO allow this to do wrapping
 func (impl implementation) render() string {
 	baseLineStyle := lipgloss.NewStyle()
 	if impl.isHighlighted {
 		baseLineStyle = baseLineStyle.Background(global_styles.FocusedComponentBackgroundColor).Bold(true)
 	}
 
-	// Calculate the widths for the various components
-	biggestThresholdPassed := sliver
-	for trialComponentSize, threshold := range componentSizeThresholds {
-		if impl.width > threshold && threshold > componentSizeThresholds[biggestThresholdPassed] {
-			biggestThresholdPassed = trialComponentSize
+	// We lay the columns out ourselves rather than letting lipgloss do it, because lipgloss measures width by rune
+	// rather than by grapheme cluster and so gets emoji sequences wrong
+	columns := make([]renderedColumn, 0, len(impl.columns))
+	previewIndent := 0
+	hasReachedNameColumn := false
+	for _, laidOut := range layOutColumns(impl.columns, impl.width) {
+		if laidOut.spec.Type == NameColumn {
+			hasReachedNameColumn = true
+		}
+		if !hasReachedNameColumn {
+			previewIndent += laidOut.width
 		}
+
+		columns = append(columns, impl.renderColumn(laidOut, baseLineStyle))
 	}
-	actualComponentSize := biggestThresholdPassed
-	desiredCheckmarkWidth, found := desiredCheckmarkWidthsByComponentSize[actualComponentSize]
-	if !found {
-		panic("No checkmark width for terminal size")
+
+	hasPreviewLine := impl.hasPreviewLine()
+	rowHeight := impl.getRowHeight(columns)
+
+	resultLines := make([]string, 0, rowHeight+1)
+	for lineIdx := 0; lineIdx < rowHeight; lineIdx++ {
+		line := ""
+		usedWidth := 0
+		for _, column := range columns {
+			columnLine := ""
+			if lineIdx < len(column.lines) {
+				columnLine = column.lines[lineIdx]
+			}
+			line += column.style.Render(helpers.PadToWidth(columnLine, column.width))
+			usedWidth += column.width
+		}
+
+		// Fill out the rest of the row so the highlight covers all of it
+		line += baseLineStyle.Render(strings.Repeat(" ", helpers.GetMaxInt(0, impl.width-usedWidth)))
+		resultLines = append(resultLines, line)
 	}
-	timestampWidth, found := timestampWidthsByComponentSize[actualComponentSize]
-	if !found {
-		panic("No timestamp width for terminal size")
+
+	if hasPreviewLine {
+		// The preview sits underneath the name, so the columns before it stay clear
+		previewIndent = helpers.GetMinInt(impl.width, previewIndent)
+		previewStr := helpers.TruncateToWidth(impl.preview, impl.width-previewIndent)
+		previewLine := baseLineStyle.Render(strings.Repeat(" ", previewIndent)) +
+			baseLineStyle.Copy().Faint(true).Render(helpers.PadToWidth(previewStr, impl.width-previewIndent))
+		resultLines = append(resultLines, previewLine)
 	}
 
-	widthRemaining := helpers.GetMaxInt(0, impl.width-desiredCheckmarkWidth-timestampWidth)
-	// Safety valve: if we don't have at least 10 characters, don't even bother
+	return strings.Join(resultLines, "\n")
+}
 
-	nameWidth := helpers.GetMinInt(
-		maxNameWidth,
-		int(0.6*float64(widthRemaining)),
-	)
-	tagsWidth := helpers.GetMaxInt(0, widthRemaining-nameWidth)
+func (impl implementation) hasPreviewLine() bool {
+	return impl.isShowingPreview && len(impl.preview) > 0
+}
 
-	// Checkmark string
-	checkmarkStr := ""
-	if impl.isSelected {
-		checkmarkStr = string(checkmarkChar)
+// getRowHeight gets how many lines the columns take up (not counting the preview line), which is as many as the
+// tallest column needs but no more than fit
+func (impl implementation) getRowHeight(columns []renderedColumn) int {
+	rowHeight := 1
+	for _, column := range columns {
+		rowHeight = helpers.GetMaxInt(rowHeight, len(column.lines))
+	}
+	if impl.height > 0 {
+		// Leave room for the preview line, if there is one
+		availableHeight := impl.height
+		if impl.hasPreviewLine() {
+			availableHeight--
+		}
+		rowHeight = helpers.GetMaxInt(1, helpers.GetMinInt(rowHeight, availableHeight))
 	}
-	checkmarkStr = baseLineStyle.Copy().
-		Foreground(global_styles.Orange).
-		Width(desiredCheckmarkWidth).
-		AlignHorizontal(lipgloss.Center).
-		Render(checkmarkStr)
-
-	// Timestamp (disabled if too small)
-	timestampStr := ""
-	if timestampWidth > 0 {
-		timestampStr = impl.timestamp.Format(contentTimestampFormat)
-		timestampStr = baseLineStyle.Copy().
-			Foreground(global_styles.Cyan).
-			Width(timestampWidth).
-			AlignHorizontal(lipgloss.Left).
-			Render(timestampStr)
+	return rowHeight
+}
+
+// renderColumn gets the lines of text for a column
+// Columns whose width depends on the space available leave a cell of padding on their right so they don't run into
+// the next column, and wrap onto more lines when wrapping is on
+func (impl implementation) renderColumn(laidOut laidOutColumn, baseLineStyle lipgloss.Style) renderedColumn {
+	result := renderedColumn{
+		lines: []string{},
+		width: laidOut.width,
+		style: baseLineStyle,
 	}
 
-	// Name
-	nameStr := ""
-	if nameWidth > minimumNameAndTagWidth {
-		nameStr = impl.name
-		nameLen := len(nameStr)
-		if nameLen > nameWidth-1 {
-			nameStr = nameStr[:nameWidth-2] + string(continuationChar)
+	textWidth := laidOut.width - 1
+	switch laidOut.spec.Type {
+	case CheckmarkColumn:
+		checkmarkStr := ""
+		if impl.isSelected {
+			checkmarkStr = string(checkmarkChar)
+		}
+		checkmarkPad := helpers.GetMaxInt(0, (laidOut.width-helpers.GetDisplayWidth(checkmarkStr))/2)
+		result.lines = []string{strings.Repeat(" ", checkmarkPad) + checkmarkStr}
+		result.style = baseLineStyle.Copy().Foreground(global_styles.Orange)
+	case RelativeTimeColumn:
+		result.lines = []string{getRelativeTimeString(impl.timestamp, time.Now())}
+		result.style = baseLineStyle.Copy().Foreground(global_styles.Cyan)
+	case TimestampColumn:
+		result.lines = []string{impl.timestamp.Format(getTimeFormat(laidOut.spec))}
+		result.style = baseLineStyle.Copy().Foreground(global_styles.Cyan)
+	case LastModifiedColumn:
+		result.lines = []string{impl.lastModified.Format(getTimeFormat(laidOut.spec))}
+		result.style = baseLineStyle.Copy().Foreground(global_styles.Peach)
+	case WordCountColumn:
+		result.lines = []string{pluralize(impl.wordCount, "word")}
+		result.style = baseLineStyle.Copy().Faint(true)
+	case NameColumn:
+		if !impl.isEncrypted {
+			result.lines = impl.wrapOrTruncate(impl.name, textWidth)
+		} else {
+			// The marker hangs in front of the name, so that wrapped lines stay lined up after it
+			markerWidth := helpers.GetDisplayWidth(encryptedMarker)
+			result.lines = []string{encryptedMarker}
+			for idx, line := range impl.wrapOrTruncate(impl.name, textWidth-markerWidth) {
+				if idx == 0 {
+					result.lines[0] += line
+					continue
+				}
+				result.lines = append(result.lines, strings.Repeat(" ", markerWidth)+line)
+			}
 		}
-		nameStr = baseLineStyle.Copy().
-			Foreground(global_styles.White).
-			Width(nameWidth).
-			AlignHorizontal(lipgloss.Left).
-			Render(nameStr)
+		result.style = baseLineStyle.Copy().Foreground(global_styles.White)
+	case TagsColumn:
+		result.lines = impl.wrapOrTruncate(strings.Join(impl.tags, " "), textWidth)
+		result.style = baseLineStyle.Copy().Foreground(global_styles.Red)
+	case PathColumn:
+		result.lines = impl.wrapOrTruncate(impl.path, textWidth)
+		result.style = baseLineStyle.Copy().Faint(true)
 	}
+	return result
+}
+
+func (impl implementation) wrapOrTruncate(text string, width int) []string {
+	if impl.isWrapping {
+		return helpers.WrapToWidth(text, width)
+	}
+	return []string{helpers.TruncateToWidth(text, width)}
+}
 
-	tagsStr := ""
-	if tagsWidth > minimumNameAndTagWidth {
-		tagsStr = strings.Join(impl.tags, " ")
-		tagsLen := len(tagsStr)
-		if tagsLen > tagsWidth-1 {
-			tagsStr = tagsStr[:tagsWidth-2] + string(continuationChar)
+func getCheckmarkWidth(totalWidth int) int {
+	biggestThresholdPassed := sliver
+	for trialComponentSize, threshold := range componentSizeThresholds {
+		if totalWidth > threshold && threshold > componentSizeThresholds[biggestThresholdPassed] {
+			biggestThresholdPassed = trialComponentSize
 		}
-		tagsStr = baseLineStyle.Copy().
-			Foreground(global_styles.Red).
-			Width(tagsWidth).
-			AlignHorizontal(lipgloss.Left).
-			Render(tagsStr)
 	}
+	desiredCheckmarkWidth, found := desiredCheckmarkWidthsByComponentSize[biggestThresholdPassed]
+	if !found {
+		panic("No checkmark width for terminal size")
+	}
+	return desiredCheckmarkWidth
+}
 
-	return lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		checkmarkStr,
-		timestampStr,
-		nameStr,
-		tagsStr,
-	)
-
-	/*
-		return baseLineStyle.Copy().
-			Width(impl.width).
-			MaxWidth(impl.width).
-			Render(line)
-
-	*/
+// A column of a row, whose lines will be padded out to the column's width
+type renderedColumn struct {
+	lines []string
+	width int
+	style lipgloss.Style
 }
@@ -0,0 +1,140 @@
+package entry_item
+
+import (
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"github.com/rivo/uniseg"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+var nonAsciiEntries = []content_item.ContentItem{
+	{
+		Name: "日記と思い出のまとめ-二〇二三年四月の振り返りと来月の計画について.md",
+		Tags: []string{"個人/日記", "project-support/wealthdraft"},
+	},
+	{
+		Name: "👩‍💻👩‍💻👩‍💻 pairing session notes with the whole team 🎉🎉🎉🎉🎉🎉🎉.md",
+		Tags: []string{"work/🚀-launches"},
+	},
+	{
+		Name: "café-résumé-brainstorming-for-the-négociation-with-the-landlord.md",
+		Tags: []string{"general-reference/fréquences"},
+	},
+	{
+		Name: "한국어 메모 - 회의록과 다음 단계에 대한 정리 그리고 추가적인 생각들.md",
+		Tags: []string{"회의/팀", "Ελληνικά/σημειώσεις"},
+	},
+}
+
+func TestRenderedRowsAreExactlyAsWideAsTheComponent(t *testing.T) {
+	for _, width := range []int{40, 90, 130, 160} {
+		for _, isWrapping := range []bool{true, false} {
+			for _, content := range nonAsciiEntries {
+				content.Timestamp = time.Date(2023, 4, 17, 9, 30, 0, 0, time.UTC)
+				content.Preview = "最初の文はここにあります、そしてとても長いので切り詰める必要があります。"
+
+				item := New(content)
+				item.Resize(width, 10)
+				item.SetWrapping(isWrapping)
+				item.SetShowingPreview(true)
+				rendered := helpers.ClearFormatting(item.View())
+
+				if !utf8.ValidString(rendered) {
+					t.Fatalf("Rendering '%s' at width %d produced invalid UTF-8", content.Name, width)
+				}
+				for _, line := range strings.Split(rendered, "\n") {
+					if lineWidth := helpers.GetDisplayWidth(line); lineWidth != width {
+						t.Errorf(
+							"Expected every line of '%s' rendered at width %d (wrapping: %v) to be %d cells wide, but line '%s' was %d cells",
+							content.Name,
+							width,
+							isWrapping,
+							width,
+							line,
+							lineWidth,
+						)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestTruncatedNamesEndWithEllipsis(t *testing.T) {
+	for _, content := range nonAsciiEntries {
+		item := New(content)
+		item.Resize(130, 1)
+		item.SetWrapping(false)
+		rendered := helpers.ClearFormatting(item.View())
+
+		if strings.Contains(rendered, "\n") {
+			t.Errorf("Expected '%s' to render on a single line when not wrapping, but got:\n%s", content.Name, rendered)
+		}
+		if !strings.Contains(rendered, "…") {
+			t.Errorf("Expected '%s' to be cut off with an ellipsis, but got: %s", content.Name, rendered)
+		}
+	}
+}
+
+func TestWrappedNamesAreNotCutOff(t *testing.T) {
+	for _, content := range nonAsciiEntries {
+		item := New(content)
+		item.Resize(130, 10)
+		item.SetWrapping(true)
+		rendered := helpers.ClearFormatting(item.View())
+
+		// Join the name column back together to check that nothing went missing
+		nameStart, nameEnd := 0, 0
+		for _, column := range layOutColumns(DefaultColumnSpecs, 130) {
+			if column.spec.Type == NameColumn {
+				nameEnd = nameStart + column.width
+				break
+			}
+			nameStart += column.width
+		}
+		nameChunks := make([]string, 0)
+		for _, line := range strings.Split(rendered, "\n") {
+			nameChunks = append(nameChunks, strings.TrimSpace(cutCells(line, nameStart, nameEnd)))
+		}
+		rejoined := strings.Join(nameChunks, "")
+		if strings.ReplaceAll(rejoined, " ", "") != strings.ReplaceAll(content.Name, " ", "") {
+			t.Errorf("Expected wrapped name to contain all of '%s', but got '%s'", content.Name, rejoined)
+		}
+	}
+}
+
+func TestEncryptedEntriesShowALockMarker(t *testing.T) {
+	item := New(content_item.ContentItem{
+		Timestamp:   time.Date(2023, 4, 17, 9, 30, 0, 0, time.UTC),
+		Name:        "1-on-1-with-the-new-manager-about-the-reorganization-and-goals.md",
+		Tags:        []string{"work/one-on-ones"},
+		IsEncrypted: true,
+	})
+	item.Resize(90, 10)
+	for _, line := range strings.Split(helpers.ClearFormatting(item.View()), "\n") {
+		if width := helpers.GetDisplayWidth(line); width != 90 {
+			t.Errorf("Expected every line to be 90 cells wide, but got %d for line '%s'", width, line)
+		}
+	}
+	if rendered := helpers.ClearFormatting(item.View()); !strings.Contains(rendered, encryptedMarker+"1-on-1") {
+		t.Errorf("Expected the name to have the lock marker in front of it, but got:\n%s", rendered)
+	}
+}
+
+// Gets the part of the line between the given display cells
+func cutCells(line string, startCell int, endCell int) string {
+	result := strings.Builder{}
+	cell := 0
+	graphemes := uniseg.NewGraphemes(line)
+	for graphemes.Next() {
+		cluster := graphemes.Str()
+		if cell >= startCell && cell < endCell {
+			result.WriteString(cluster)
+		}
+		cell += helpers.GetDisplayWidth(cluster)
+	}
+	return result.String()
+}
@@ -2,6 +2,7 @@ package entry_item
 
 import (
 	"github.com/mieubrisse/cli-journal-go/components/filterable_checklist_item"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
 	"time"
 )
 
@@ -9,6 +10,22 @@ type Component interface {
 	filterable_checklist_item.Component
 
 	GetTimestamp() time.Time
+	GetLastModified() time.Time
 	GetName() string
 	GetTags() []string
+
+	// GetPath gets the location of the entry, relative to the journal root
+	GetPath() string
+
+	// SetContent replaces what the item displays (e.g. after the entry was renamed), keeping its display state
+	SetContent(content content_item.ContentItem)
+
+	// SetColumns sets which columns the item displays
+	SetColumns(columns []ColumnSpec)
+
+	// SetWrapping sets whether long names & tags wrap onto more lines, making the item taller
+	SetWrapping(isWrapping bool)
+
+	// SetShowingPreview sets whether a line with the start of the entry's body is shown under the name
+	SetShowingPreview(isShowingPreview bool)
 }
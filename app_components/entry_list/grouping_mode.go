@@ -0,0 +1,127 @@
+package entry_list
+
+import (
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/app_components/entry_item"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"strings"
+	"time"
+)
+
+type GroupingMode int
+
+const (
+	NoGrouping GroupingMode = iota
+	Day
+	Week
+	Month
+	Tag
+)
+
+const (
+	groupKeyDateFormat  = "2006-01-02"
+	dayHeaderDateFormat = "Monday 2006-01-02"
+	monthHeaderFormat   = "January 2006"
+)
+
+// Order in which the grouping modes are cycled through
+var allGroupingModes = []GroupingMode{
+	NoGrouping,
+	Day,
+	Week,
+	Month,
+	Tag,
+}
+
+// Names used to refer to the grouping modes (e.g. in config)
+var groupingModeNames = map[GroupingMode]string{
+	NoGrouping: "none",
+	Day:        "day",
+	Week:       "week",
+	Month:      "month",
+	Tag:        "tag",
+}
+
+func ParseGroupingMode(name string) (GroupingMode, error) {
+	for _, mode := range allGroupingModes {
+		if groupingModeNames[mode] == name {
+			return mode, nil
+		}
+	}
+
+	validNames := make([]string, 0, len(allGroupingModes))
+	for _, mode := range allGroupingModes {
+		validNames = append(validNames, groupingModeNames[mode])
+	}
+	return 0, fmt.Errorf("unrecognized grouping mode '%s'; valid grouping modes are: %s", name, strings.Join(validNames, ", "))
+}
+
+// GetAllGroupingModes gets the grouping modes in the order they're cycled through
+func GetAllGroupingModes() []GroupingMode {
+	return append([]GroupingMode{}, allGroupingModes...)
+}
+
+func (mode GroupingMode) String() string {
+	return groupingModeNames[mode]
+}
+
+// Next gets the grouping mode that comes after this one when cycling (wrapping around), or the one before if the
+// offset is negative
+func (mode GroupingMode) Next(offset int) GroupingMode {
+	currentIdx := 0
+	for idx, trialMode := range allGroupingModes {
+		if trialMode == mode {
+			currentIdx = idx
+			break
+		}
+	}
+
+	numModes := len(allGroupingModes)
+	nextIdx := ((currentIdx+offset)%numModes + numModes) % numModes
+	return allGroupingModes[nextIdx]
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getGrouper gets the function that puts items into groups, returning the group key and the group's header text
+// A nil grouper means no grouping
+func (mode GroupingMode) getGrouper() func(item entry_item.Component) (string, string) {
+	switch mode {
+	case Day:
+		return func(item entry_item.Component) (string, string) {
+			timestamp := item.GetTimestamp()
+			return timestamp.Format(groupKeyDateFormat), timestamp.Format(dayHeaderDateFormat)
+		}
+	case Week:
+		return func(item entry_item.Component) (string, string) {
+			weekStart := getStartOfWeek(item.GetTimestamp())
+			return weekStart.Format(groupKeyDateFormat), "Week of " + weekStart.Format(groupKeyDateFormat)
+		}
+	case Month:
+		return func(item entry_item.Component) (string, string) {
+			timestamp := item.GetTimestamp()
+			return timestamp.Format("2006-01"), timestamp.Format(monthHeaderFormat)
+		}
+	case Tag:
+		// Items with multiple tags get grouped under their first one
+		return func(item entry_item.Component) (string, string) {
+			tag := ""
+			if tags := item.GetTags(); len(tags) > 0 {
+				tag = tags[0]
+			}
+			return tag, journal_store.DescribeTag(tag)
+		}
+	default:
+		return nil
+	}
+}
+
+// Weeks start on Monday
+func getStartOfWeek(timestamp time.Time) time.Time {
+	daysSinceMonday := (int(timestamp.Weekday()) + 6) % 7
+	year, month, day := timestamp.Date()
+	return time.Date(year, month, day-daysSinceMonday, 0, 0, 0, 0, timestamp.Location())
+}
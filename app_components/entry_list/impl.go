@@ -6,45 +6,97 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mieubrisse/cli-journal-go/app_components/entry_item"
 	"github.com/mieubrisse/cli-journal-go/components/filterable_checklist"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/entry_filter"
 	"github.com/mieubrisse/cli-journal-go/global_styles"
-	"regexp"
+	"github.com/mieubrisse/cli-journal-go/helpers"
 	"strings"
 )
 
+const (
+	footerSectionSeparator = "    "
+)
+
 // This
 type Model struct {
 	checklist filterable_checklist.Component[entry_item.Component]
 
 	items []entry_item.Component
 
+	sortMode SortMode
+
+	groupingMode GroupingMode
+
+	isWrapping bool
+
+	isShowingPreviews bool
+
+	columns []entry_item.ColumnSpec
+
+	// The filters last given to SetFilters, so they can be re-applied when the content changes
+	nameFilterLines []string
+	tagFilterLines  []string
+
+	// How well each item matched the name filters (lower is better), for sorting by match score
+	matchScores map[entry_item.Component]int
+
 	// Whether to highlight the cursor line or not
 	isFocused bool
 
+	// Shown at the end of the footer (e.g. the journal's git status), each in its own section
+	footerIndicators []string
+
 	height int
 	width  int
 }
 
 // TODO replace content with contentProvider
-func New(content []entry_item.Component) Model {
+func New(
+	content []entry_item.Component,
+	sortMode SortMode,
+	groupingMode GroupingMode,
+	columns []entry_item.ColumnSpec,
+) Model {
 	checklist := filterable_checklist.New[entry_item.Component]()
-	checklist.SetItems(content)
 
-	return Model{
-		checklist: checklist,
-		items:     content,
-		isFocused: false,
-		height:    0,
-		width:     0,
+	model := Model{
+		checklist:         checklist,
+		items:             content,
+		sortMode:          sortMode,
+		groupingMode:      groupingMode,
+		isWrapping:        true,
+		isShowingPreviews: false,
+		columns:           columns,
+		nameFilterLines:   []string{},
+		tagFilterLines:    []string{},
+		matchScores:       map[entry_item.Component]int{},
+		isFocused:         false,
+		footerIndicators:  []string{},
+		height:            0,
+		width:             0,
 	}
+	model.SetSortMode(sortMode)
+	model.SetGroupingMode(groupingMode)
+
+	// Set the items after the ordering so that the first item in display order starts highlighted
+	checklist.SetItems(content)
+	model.SetWrapping(model.isWrapping)
+	model.SetShowingPreviews(model.isShowingPreviews)
+	for _, item := range content {
+		item.SetColumns(columns)
+	}
+	return model
 }
 
 func (model Model) Init() tea.Cmd {
 	return nil
 }
 
-func (model Model) Update(msg tea.Msg) tea.Cmd {
-	// Do nothing on non-Keymsgs
+func (model *Model) Update(msg tea.Msg) tea.Cmd {
+	// Mouse events get routed to us by position, so we handle them whether or not we're focused
 	switch msg.(type) {
+	case tea.MouseMsg:
+		return model.checklist.Update(msg)
 	case tea.KeyMsg:
 		// Proceed to rest of function
 	default:
@@ -60,7 +112,12 @@ func (model Model) Update(msg tea.Msg) tea.Cmd {
 
 func (model Model) View() string {
 	// First calculate the footer, so we can get its height later
-	footerStr := ""
+	footerSections := make([]string, 0)
+	if model.checklist.IsInVisualMode() {
+		visualStr := lipgloss.NewStyle().Foreground(global_styles.Orange).Bold(true).Render("VISUAL") +
+			lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf(" %d in range", len(model.checklist.GetVisualRangeOriginalIndices())))
+		footerSections = append(footerSections, visualStr)
+	}
 	numSelectedItems := len(model.checklist.GetSelectedItemOriginalIndices())
 	if numSelectedItems > 0 {
 		numberStr := fmt.Sprintf("%d", numSelectedItems)
@@ -68,8 +125,22 @@ func (model Model) View() string {
 
 		textStr := lipgloss.NewStyle().Foreground(global_styles.White).Render(" items selected")
 
-		footerStr = numberStr + textStr
+		footerSections = append(footerSections, numberStr+textStr)
+	}
+	sortStr := lipgloss.NewStyle().Faint(true).Render("sorted by ") +
+		lipgloss.NewStyle().Foreground(global_styles.Cyan).Render(model.sortMode.GetLabel())
+	footerSections = append(footerSections, sortStr)
+	if model.groupingMode != NoGrouping {
+		groupingStr := lipgloss.NewStyle().Faint(true).Render("grouped by ") +
+			lipgloss.NewStyle().Foreground(global_styles.Cyan).Render(model.groupingMode.String())
+		footerSections = append(footerSections, groupingStr)
 	}
+	for _, indicator := range model.footerIndicators {
+		if len(indicator) > 0 {
+			footerSections = append(footerSections, indicator)
+		}
+	}
+	footerStr := strings.Join(footerSections, footerSectionSeparator)
 	style := lipgloss.NewStyle().
 		Width(model.width).
 		MaxWidth(model.width).
@@ -77,22 +148,16 @@ func (model Model) View() string {
 	finalFooter := style.Render(footerStr)
 
 	// Calculate content
-	var content string
-	if len(model.checklist.GetFilterableList().GetFilteredItemIndices()) == 0 {
+	// When every group is folded no items are displayed, but the group headers still are
+	content := model.checklist.View()
+	if len(content) == 0 {
 		content = lipgloss.NewStyle().
 			Width(model.width).
 			Faint(true).
 			Align(lipgloss.Center).
 			Render("No items")
-	} else {
-		content = model.checklist.View()
 	}
-	finalContent := lipgloss.NewStyle().
-		Width(model.width).
-		MaxWidth(model.width).
-		Height(model.height).
-		MaxHeight(model.height).
-		Render(content)
+	finalContent := helpers.FitToSize(content, model.width, model.height)
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -101,46 +166,131 @@ func (model Model) View() string {
 	)
 }
 
+// SetFooterIndicators sets the already-styled texts shown at the end of the footer; empty ones are hidden
+func (model *Model) SetFooterIndicators(indicators ...string) {
+	model.footerIndicators = indicators
+}
+
 func (model *Model) SetFilters(nameFilterLines []string, tagFilterLines []string) {
-	nameFilterRegexes := transformTermsToFilterRegexes(nameFilterLines)
-	tagFilterRegexes := transformTermsToFilterRegexes(tagFilterLines)
+	model.nameFilterLines = nameFilterLines
+	model.tagFilterLines = tagFilterLines
 
-	// The way this predicate is structured is as a "gauntlet" - there are many opportunities for an item to be discarded,
-	// and only if it passes those will it be in
-	// I believe this to be the best way to structure predicates, because it makes it easier to think about
+	filter := entry_filter.New(nameFilterLines, tagFilterLines)
+
+	matchScores := make(map[entry_item.Component]int, len(model.items))
 	predicate := func(_ int, item entry_item.Component) bool {
-		// Filter out non-matching names
-		for _, nameRegex := range nameFilterRegexes {
-			if !nameRegex.MatchString(item.GetName()) {
-				return false
-			}
+		matchScore, isMatch := filter.Match(item.GetName(), item.GetTags())
+		if !isMatch {
+			return false
 		}
+		matchScores[item] = matchScore
+		return true
+	}
 
-		// If no tag filters are specified, skip this step of the gauntlet
-		if len(tagFilterRegexes) > 0 {
-			// If we have tag filters, we run a sub-gauntlet for tags, where at least one tag must make it through
-			hasTagMatch := false
-		tagLoop:
-			for _, tag := range item.GetTags() {
-				for _, tagRegex := range tagFilterRegexes {
-					if !tagRegex.MatchString(tag) {
-						continue tagLoop
-					}
-				}
-
-				// A tag made it through the gauntlet; no need to process further tags
-				hasTagMatch = true
-				break
-			}
-			if !hasTagMatch {
-				return false
-			}
+	model.checklist.GetFilterableList().UpdateFilter(predicate)
+
+	model.matchScores = matchScores
+	if model.sortMode == MatchScore {
+		model.checklist.GetFilterableList().SetOrdering(model.sortMode.getLessFunc(model.matchScores))
+	}
+}
+
+// Used for manipulations of the underlying checklist (e.g. moving the highlight, or selecting items)
+func (model Model) GetChecklist() filterable_checklist.Component[entry_item.Component] {
+	return model.checklist
+}
+
+func (model Model) GetSortMode() SortMode {
+	return model.sortMode
+}
+
+// SetSortMode reorders the list according to the given sort mode, keeping the highlighted item highlighted
+func (model *Model) SetSortMode(sortMode SortMode) {
+	model.sortMode = sortMode
+	model.checklist.GetFilterableList().SetOrdering(sortMode.getLessFunc(model.matchScores))
+}
+
+func (model Model) GetGroupingMode() GroupingMode {
+	return model.groupingMode
+}
+
+// SetGroupingMode gathers the items into groups with headers, unfolding any folded groups
+func (model *Model) SetGroupingMode(groupingMode GroupingMode) {
+	model.groupingMode = groupingMode
+	model.checklist.GetFilterableList().SetGrouping(groupingMode.getGrouper())
+}
+
+// SetContent replaces the entries in the list (e.g. after they've changed on disk), keeping the same entries
+// highlighted & selected where they still exist
+func (model *Model) SetContent(content []entry_item.Component) {
+	filterableList := model.checklist.GetFilterableList()
+	highlightedPath := ""
+	if highlightedFilteredIdx, found := filterableList.GetHighlightedItemIndex(); found {
+		highlightedPath = model.items[filterableList.GetFilteredItemIndices()[highlightedFilteredIdx]].GetPath()
+	}
+	selectedPaths := make(map[string]bool, 0)
+	for originalIdx := range model.checklist.GetSelectedItemOriginalIndices() {
+		selectedPaths[model.items[originalIdx].GetPath()] = true
+	}
+
+	for _, item := range content {
+		item.SetColumns(model.columns)
+		item.SetWrapping(model.isWrapping)
+		item.SetShowingPreview(model.isShowingPreviews)
+	}
+	model.items = content
+	model.checklist.SetItems(content)
+	for originalIdx, item := range content {
+		if selectedPaths[item.GetPath()] {
+			model.checklist.SetItemSelection(originalIdx, true)
 		}
+	}
+	model.SetFilters(model.nameFilterLines, model.tagFilterLines)
 
-		return true
+	for filteredIdx, originalIdx := range filterableList.GetFilteredItemIndices() {
+		if content[originalIdx].GetPath() == highlightedPath {
+			filterableList.HighlightItem(filteredIdx)
+			break
+		}
 	}
+}
 
-	model.checklist.GetFilterableList().UpdateFilter(predicate)
+// UpdateItem changes what the entry at the given path displays without replacing it, so it stays highlighted &
+// selected; the list gets re-sorted & re-filtered in case the change affects that
+func (model *Model) UpdateItem(entryPath string, content content_item.ContentItem) error {
+	for _, item := range model.items {
+		if item.GetPath() != entryPath {
+			continue
+		}
+		item.SetContent(content)
+
+		// Re-filtering also re-sorts & re-groups
+		model.SetFilters(model.nameFilterLines, model.tagFilterLines)
+		return nil
+	}
+	return fmt.Errorf("no entry in the list has path '%s'", entryPath)
+}
+
+func (model Model) IsWrapping() bool {
+	return model.isWrapping
+}
+
+func (model *Model) SetWrapping(isWrapping bool) {
+	model.isWrapping = isWrapping
+	for _, item := range model.items {
+		item.SetWrapping(isWrapping)
+	}
+}
+
+func (model Model) IsShowingPreviews() bool {
+	return model.isShowingPreviews
+}
+
+func (model *Model) SetShowingPreviews(isShowingPreviews bool) {
+	model.isShowingPreviews = isShowingPreviews
+	for _, item := range model.items {
+		item.SetShowingPreview(isShowingPreviews)
+	}
 }
 
 /*
@@ -220,30 +370,3 @@ func (model Model) recalculateView() Model {
 	return model
 }
 */
-
-// Each line that has text, will produce a regex filter that must match
-func transformTermsToFilterRegexes(lines []string) []*regexp.Regexp {
-	result := make([]*regexp.Regexp, 0)
-	for _, line := range lines {
-		terms := strings.Fields(line)
-
-		// Don't bother creating a regex for an empty line
-		if len(terms) == 0 {
-			continue
-		}
-
-		// We have terms, so we need to escape them
-		escapedTerms := make([]string, 0, len(terms))
-		for _, term := range terms {
-			escapedTerms = append(escapedTerms, regexp.QuoteMeta(term))
-		}
-
-		// The (?i) makes the search case-insensitive
-		regexStr := "(?i)" + strings.Join(escapedTerms, ".*")
-
-		// Okay to use MustCompile here because we quote the user's input so it should be safe
-		result = append(result, regexp.MustCompile(regexStr))
-	}
-
-	return result
-}
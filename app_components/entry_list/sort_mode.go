@@ -0,0 +1,148 @@
+package entry_list
+
+import (
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/app_components/entry_item"
+	"strings"
+)
+
+type SortMode int
+
+const (
+	TimestampDescending SortMode = iota
+	TimestampAscending
+	Name
+	TagCount
+	LastModified
+	MatchScore
+)
+
+// Order in which the sort modes are cycled through
+var allSortModes = []SortMode{
+	TimestampDescending,
+	TimestampAscending,
+	Name,
+	TagCount,
+	LastModified,
+	MatchScore,
+}
+
+// Names used to refer to the sort modes (e.g. in config)
+var sortModeNames = map[SortMode]string{
+	TimestampDescending: "newest",
+	TimestampAscending:  "oldest",
+	Name:                "name",
+	TagCount:            "tag-count",
+	LastModified:        "last-modified",
+	MatchScore:          "match-score",
+}
+
+// Labels displayed in the list footer
+var sortModeLabels = map[SortMode]string{
+	TimestampDescending: "newest first",
+	TimestampAscending:  "oldest first",
+	Name:                "name",
+	TagCount:            "most tags",
+	LastModified:        "recently modified",
+	MatchScore:          "best match",
+}
+
+func ParseSortMode(name string) (SortMode, error) {
+	for _, mode := range allSortModes {
+		if sortModeNames[mode] == name {
+			return mode, nil
+		}
+	}
+
+	validNames := make([]string, 0, len(allSortModes))
+	for _, mode := range allSortModes {
+		validNames = append(validNames, sortModeNames[mode])
+	}
+	return 0, fmt.Errorf("unrecognized sort mode '%s'; valid sort modes are: %s", name, strings.Join(validNames, ", "))
+}
+
+// GetAllSortModes gets the sort modes in the order they're cycled through
+func GetAllSortModes() []SortMode {
+	return append([]SortMode{}, allSortModes...)
+}
+
+func (mode SortMode) String() string {
+	return sortModeNames[mode]
+}
+
+func (mode SortMode) GetLabel() string {
+	return sortModeLabels[mode]
+}
+
+// Next gets the sort mode that comes after this one when cycling (wrapping around), or the one before if the offset
+// is negative
+func (mode SortMode) Next(offset int) SortMode {
+	currentIdx := 0
+	for idx, trialMode := range allSortModes {
+		if trialMode == mode {
+			currentIdx = idx
+			break
+		}
+	}
+
+	numModes := len(allSortModes)
+	nextIdx := ((currentIdx+offset)%numModes + numModes) % numModes
+	return allSortModes[nextIdx]
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getLessFunc gets the ordering function for the sort mode
+// Ties are always broken by newest-first, so the order is stable no matter what order the items came in
+func (mode SortMode) getLessFunc(matchScores map[entry_item.Component]int) func(a entry_item.Component, b entry_item.Component) bool {
+	newestFirst := func(a entry_item.Component, b entry_item.Component) bool {
+		return a.GetTimestamp().After(b.GetTimestamp())
+	}
+
+	switch mode {
+	case TimestampAscending:
+		return func(a entry_item.Component, b entry_item.Component) bool {
+			return a.GetTimestamp().Before(b.GetTimestamp())
+		}
+	case Name:
+		return func(a entry_item.Component, b entry_item.Component) bool {
+			aName := strings.ToLower(a.GetName())
+			bName := strings.ToLower(b.GetName())
+			if aName != bName {
+				return aName < bName
+			}
+			return newestFirst(a, b)
+		}
+	case TagCount:
+		return func(a entry_item.Component, b entry_item.Component) bool {
+			aNumTags := len(a.GetTags())
+			bNumTags := len(b.GetTags())
+			if aNumTags != bNumTags {
+				return aNumTags > bNumTags
+			}
+			return newestFirst(a, b)
+		}
+	case LastModified:
+		return func(a entry_item.Component, b entry_item.Component) bool {
+			if !a.GetLastModified().Equal(b.GetLastModified()) {
+				return a.GetLastModified().After(b.GetLastModified())
+			}
+			return newestFirst(a, b)
+		}
+	case MatchScore:
+		// Lower scores are better matches
+		return func(a entry_item.Component, b entry_item.Component) bool {
+			aScore := matchScores[a]
+			bScore := matchScores[b]
+			if aScore != bScore {
+				return aScore < bScore
+			}
+			return newestFirst(a, b)
+		}
+	default:
+		return newestFirst
+	}
+}
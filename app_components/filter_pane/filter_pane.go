@@ -3,6 +3,7 @@ package filter_pane
 import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/entry_filter"
 	"github.com/mieubrisse/cli-journal-go/global_styles"
 	"github.com/mieubrisse/vim-bubble/vim"
 	"strings"
@@ -28,11 +29,15 @@ var insertModePlacardStyle = lipgloss.NewStyle().
 	Background(global_styles.Orange).
 	Foreground(global_styles.Black)
 
-// TODO allow initializing with a state
-func New() Model {
+// The initial filter text is in the same form as what the user types: one filter per line, with tag filters starting
+// with a '#'
+func New(initialFilterText string) Model {
 	input := vim.New()
 	input.NormalModePlacardStyle = normalModePlacardStyle
 	input.InsertModePlacardStyle = insertModePlacardStyle
+	if len(initialFilterText) > 0 {
+		input.SetValue(initialFilterText)
+	}
 	return Model{
 		input:     input,
 		isFocused: false,
@@ -85,6 +90,12 @@ func (model Model) GetValue() string {
 	return model.input.GetValue()
 }
 
+// SetValue replaces all the filter text
+func (model *Model) SetValue(filterText string) {
+	model.input.CheckpointHistory()
+	model.input.SetValue(filterText)
+}
+
 func (model *Model) Clear() {
 	model.input.SetValue("")
 }
@@ -92,30 +103,7 @@ func (model *Model) Clear() {
 // Returns nameFilterLines, tagFilterLInes
 func (model Model) GetFilterLines() ([]string, []string) {
 	rawLines := strings.Split(model.input.GetValue(), "\n")
-
-	nameFilterLines := make([]string, 0)
-	tagFilterLines := make([]string, 0)
-	for _, rawLine := range rawLines {
-		filter := strings.TrimSpace(rawLine)
-
-		isTagFilter := false
-		if strings.HasPrefix(filter, tagFilterLineLeader) {
-			isTagFilter = true
-			filter = filter[1:]
-		}
-
-		if len(filter) == 0 {
-			continue
-		}
-
-		if isTagFilter {
-			tagFilterLines = append(tagFilterLines, filter)
-		} else {
-			nameFilterLines = append(nameFilterLines, filter)
-		}
-	}
-
-	return nameFilterLines, tagFilterLines
+	return entry_filter.ParseFilterLines(rawLines)
 }
 
 func (model Model) GetMode() vim.Mode {
@@ -141,6 +129,21 @@ func (model Model) GetCurrentFilter() (string, bool) {
 	return line, isTagFilter
 }
 
+// AddFilter adds the filter on a new line after the existing ones
+func (model *Model) AddFilter(filterText string, isTagFilter bool) {
+	model.input.CheckpointHistory()
+	newFilter := filterText
+	if isTagFilter {
+		newFilter = tagFilterLineLeader + filterText
+	}
+
+	value := strings.TrimRight(model.input.GetValue(), "\n")
+	if len(strings.TrimSpace(value)) > 0 {
+		value += "\n"
+	}
+	model.input.SetValue(value + newFilter)
+}
+
 func (model *Model) ReplaceCurrentFilter(filterText string, isTagFilter bool) {
 	model.input.CheckpointHistory()
 	newFilter := filterText
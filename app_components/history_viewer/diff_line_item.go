@@ -0,0 +1,56 @@
+package history_viewer
+
+import (
+	"github.com/mieubrisse/cli-journal-go/global_styles"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"github.com/mieubrisse/cli-journal-go/text_diff"
+)
+
+// diffLineItem is a line of the diff, colored by whether it was added, removed or unchanged
+type diffLineItem struct {
+	line text_diff.Line
+
+	isHighlighted bool
+
+	width  int
+	height int
+}
+
+func (item diffLineItem) View() string {
+	// Cut off before coloring, since truncating doesn't understand color codes
+	text := helpers.TruncateToWidth(diffLinePrefixes[item.line.Kind]+item.line.Text, item.width)
+	style := diffLineStyles[item.line.Kind]
+	if item.isHighlighted {
+		style = style.Copy().Background(global_styles.FocusedComponentBackgroundColor)
+	}
+	return style.Render(helpers.PadToWidth(text, item.width))
+}
+
+func (item *diffLineItem) Resize(width int, height int) {
+	item.width = width
+	item.height = height
+}
+
+func (item diffLineItem) GetWidth() int {
+	return item.width
+}
+
+func (item diffLineItem) GetHeight() int {
+	return item.height
+}
+
+func (item diffLineItem) GetNumLines() int {
+	return 1
+}
+
+func (item diffLineItem) IsHighlighted() bool {
+	return item.isHighlighted
+}
+
+func (item *diffLineItem) SetHighlighted(isHighlighted bool) {
+	item.isHighlighted = isHighlighted
+}
+
+func (item diffLineItem) GetValue() string {
+	return item.line.Text
+}
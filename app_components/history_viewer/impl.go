@@ -0,0 +1,318 @@
+package history_viewer
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/components/filterable_list"
+	"github.com/mieubrisse/cli-journal-go/components/filterable_list_item"
+	"github.com/mieubrisse/cli-journal-go/global_styles"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"github.com/mieubrisse/cli-journal-go/text_diff"
+	"strings"
+)
+
+const (
+	horizontalPadding = 2
+	verticalPadding   = 1
+
+	// The title and the blank line below it, plus the key hints and the blank line above them
+	titleHeight = 2
+	hintsHeight = 2
+
+	// The comparison line above the diff
+	diffHeaderHeight = 1
+
+	maxVersionsPaneWidth = 45
+	paneGap              = 2
+
+	versionTimestampFormat = "2006-01-02 15:04"
+	currentVersionLabel    = "Now (uncommitted)"
+	markedVersionPrefix    = "● "
+	unmarkedVersionPrefix  = "  "
+
+	noMarkedVersionIdx = -1
+
+	keyHints = "j/k: version   m: compare with   ctrl+j/k, ctrl+d/u: scroll   r: restore   esc: close"
+)
+
+var addedLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#8ec07c"))
+var removedLineStyle = lipgloss.NewStyle().Foreground(global_styles.Red)
+var unchangedLineStyle = lipgloss.NewStyle().Faint(true)
+
+var diffLinePrefixes = map[text_diff.LineKind]string{
+	text_diff.Unchanged: "  ",
+	text_diff.Added:     "+ ",
+	text_diff.Removed:   "- ",
+}
+var diffLineStyles = map[text_diff.LineKind]lipgloss.Style{
+	text_diff.Unchanged: unchangedLineStyle,
+	text_diff.Added:     addedLineStyle,
+	text_diff.Removed:   removedLineStyle,
+}
+
+type implementation struct {
+	title string
+
+	versions []Version
+
+	// The version to compare the highlighted one with, or noMarkedVersionIdx to compare it with the one before it
+	markedVersionIdx int
+
+	versionsList filterable_list.Component[filterable_list_item.Component]
+
+	// Scrolled by moving its highlight, which acts as a cursor
+	diffList filterable_list.Component[*diffLineItem]
+
+	// Says which versions the diff is between
+	diffHeader string
+
+	isFocused bool
+
+	height int
+	width  int
+}
+
+func New() Component {
+	return &implementation{
+		title:            "",
+		versions:         []Version{},
+		markedVersionIdx: noMarkedVersionIdx,
+		versionsList:     filterable_list.New[filterable_list_item.Component](),
+		diffList:         filterable_list.New[*diffLineItem](),
+		diffHeader:       "",
+		isFocused:        false,
+		height:           0,
+		width:            0,
+	}
+}
+
+func (impl *implementation) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !impl.isFocused {
+		return nil
+	}
+
+	diffPageHeight := helpers.GetMaxInt(1, impl.diffList.GetHeight()/2)
+	switch keyMsg.String() {
+	case "j", "down":
+		impl.scrollVersions(1)
+	case "k", "up":
+		impl.scrollVersions(-1)
+	case "m":
+		highlightedIdx, found := impl.getHighlightedVersionIndex()
+		if !found {
+			return nil
+		}
+		if impl.markedVersionIdx == highlightedIdx {
+			impl.markedVersionIdx = noMarkedVersionIdx
+		} else {
+			impl.markedVersionIdx = highlightedIdx
+		}
+		impl.refreshVersionsList(highlightedIdx)
+		impl.refreshDiff()
+	case "ctrl+j":
+		impl.scrollDiff(1)
+	case "ctrl+k":
+		impl.scrollDiff(-1)
+	case "ctrl+d":
+		impl.scrollDiff(diffPageHeight)
+	case "ctrl+u":
+		impl.scrollDiff(-diffPageHeight)
+	}
+	return nil
+}
+
+func (impl implementation) View() string {
+	innerWidth := helpers.GetMaxInt(0, impl.width-2*horizontalPadding)
+
+	renderedTitle := lipgloss.NewStyle().
+		Foreground(global_styles.White).
+		Bold(true).
+		Render(impl.title)
+
+	renderedDiffHeader := lipgloss.NewStyle().
+		Foreground(global_styles.Cyan).
+		Render(helpers.TruncateToWidth(impl.diffHeader, impl.diffList.GetWidth()))
+	diffPane := lipgloss.JoinVertical(lipgloss.Left, renderedDiffHeader, impl.diffList.View())
+
+	panes := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		helpers.FitToSize(impl.versionsList.View(), impl.versionsList.GetWidth(), impl.versionsList.GetHeight()),
+		strings.Repeat(" ", paneGap),
+		diffPane,
+	)
+
+	renderedHints := lipgloss.NewStyle().Faint(true).Render(helpers.TruncateToWidth(keyHints, innerWidth))
+
+	lines := lipgloss.JoinVertical(
+		lipgloss.Left,
+		renderedTitle,
+		"",
+		panes,
+		"",
+		renderedHints,
+	)
+
+	return lipgloss.NewStyle().
+		Width(impl.width).
+		Height(impl.height).
+		Padding(verticalPadding, horizontalPadding, verticalPadding, horizontalPadding).
+		Render(lines)
+}
+
+func (impl *implementation) SetVersions(title string, versions []Version) {
+	impl.title = title
+	impl.versions = versions
+	impl.markedVersionIdx = noMarkedVersionIdx
+	impl.refreshVersionsList(0)
+	impl.refreshDiff()
+}
+
+func (impl implementation) GetHighlightedVersion() (Version, bool) {
+	highlightedIdx, found := impl.getHighlightedVersionIndex()
+	if !found {
+		return Version{}, false
+	}
+	return impl.versions[highlightedIdx], true
+}
+
+func (impl *implementation) Focus() tea.Cmd {
+	impl.isFocused = true
+	impl.versionsList.Focus()
+	return impl.diffList.Focus()
+}
+
+func (impl *implementation) Blur() tea.Cmd {
+	impl.isFocused = false
+	impl.versionsList.Blur()
+	return impl.diffList.Blur()
+}
+
+func (impl implementation) Focused() bool {
+	return impl.isFocused
+}
+
+func (impl *implementation) Resize(width int, height int) {
+	impl.width = width
+	impl.height = height
+
+	innerWidth := helpers.GetMaxInt(0, width-2*horizontalPadding)
+	innerHeight := helpers.GetMaxInt(0, height-2*verticalPadding-titleHeight-hintsHeight)
+
+	versionsPaneWidth := helpers.GetMinInt(maxVersionsPaneWidth, innerWidth/3)
+	impl.versionsList.Resize(versionsPaneWidth, innerHeight)
+
+	diffPaneWidth := helpers.GetMaxInt(0, innerWidth-versionsPaneWidth-paneGap)
+	impl.diffList.Resize(diffPaneWidth, helpers.GetMaxInt(0, innerHeight-diffHeaderHeight))
+}
+
+func (impl implementation) GetHeight() int {
+	return impl.height
+}
+
+func (impl implementation) GetWidth() int {
+	return impl.width
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (impl implementation) getHighlightedVersionIndex() (int, bool) {
+	highlightedFilteredIdx, found := impl.versionsList.GetHighlightedItemIndex()
+	if !found {
+		return 0, false
+	}
+	return impl.versionsList.GetFilteredItemIndices()[highlightedFilteredIdx], true
+}
+
+// The list can't scroll when it's empty, so these check first
+func (impl *implementation) scrollVersions(offset int) {
+	if len(impl.versionsList.GetFilteredItemIndices()) == 0 {
+		return
+	}
+	impl.versionsList.Scroll(offset)
+	impl.refreshDiff()
+}
+
+func (impl *implementation) scrollDiff(offset int) {
+	if len(impl.diffList.GetFilteredItemIndices()) == 0 {
+		return
+	}
+	impl.diffList.Scroll(offset)
+}
+
+// refreshVersionsList rebuilds the version rows (e.g. to show which one is marked), highlighting the given version
+func (impl *implementation) refreshVersionsList(highlightedIdx int) {
+
+	items := make([]filterable_list_item.Component, 0, len(impl.versions))
+	for idx, version := range impl.versions {
+		prefix := unmarkedVersionPrefix
+		if idx == impl.markedVersionIdx {
+			prefix = markedVersionPrefix
+		}
+		items = append(items, filterable_list_item.New(prefix+getVersionLabel(version)))
+	}
+	impl.versionsList.SetItems(items)
+	if len(items) > 0 {
+		impl.versionsList.Scroll(highlightedIdx)
+	}
+}
+
+// refreshDiff shows the changes from the older of the compared versions to the newer one
+func (impl *implementation) refreshDiff() {
+	highlightedIdx, found := impl.getHighlightedVersionIndex()
+	if !found {
+		impl.diffHeader = "No versions"
+		impl.diffList.SetItems([]*diffLineItem{})
+		return
+	}
+
+	// The versions are newest first, so the older one has the higher index
+	newerIdx, olderIdx := highlightedIdx, highlightedIdx+1
+	if impl.markedVersionIdx != noMarkedVersionIdx && impl.markedVersionIdx != highlightedIdx {
+		newerIdx = helpers.GetMinInt(highlightedIdx, impl.markedVersionIdx)
+		olderIdx = helpers.GetMaxInt(highlightedIdx, impl.markedVersionIdx)
+	}
+
+	newer := impl.versions[newerIdx]
+	olderBody := ""
+	olderLabel := "nothing"
+	if olderIdx < len(impl.versions) {
+		olderBody = impl.versions[olderIdx].Body
+		olderLabel = getShortVersionLabel(impl.versions[olderIdx])
+	}
+
+	diffLines := text_diff.Diff(olderBody, newer.Body)
+	impl.diffHeader = fmt.Sprintf("From %s to %s", olderLabel, getShortVersionLabel(newer))
+	if !text_diff.HasChanges(diffLines) {
+		impl.diffHeader += " (no changes)"
+	}
+
+	items := make([]*diffLineItem, 0, len(diffLines))
+	for _, line := range diffLines {
+		items = append(items, &diffLineItem{
+			line:          line,
+			isHighlighted: false,
+			width:         0,
+			height:        0,
+		})
+	}
+	impl.diffList.SetItems(items)
+}
+
+func getVersionLabel(version Version) string {
+	if version.IsCurrent {
+		return currentVersionLabel
+	}
+	return version.Timestamp.Format(versionTimestampFormat) + "  " + version.Description
+}
+
+func getShortVersionLabel(version Version) string {
+	if version.IsCurrent {
+		return "now"
+	}
+	return version.Timestamp.Format(versionTimestampFormat)
+}
@@ -0,0 +1,35 @@
+package history_viewer
+
+import (
+	"github.com/mieubrisse/cli-journal-go/components"
+	"time"
+)
+
+// Version is a version of an entry that the viewer can show
+type Version struct {
+	Timestamp time.Time
+
+	// What changed in this version (e.g. the commit message)
+	Description string
+
+	Body string
+
+	// Whether this is the entry as it is now on disk, rather than a saved version
+	IsCurrent bool
+}
+
+/*
+Component is a modal for browsing an entry's versions, showing what changed between two of them
+
+By default the highlighted version is compared with the one before it; marking a version compares it with the
+highlighted one instead.
+*/
+type Component interface {
+	components.InteractiveComponent
+
+	// SetVersions replaces the versions (newest first), highlighting the newest and clearing the mark
+	SetVersions(title string, versions []Version)
+
+	// GetHighlightedVersion gets the highlighted version, returning false if there are no versions
+	GetHighlightedVersion() (Version, bool)
+}
@@ -7,23 +7,21 @@ import (
 	"github.com/mieubrisse/cli-journal-go/components/filterable_list_item"
 	"github.com/mieubrisse/cli-journal-go/components/text_input"
 	"github.com/mieubrisse/cli-journal-go/global_styles"
-	"github.com/mieubrisse/vim-bubble/vim"
-	"regexp"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
 )
 
 const (
 	horizontalPadding = 2
 	verticalPadding   = 1
-
-	title = "Create Content"
 )
 
-var acceptableNameRegex = regexp.MustCompile("^[a-zA-Z0-9.-]+$")
-
 // TODO something about a border?
 
 type implementation struct {
-	input vim.Model
+	title string
+
+	nameInput     text_input.Model
+	nameValidator func(string) bool
 
 	tabCompletionPane filterable_list.Component[filterable_list_item.Component]
 
@@ -33,12 +31,11 @@ type implementation struct {
 	width  int
 }
 
-func New() Component {
-	input := text_input.New("Name: ")
-	validator := func(text string) bool {
-		return acceptableNameRegex.MatchString(text)
-	}
+// New creates a single-field form, whose field turns red while the validator rejects its value
+func New(title string, fieldLabel string, validator func(string) bool) Component {
+	input := text_input.New(fieldLabel)
 	impl := implementation{
+		title:         title,
 		nameInput:     input,
 		nameValidator: validator,
 	}
@@ -46,7 +43,14 @@ func New() Component {
 	return &impl
 }
 
-func (impl implementation) Update(msg tea.Msg) tea.Cmd {
+// NewMasked creates a single-field form that hides what's typed into it (e.g. for passphrases)
+func NewMasked(title string, fieldLabel string, validator func(string) bool) Component {
+	result := New(title, fieldLabel, validator).(*implementation)
+	result.nameInput.SetMasked(true)
+	return result
+}
+
+func (impl *implementation) Update(msg tea.Msg) tea.Cmd {
 	cmd := impl.nameInput.Update(msg)
 	impl.recalculateInputColors()
 	return cmd
@@ -58,7 +62,7 @@ func (impl implementation) View() string {
 	renderedTitle := lipgloss.NewStyle().
 		Foreground(global_styles.White).
 		Bold(true).
-		Render(title)
+		Render(impl.title)
 
 	lines := lipgloss.JoinVertical(
 		lipgloss.Center,
@@ -74,6 +78,11 @@ func (impl implementation) View() string {
 		Render(lines)
 }
 
+// IsValidEntryName gets whether the name is one that an entry can have
+func IsValidEntryName(name string) bool {
+	return journal_store.IsValidEntryName(name)
+}
+
 func (impl implementation) GetValue() string {
 	return impl.nameInput.GetValue()
 }
@@ -87,8 +96,14 @@ func (impl implementation) GetNameValue() string {
 	return impl.nameInput.GetValue()
 }
 
-func (impl implementation) SetNameValue(name string) {
+func (impl *implementation) SetNameValue(name string) {
 	impl.nameInput.SetValue(name)
+	impl.nameInput.CursorEnd()
+	impl.recalculateInputColors()
+}
+
+func (impl implementation) IsNameValid() bool {
+	return impl.nameValidator(impl.nameInput.GetValue())
 }
 
 func (impl *implementation) Focus() tea.Cmd {
@@ -96,7 +111,7 @@ func (impl *implementation) Focus() tea.Cmd {
 	return impl.nameInput.Focus()
 }
 
-func (impl implementation) Blur() tea.Cmd {
+func (impl *implementation) Blur() tea.Cmd {
 	impl.isFocused = false
 	return impl.nameInput.Blur()
 }
@@ -7,5 +7,8 @@ type Component interface {
 
 	GetNameValue() string
 	SetNameValue(name string)
+
+	// IsNameValid gets whether the name typed in is one an entry can have
+	IsNameValid() bool
 	Clear()
 }
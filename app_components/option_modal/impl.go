@@ -0,0 +1,125 @@
+package option_modal
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/components/filterable_list"
+	"github.com/mieubrisse/cli-journal-go/components/filterable_list_item"
+	"github.com/mieubrisse/cli-journal-go/global_styles"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+)
+
+const (
+	horizontalPadding = 2
+	verticalPadding   = 1
+
+	// The title, and the blank line below it
+	titleHeight = 2
+)
+
+type implementation struct {
+	title string
+
+	optionsList filterable_list.Component[filterable_list_item.Component]
+
+	isFocused bool
+
+	height int
+	width  int
+}
+
+func New(title string) Component {
+	return &implementation{
+		title:       title,
+		optionsList: filterable_list.New[filterable_list_item.Component](),
+		isFocused:   false,
+		height:      0,
+		width:       0,
+	}
+}
+
+func (impl *implementation) Update(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "down", "ctrl+n":
+			impl.optionsList.Scroll(1)
+			return nil
+		case "up", "ctrl+p":
+			impl.optionsList.Scroll(-1)
+			return nil
+		}
+	}
+	return impl.optionsList.Update(msg)
+}
+
+func (impl implementation) View() string {
+	renderedTitle := lipgloss.NewStyle().
+		Foreground(global_styles.White).
+		Bold(true).
+		Render(impl.title)
+
+	lines := lipgloss.JoinVertical(
+		lipgloss.Center,
+		renderedTitle,
+		"",
+		impl.optionsList.View(),
+	)
+
+	return lipgloss.NewStyle().
+		Width(impl.width).
+		Height(impl.height).
+		Padding(verticalPadding, horizontalPadding, verticalPadding, horizontalPadding).
+		Render(lines)
+}
+
+func (impl *implementation) SetTitle(title string) {
+	impl.title = title
+}
+
+func (impl *implementation) SetOptions(options []string, highlightedIdx int) {
+	items := make([]filterable_list_item.Component, 0, len(options))
+	for _, option := range options {
+		items = append(items, filterable_list_item.New(option))
+	}
+	impl.optionsList.SetItems(items)
+	impl.optionsList.HighlightItem(highlightedIdx)
+}
+
+func (impl implementation) GetHighlightedOptionIndex() (int, bool) {
+	highlightedFilteredIdx, found := impl.optionsList.GetHighlightedItemIndex()
+	if !found {
+		return 0, false
+	}
+	return impl.optionsList.GetFilteredItemIndices()[highlightedFilteredIdx], true
+}
+
+func (impl *implementation) Focus() tea.Cmd {
+	impl.isFocused = true
+	return impl.optionsList.Focus()
+}
+
+func (impl *implementation) Blur() tea.Cmd {
+	impl.isFocused = false
+	return impl.optionsList.Blur()
+}
+
+func (impl implementation) Focused() bool {
+	return impl.isFocused
+}
+
+func (impl *implementation) Resize(width int, height int) {
+	impl.width = width
+	impl.height = height
+
+	listWidth := helpers.GetMaxInt(0, width-2*horizontalPadding)
+	listHeight := helpers.GetMaxInt(0, height-2*verticalPadding-titleHeight)
+	impl.optionsList.Resize(listWidth, listHeight)
+}
+
+func (impl implementation) GetHeight() int {
+	return impl.height
+}
+
+func (impl implementation) GetWidth() int {
+	return impl.width
+}
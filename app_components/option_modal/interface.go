@@ -0,0 +1,17 @@
+package option_modal
+
+import "github.com/mieubrisse/cli-journal-go/components"
+
+// Component is a modal for choosing one of a list of options
+type Component interface {
+	components.InteractiveComponent
+
+	// SetTitle replaces the title shown above the options (e.g. when the modal is reused for different choices)
+	SetTitle(title string)
+
+	// SetOptions replaces the options, highlighting the one at the given index
+	SetOptions(options []string, highlightedIdx int)
+
+	// GetHighlightedOptionIndex gets the index of the highlighted option, returning false if there are no options
+	GetHighlightedOptionIndex() (int, bool)
+}
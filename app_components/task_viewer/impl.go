@@ -0,0 +1,220 @@
+package task_viewer
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/components/filterable_checklist"
+	"github.com/mieubrisse/cli-journal-go/global_styles"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+)
+
+const (
+	horizontalPadding = 2
+	verticalPadding   = 1
+
+	// The title & task counts and the blank line below them, plus the key hints and the blank line above them
+	titleHeight = 3
+	hintsHeight = 2
+
+	openCheckbox = "[ ] "
+	doneCheckbox = "[x] "
+
+	entryDateFormat = "2006-01-02"
+
+	// The entry a task is from takes up at most this fraction of the row
+	maxSourceWidthFraction = 3
+	sourceGap              = 2
+
+	keyHints = "j/k: move   x: check/uncheck   h: hide/show done   enter: go to entry   esc: close"
+)
+
+type implementation struct {
+	title string
+
+	// Selecting a task is what checks its box
+	tasksList filterable_checklist.Component[*taskItem]
+
+	isHidingDoneTasks bool
+
+	isFocused bool
+
+	height int
+	width  int
+}
+
+func New() Component {
+	return &implementation{
+		title:             "",
+		tasksList:         filterable_checklist.New[*taskItem](),
+		isHidingDoneTasks: false,
+		isFocused:         false,
+		height:            0,
+		width:             0,
+	}
+}
+
+func (impl *implementation) Update(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !impl.isFocused {
+		return nil
+	}
+
+	// The checklist's own keys aren't passed on, since they'd check boxes without writing them to the entries
+	pageHeight := helpers.GetMaxInt(1, impl.tasksList.GetHeight()/2)
+	switch keyMsg.String() {
+	case "j", "down":
+		impl.scroll(1)
+	case "k", "up":
+		impl.scroll(-1)
+	case "J", "ctrl+d":
+		impl.scroll(pageHeight)
+	case "K", "ctrl+u":
+		impl.scroll(-pageHeight)
+	case "h":
+		impl.isHidingDoneTasks = !impl.isHidingDoneTasks
+		impl.refreshFilter()
+	}
+	return nil
+}
+
+func (impl implementation) View() string {
+	innerWidth := helpers.GetMaxInt(0, impl.width-2*horizontalPadding)
+
+	renderedTitle := lipgloss.NewStyle().
+		Foreground(global_styles.White).
+		Bold(true).
+		Render(helpers.TruncateToWidth(impl.title, innerWidth))
+	renderedCounts := lipgloss.NewStyle().
+		Foreground(global_styles.Cyan).
+		Render(helpers.TruncateToWidth(impl.getCountsDescription(), innerWidth))
+
+	renderedHints := lipgloss.NewStyle().Faint(true).Render(helpers.TruncateToWidth(keyHints, innerWidth))
+
+	lines := lipgloss.JoinVertical(
+		lipgloss.Left,
+		renderedTitle,
+		renderedCounts,
+		"",
+		helpers.FitToSize(impl.tasksList.View(), impl.tasksList.GetWidth(), impl.tasksList.GetHeight()),
+		"",
+		renderedHints,
+	)
+
+	return lipgloss.NewStyle().
+		Width(impl.width).
+		Height(impl.height).
+		Padding(verticalPadding, horizontalPadding, verticalPadding, horizontalPadding).
+		Render(lines)
+}
+
+func (impl *implementation) SetTasks(title string, tasks []Task) {
+	impl.title = title
+
+	items := make([]*taskItem, 0, len(tasks))
+	for _, task := range tasks {
+		items = append(items, &taskItem{
+			task:          task,
+			isSelected:    false,
+			isHighlighted: false,
+			width:         0,
+			height:        0,
+		})
+	}
+	impl.tasksList.SetItems(items)
+	for idx, task := range tasks {
+		impl.tasksList.SetItemSelection(idx, task.IsDone)
+	}
+	impl.refreshFilter()
+}
+
+func (impl implementation) GetHighlightedTask() (Task, bool) {
+	highlightedIdx, found := impl.getHighlightedTaskIndex()
+	if !found {
+		return Task{}, false
+	}
+	return impl.tasksList.GetItems()[highlightedIdx].task, true
+}
+
+func (impl *implementation) SetHighlightedTaskDone(isDone bool) {
+	highlightedIdx, found := impl.getHighlightedTaskIndex()
+	if !found {
+		return
+	}
+	item := impl.tasksList.GetItems()[highlightedIdx]
+	item.task.IsDone = isDone
+	impl.tasksList.SetItemSelection(highlightedIdx, isDone)
+
+	// A task that's just been checked off disappears if done tasks are hidden
+	impl.refreshFilter()
+}
+
+func (impl *implementation) Focus() tea.Cmd {
+	impl.isFocused = true
+	return impl.tasksList.Focus()
+}
+
+func (impl *implementation) Blur() tea.Cmd {
+	impl.isFocused = false
+	return impl.tasksList.Blur()
+}
+
+func (impl implementation) Focused() bool {
+	return impl.isFocused
+}
+
+func (impl *implementation) Resize(width int, height int) {
+	impl.width = width
+	impl.height = height
+
+	innerWidth := helpers.GetMaxInt(0, width-2*horizontalPadding)
+	innerHeight := helpers.GetMaxInt(0, height-2*verticalPadding-titleHeight-hintsHeight)
+	impl.tasksList.Resize(innerWidth, innerHeight)
+}
+
+func (impl implementation) GetHeight() int {
+	return impl.height
+}
+
+func (impl implementation) GetWidth() int {
+	return impl.width
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (impl implementation) getHighlightedTaskIndex() (int, bool) {
+	highlightedFilteredIdx, found := impl.tasksList.GetFilterableList().GetHighlightedItemIndex()
+	if !found {
+		return 0, false
+	}
+	return impl.tasksList.GetFilterableList().GetFilteredItemIndices()[highlightedFilteredIdx], true
+}
+
+// The list can't scroll when it's empty, so this checks first
+func (impl *implementation) scroll(offset int) {
+	filterableList := impl.tasksList.GetFilterableList()
+	if len(filterableList.GetFilteredItemIndices()) == 0 {
+		return
+	}
+	filterableList.Scroll(offset)
+}
+
+func (impl *implementation) refreshFilter() {
+	isHidingDoneTasks := impl.isHidingDoneTasks
+	impl.tasksList.GetFilterableList().UpdateFilter(func(idx int, item *taskItem) bool {
+		return !isHidingDoneTasks || !item.IsSelected()
+	})
+}
+
+func (impl implementation) getCountsDescription() string {
+	numDone := len(impl.tasksList.GetSelectedItemOriginalIndices())
+	numOpen := len(impl.tasksList.GetItems()) - numDone
+	result := fmt.Sprintf("%d open, %d done", numOpen, numDone)
+	if impl.isHidingDoneTasks {
+		result += " (hiding done)"
+	}
+	return result
+}
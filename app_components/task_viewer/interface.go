@@ -0,0 +1,35 @@
+package task_viewer
+
+import (
+	"github.com/mieubrisse/cli-journal-go/components"
+	"github.com/mieubrisse/cli-journal-go/entry_tasks"
+	"time"
+)
+
+// Task is a checkbox from one of the journal's entries, along with the entry it's in
+type Task struct {
+	entry_tasks.Task
+
+	EntryPath      string
+	EntryName      string
+	EntryTimestamp time.Time
+}
+
+/*
+Component is a modal listing the tasks from many entries, which can be narrowed down to the open ones
+
+Checking off a task is left to the viewer's owner, which writes it to the task's entry and then calls
+SetHighlightedTaskDone to match.
+*/
+type Component interface {
+	components.InteractiveComponent
+
+	// SetTasks replaces the tasks, highlighting the first
+	SetTasks(title string, tasks []Task)
+
+	// GetHighlightedTask gets the highlighted task, returning false if there are no tasks shown
+	GetHighlightedTask() (Task, bool)
+
+	// SetHighlightedTaskDone checks or unchecks the highlighted task's box
+	SetHighlightedTaskDone(isDone bool)
+}
@@ -0,0 +1,89 @@
+package task_viewer
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/global_styles"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"strings"
+)
+
+// taskItem is a task's row, with its checkbox & text on the left and the entry it's from on the right
+type taskItem struct {
+	task Task
+
+	isSelected    bool
+	isHighlighted bool
+
+	width  int
+	height int
+}
+
+func (item taskItem) View() string {
+	checkbox := openCheckbox
+	if item.isSelected {
+		checkbox = doneCheckbox
+	}
+	// The entry goes on the right, and the text fills whatever's left
+	// These are cut off before coloring, since truncating doesn't understand color codes
+	source := item.task.EntryName + "  " + item.task.EntryTimestamp.Format(entryDateFormat)
+	source = helpers.TruncateToWidth(source, item.width/maxSourceWidthFraction)
+	textWidth := helpers.GetMaxInt(
+		0,
+		item.width-helpers.GetDisplayWidth(checkbox)-sourceGap-helpers.GetDisplayWidth(source),
+	)
+	text := helpers.PadToWidth(helpers.TruncateToWidth(item.task.Text, textWidth), textWidth)
+
+	textStyle := lipgloss.NewStyle()
+	if item.isSelected {
+		textStyle = textStyle.Faint(true)
+	}
+	sourceStyle := lipgloss.NewStyle().Foreground(global_styles.Cyan)
+	if item.isHighlighted {
+		textStyle = textStyle.Background(global_styles.FocusedComponentBackgroundColor)
+		sourceStyle = sourceStyle.Background(global_styles.FocusedComponentBackgroundColor)
+	}
+	return textStyle.Render(checkbox+text+strings.Repeat(" ", sourceGap)) + sourceStyle.Render(source)
+}
+
+func (item *taskItem) Resize(width int, height int) {
+	item.width = width
+	item.height = height
+}
+
+func (item taskItem) GetWidth() int {
+	return item.width
+}
+
+func (item taskItem) GetHeight() int {
+	return item.height
+}
+
+func (item taskItem) GetNumLines() int {
+	return 1
+}
+
+func (item taskItem) IsHighlighted() bool {
+	return item.isHighlighted
+}
+
+func (item *taskItem) SetHighlighted(isHighlighted bool) {
+	item.isHighlighted = isHighlighted
+}
+
+func (item taskItem) GetValue() string {
+	return item.task.Text
+}
+
+func (item taskItem) IsSelected() bool {
+	return item.isSelected
+}
+
+func (item *taskItem) SetSelection(isSelected bool) {
+	item.isSelected = isSelected
+}
+
+// GetCheckmarkWidth is 0 because clicking can't check off a task; that has to go through the viewer's owner, so that
+// the task's entry gets written
+func (item taskItem) GetCheckmarkWidth() int {
+	return 0
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// Names made from the captured text are kept short enough to show in full in the list
+	maxCapturedNameLength = 40
+
+	// Used when the captured text has nothing that can go in a name
+	fallbackCapturedName = "capture"
+)
+
+var addSubcommand = subcommand{
+	name:      "add",
+	argsUsage: "[--tag TAG] [--name NAME] [--today] [TEXT...]",
+	description: "Capture the text (the args, then stdin if it isn't a terminal) in a new entry, named after the text's " +
+		"first line unless a name is given, and print its path",
+	minArgs: 0,
+	maxArgs: unlimitedArgs,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		var tag string
+		flags.StringVar(&tag, "tag", "", "Tag to give the entry")
+		flags.StringVar(&tag, "t", "", "Shorthand for --tag")
+		name := flags.String("name", "", "Name to give the entry, rather than one made from the text")
+		isAppendingToToday := flags.Bool("today", false, "Append to the newest entry with the tag (and name, if given) created today, only creating an entry if there isn't one")
+
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			text, err := readCapturedText(args, stdin)
+			if err != nil {
+				return err
+			}
+			if len(strings.TrimSpace(text)) == 0 {
+				return fmt.Errorf("there's nothing to add; give the text as arguments or on stdin")
+			}
+
+			tagToUse := strings.TrimPrefix(tag, tagPrefix)
+			if err := journal_store.ValidateTag(tagToUse); err != nil {
+				return err
+			}
+			if len(*name) > 0 && !journal_store.IsValidEntryName(*name) {
+				return fmt.Errorf("'%s' isn't a valid entry name; names can only contain letters, numbers, '.' and '-'", *name)
+			}
+
+			now := time.Now()
+			if *isAppendingToToday {
+				entry, found, err := findTodaysEntry(store, tagToUse, *name, now)
+				if err != nil {
+					return err
+				}
+				if found {
+					existingBody, err := store.Read(entry.Path)
+					if err != nil {
+						return err
+					}
+					if _, err := store.Write(entry.Path, joinWithBlankLine(existingBody, text)); err != nil {
+						return err
+					}
+					fmt.Fprintln(stdout, store.GetAbsolutePath(entry.Path))
+					return nil
+				}
+			}
+
+			nameToUse := *name
+			if len(nameToUse) == 0 {
+				firstLine := strings.SplitN(strings.TrimSpace(text), "\n", 2)[0]
+				derivedName, isValid := journal_store.ToEntryName(firstLine, maxCapturedNameLength)
+				if !isValid {
+					derivedName = fallbackCapturedName
+				}
+				nameToUse = derivedName
+			}
+
+			templateBody, err := store.RenderTemplate(now, nameToUse, tagToUse)
+			if err != nil {
+				return err
+			}
+			entry, err := store.Create(now, nameToUse, tagToUse, joinWithBlankLine(templateBody, text))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(stdout, store.GetAbsolutePath(entry.Path))
+			return nil
+		}
+	},
+}
+
+// readCapturedText gets the text to add: the args as a line, followed by stdin unless it's the terminal (in which case
+// the user isn't piping anything in)
+func readCapturedText(args []string, stdin io.Reader) (string, error) {
+	parts := make([]string, 0, 2)
+	if len(args) > 0 {
+		parts = append(parts, strings.Join(args, " "))
+	}
+	if !isTerminal(stdin) {
+		stdinBytes, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("an error occurred reading stdin: %w", err)
+		}
+		if stdinText := strings.TrimRight(string(stdinBytes), "\n"); len(stdinText) > 0 {
+			parts = append(parts, stdinText)
+		}
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return strings.Join(parts, "\n") + "\n", nil
+}
+
+func isTerminal(reader io.Reader) bool {
+	file, ok := reader.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// findTodaysEntry gets the newest entry with the tag created on the same day as now, which must also have the name if
+// one is given
+func findTodaysEntry(store journal_store.Store, tag string, name string, now time.Time) (content_item.ContentItem, bool, error) {
+	entries, err := store.List()
+	if err != nil {
+		return content_item.ContentItem{}, false, err
+	}
+
+	nowYear, nowMonth, nowDay := now.Date()
+	for _, entry := range entries {
+		entryTag := ""
+		if len(entry.Tags) > 0 {
+			entryTag = entry.Tags[0]
+		}
+		if entryTag != tag || (len(name) > 0 && entry.Name != name) {
+			continue
+		}
+
+		entryYear, entryMonth, entryDay := entry.Timestamp.In(now.Location()).Date()
+		if entryYear == nowYear && entryMonth == nowMonth && entryDay == nowDay {
+			return entry, true, nil
+		}
+	}
+	return content_item.ContentItem{}, false, nil
+}
+
+// joinWithBlankLine puts the addition after the body, separated by a blank line (unless there's no body)
+func joinWithBlankLine(body string, addition string) string {
+	if len(strings.TrimSpace(body)) == 0 {
+		return addition
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + addition
+}
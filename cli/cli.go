@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/git_journal"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	successExitCode  = 0
+	failureExitCode  = 1
+	badUsageExitCode = 2
+	unlimitedArgs    = -1
+	tagPrefix        = "#"
+	journalDirPerms  = 0755
+)
+
+// The function that does a subcommand's work, once its flags have been parsed
+type runFunc func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error
+
+// A non-interactive command, for driving the journal from scripts
+type subcommand struct {
+	name        string
+	argsUsage   string
+	description string
+
+	minArgs int
+	maxArgs int
+
+	// Whether the subcommand works without the journal, so it neither creates the journal directory nor gets a store
+	isJournalUnused bool
+
+	// Declares the subcommand's flags, returning the function that runs it (which can read the flags' values)
+	prepare func(flags *flag.FlagSet) runFunc
+}
+
+// RepeatedStringFlag is a flag that can be given multiple times, collecting every value
+type RepeatedStringFlag []string
+
+func (values *RepeatedStringFlag) String() string {
+	return strings.Join(*values, ", ")
+}
+
+func (values *RepeatedStringFlag) Set(value string) error {
+	*values = append(*values, value)
+	return nil
+}
+
+// Lives in a function (rather than a var) so that subcommands can refer to the list of subcommands without an
+// initialization cycle
+func getAllSubcommands() []subcommand {
+	return []subcommand{
+		newSubcommand,
+		lsSubcommand,
+		tagsSubcommand,
+		openSubcommand,
+		retagSubcommand,
+		rmSubcommand,
+		pickSubcommand,
+		addSubcommand,
+		configSubcommand,
+		importSubcommand,
+		exportSubcommand,
+		siteSubcommand,
+		encryptSubcommand,
+		decryptSubcommand,
+		linksSubcommand,
+	}
+}
+
+// Run runs the subcommand named by the first arg on the configured journal, returning the exit code the program should
+// exit with
+func Run(cfg config.Config, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		PrintUsage(stdout)
+		return successExitCode
+	}
+
+	cmd, found := getSubcommand(args[0])
+	if !found {
+		fmt.Fprintf(stderr, "Unrecognized command '%s'\n\n", args[0])
+		PrintUsage(stderr)
+		return badUsageExitCode
+	}
+
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		printSubcommandUsage(stderr, cmd, flags)
+	}
+	run := cmd.prepare(flags)
+
+	positionalArgs, err := parseInterspersedFlags(flags, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return successExitCode
+	}
+	if err != nil {
+		// The flag set has already reported the problem
+		return badUsageExitCode
+	}
+	if len(positionalArgs) < cmd.minArgs || (cmd.maxArgs != unlimitedArgs && len(positionalArgs) > cmd.maxArgs) {
+		fmt.Fprintf(stderr, "Wrong number of arguments to '%s'\n", cmd.name)
+		flags.Usage()
+		return badUsageExitCode
+	}
+
+	if cmd.isJournalUnused {
+		if err := run(cfg, journal_store.Store{}, positionalArgs, stdin, stdout); err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return failureExitCode
+		}
+		return successExitCode
+	}
+
+	store, gitRepo, err := openStore(cfg, cfg.JournalRoot)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return failureExitCode
+	}
+	// Only asked for once a command needs an encrypted entry
+	store.GetKeyring().SetPassphrasePrompt(promptForPassphrase)
+	runErr := run(cfg, store, positionalArgs, stdin, stdout)
+
+	// Whatever was changed before any error still gets committed, so the repository matches the journal
+	if gitRepo != nil {
+		description := strings.TrimSpace(cmd.name + " " + strings.Join(positionalArgs, " "))
+		if err := gitRepo.CommitPending(description); err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return failureExitCode
+		}
+	}
+	if runErr != nil {
+		fmt.Fprintln(stderr, "Error:", runErr)
+		return failureExitCode
+	}
+	return successExitCode
+}
+
+// PrintUsage describes how to start the UI and run the subcommands
+func PrintUsage(out io.Writer) {
+	fmt.Fprintln(out, "Usage: cli-journal [FLAGS]                Browse the journal")
+	fmt.Fprintln(out, "       cli-journal COMMAND [ARGS...]      Run a command without the UI")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+	for _, cmd := range getAllSubcommands() {
+		fmt.Fprintf(out, "  %-8s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Run 'cli-journal COMMAND --help' for a command's arguments & flags")
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// openStore gets the store for the journal at the given root, along with the git repository that records its changes
+// if the config has auto-commit on (nil otherwise)
+// The journal directory gets created if it doesn't exist yet
+func openStore(cfg config.Config, journalRoot string) (journal_store.Store, *git_journal.Repo, error) {
+	if err := os.MkdirAll(journalRoot, journalDirPerms); err != nil {
+		return journal_store.Store{}, nil, fmt.Errorf("an error occurred creating the journal directory '%s': %w", journalRoot, err)
+	}
+	store := journal_store.New(journalRoot).WithEncryptedTags(cfg.GetEncryptedTags())
+	if !cfg.Git.AutoCommit {
+		return store, nil, nil
+	}
+	gitRepo, err := git_journal.Open(journalRoot)
+	if err != nil {
+		return journal_store.Store{}, nil, err
+	}
+	return store.WithChangeListener(gitRepo), gitRepo, nil
+}
+
+func getSubcommand(name string) (subcommand, bool) {
+	for _, cmd := range getAllSubcommands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return subcommand{}, false
+}
+
+func printSubcommandUsage(out io.Writer, cmd subcommand, flags *flag.FlagSet) {
+	fmt.Fprintf(out, "Usage: cli-journal %s %s\n\n%s\n", cmd.name, cmd.argsUsage, cmd.description)
+
+	hasFlags := false
+	flags.VisitAll(func(_ *flag.Flag) {
+		hasFlags = true
+	})
+	if hasFlags {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Flags:")
+		flags.PrintDefaults()
+	}
+}
+
+// parseInterspersedFlags parses the flags wherever they are amongst the args (the flag package stops at the first
+// positional arg), returning the positional args
+// Everything after a "--" is treated as positional
+func parseInterspersedFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	positionalArgs := make([]string, 0)
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		remainingArgs := flags.Args()
+		if len(remainingArgs) == 0 {
+			return positionalArgs, nil
+		}
+
+		numParsed := len(args) - len(remainingArgs)
+		if numParsed > 0 && args[numParsed-1] == "--" {
+			return append(positionalArgs, remainingArgs...), nil
+		}
+		positionalArgs = append(positionalArgs, remainingArgs[0])
+		args = remainingArgs[1:]
+	}
+}
+
+// resolveEntry finds the entry that the arg refers to, which is either its path (relative to the journal root) or its
+// name if no other entry has that name
+func resolveEntry(entries []content_item.ContentItem, nameOrPath string) (content_item.ContentItem, error) {
+	cleanedPath := path.Clean(nameOrPath)
+	for _, entry := range entries {
+		if entry.Path == cleanedPath {
+			return entry, nil
+		}
+	}
+
+	matchingEntries := make([]content_item.ContentItem, 0)
+	for _, entry := range entries {
+		if entry.Name == nameOrPath {
+			matchingEntries = append(matchingEntries, entry)
+		}
+	}
+	switch len(matchingEntries) {
+	case 0:
+		return content_item.ContentItem{}, fmt.Errorf("no entry has name or path '%s'", nameOrPath)
+	case 1:
+		return matchingEntries[0], nil
+	default:
+		matchingPaths := make([]string, 0, len(matchingEntries))
+		for _, entry := range matchingEntries {
+			matchingPaths = append(matchingPaths, entry.Path)
+		}
+		sort.Strings(matchingPaths)
+		return content_item.ContentItem{}, fmt.Errorf(
+			"%d entries are named '%s', so it needs to be given as one of the paths: %s",
+			len(matchingEntries),
+			nameOrPath,
+			strings.Join(matchingPaths, ", "),
+		)
+	}
+}
+
+// resolveEntries resolves each of the args to an entry, failing if any of them don't resolve or if an entry is given
+// twice
+func resolveEntries(store journal_store.Store, namesOrPaths []string) ([]content_item.ContentItem, error) {
+	entries, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]content_item.ContentItem, 0, len(namesOrPaths))
+	seenPaths := map[string]bool{}
+	for _, nameOrPath := range namesOrPaths {
+		entry, err := resolveEntry(entries, nameOrPath)
+		if err != nil {
+			return nil, err
+		}
+		if seenPaths[entry.Path] {
+			return nil, fmt.Errorf("entry '%s' was given more than once", entry.Path)
+		}
+		seenPaths[entry.Path] = true
+		result = append(result, entry)
+	}
+	return result, nil
+}
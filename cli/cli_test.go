@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLsAppliesFilters(t *testing.T) {
+	cfg, store := newTestJournal(t)
+	mustCreate(t, store, "standup-notes", "work")
+	mustCreate(t, store, "standup-ideas", "")
+	mustCreate(t, store, "groceries", "work")
+
+	stdout := runExpectingSuccess(t, cfg, "ls", "--paths", "stand", "#work")
+	if lines := strings.Fields(stdout); len(lines) != 1 || !strings.HasSuffix(lines[0], "standup-notes") {
+		t.Errorf("expected only the work standup entry to be listed, but got:\n%s", stdout)
+	}
+}
+
+func TestLsJSONOutput(t *testing.T) {
+	cfg, store := newTestJournal(t)
+	mustCreate(t, store, "standup-notes", "work")
+	mustCreate(t, store, "groceries", "")
+
+	stdout := runExpectingSuccess(t, cfg, "ls", "--format", "json", "--match-positions", "notes")
+	var records []jsonEntryRecord
+	if err := json.Unmarshal([]byte(stdout), &records); err != nil {
+		t.Fatalf("expected a JSON array of entries, but got an error parsing the output: %v\n%s", err, stdout)
+	}
+	if len(records) != 1 || records[0].Name != "standup-notes" || records[0].SchemaVersion != jsonSchemaVersion {
+		t.Fatalf("expected only the standup entry, but got:\n%s", stdout)
+	}
+	expectedPositions := []jsonMatchPosition{{Start: 8, End: 13}}
+	if records[0].MatchPositions == nil || !reflect.DeepEqual(*records[0].MatchPositions, expectedPositions) {
+		t.Errorf("expected match positions %v, but got:\n%s", expectedPositions, stdout)
+	}
+
+	stdout = runExpectingSuccess(t, cfg, "ls", "--format", "ndjson")
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 2 || strings.Contains(stdout, "match_positions") {
+		t.Errorf("expected one line per entry without match positions, but got:\n%s", stdout)
+	}
+}
+
+func TestAddAppendsToTodaysEntry(t *testing.T) {
+	cfg, store := newTestJournal(t)
+	if err := os.WriteFile(filepath.Join(store.GetRootDirpath(), ".template"), []byte("# {{.Name}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if exitCode := Run(cfg, []string{"add", "-t", "ideas", "--today"}, strings.NewReader("Pizza oven!\n"), &stdout, &stderr); exitCode != successExitCode {
+		t.Fatalf("expected adding from stdin to succeed, but got exit code %d and error output:\n%s", exitCode, stderr.String())
+	}
+	runExpectingSuccess(t, cfg, "add", "--tag", "ideas", "--today", "more", "ideas")
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name != "pizza-oven" {
+		t.Fatalf("expected one entry named after the first capture, but got %v", entries)
+	}
+	body, err := store.Read(entries[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedBody := "# pizza-oven\n\nPizza oven!\n\nmore ideas\n"; body != expectedBody {
+		t.Errorf("expected body %q but got %q", expectedBody, body)
+	}
+}
+
+func TestRetagRefusesAmbiguousNames(t *testing.T) {
+	cfg, store := newTestJournal(t)
+	workNotesPath := mustCreate(t, store, "notes", "work")
+	mustCreate(t, store, "notes", "home")
+
+	var stdout, stderr bytes.Buffer
+	if exitCode := Run(cfg, []string{"retag", "archive", "notes"}, strings.NewReader(""), &stdout, &stderr); exitCode != failureExitCode {
+		t.Fatalf("expected retagging an ambiguous name to fail, but got exit code %d", exitCode)
+	}
+
+	runExpectingSuccess(t, cfg, "retag", "archive", workNotesPath)
+	if output := runExpectingSuccess(t, cfg, "tags"); output != "archive\t1\nhome\t1\n" {
+		t.Errorf("expected one entry to have been retagged, but the tags are:\n%s", output)
+	}
+}
+
+func TestOnlyCommandsUsingTheJournalCreateItsDirectory(t *testing.T) {
+	cfg, _ := newTestJournal(t)
+	cfg.JournalRoot = filepath.Join(cfg.JournalRoot, "not-yet-created")
+
+	runExpectingSuccess(t, cfg, "help")
+	runExpectingSuccess(t, cfg, "config", "show")
+	if _, err := os.Stat(cfg.JournalRoot); !os.IsNotExist(err) {
+		t.Fatalf("expected the journal directory not to be created by commands that don't use it, but got: %v", err)
+	}
+
+	runExpectingSuccess(t, cfg, "ls")
+	if _, err := os.Stat(cfg.JournalRoot); err != nil {
+		t.Errorf("expected listing the journal to create its directory, but got: %v", err)
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newTestJournal(t *testing.T) (config.Config, journal_store.Store) {
+	t.Helper()
+	cfg, err := config.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.JournalRoot = t.TempDir()
+	return cfg, journal_store.New(cfg.JournalRoot)
+}
+
+// Returns the entry's path
+func mustCreate(t *testing.T, store journal_store.Store, name string, tag string) string {
+	t.Helper()
+	entry, err := store.Create(time.Now(), name, tag, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entry.Path
+}
+
+func runExpectingSuccess(t *testing.T, cfg config.Config, args ...string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if exitCode := Run(cfg, args, strings.NewReader(""), &stdout, &stderr); exitCode != successExitCode {
+		t.Fatalf("expected %v to succeed, but got exit code %d and error output:\n%s", args, exitCode, stderr.String())
+	}
+	return stdout.String()
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+)
+
+const (
+	configShowAction  = "show"
+	configPathsAction = "paths"
+)
+
+var configSubcommand = subcommand{
+	name:      "config",
+	argsUsage: configShowAction + "|" + configPathsAction,
+	description: "Print the settings in effect (as JSON, in the form of a config file), or where the config files " +
+		"are looked for",
+	minArgs: 1,
+	maxArgs: 1,
+	// Should work even when the journal root in the config is bad, so the problem can be tracked down
+	isJournalUnused: true,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			switch args[0] {
+			case configShowAction:
+				configJSON, err := cfg.ToJSON()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(stdout, configJSON)
+				return nil
+			case configPathsAction:
+				userConfigFilepath, err := config.GetUserConfigFilepath()
+				if err != nil {
+					return err
+				}
+
+				loadedFilepaths := map[string]bool{}
+				for _, loadedFilepath := range cfg.GetLoadedFilepaths() {
+					loadedFilepaths[loadedFilepath] = true
+				}
+				// Listed in the order they're applied, so later files override earlier ones
+				for _, configFilepath := range []string{userConfigFilepath, config.GetJournalConfigFilepath(cfg.JournalRoot)} {
+					status := "not found"
+					if loadedFilepaths[configFilepath] {
+						status = "loaded"
+					}
+					fmt.Fprintf(stdout, "%s\t%s\n", configFilepath, status)
+				}
+				return nil
+			default:
+				return fmt.Errorf("unrecognized config action '%s'; valid actions are: %s, %s", args[0], configShowAction, configPathsAction)
+			}
+		}
+	},
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"flag"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+)
+
+var encryptSubcommand = subcommand{
+	name:        "encrypt",
+	argsUsage:   "NAME|PATH...",
+	description: "Encrypt the entries on disk, asking for the journal's passphrase (or a new one) if needed",
+	minArgs:     1,
+	maxArgs:     unlimitedArgs,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			return setEncrypted(store, args, true)
+		}
+	},
+}
+
+var decryptSubcommand = subcommand{
+	name:        "decrypt",
+	argsUsage:   "NAME|PATH...",
+	description: "Store the encrypted entries as plaintext again; entries in encrypted tags can't be decrypted",
+	minArgs:     1,
+	maxArgs:     unlimitedArgs,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			return setEncrypted(store, args, false)
+		}
+	},
+}
+
+// setEncrypted encrypts or decrypts the entries, changing none of them if any can't be changed
+func setEncrypted(store journal_store.Store, args []string, isEncrypted bool) error {
+	entries, err := resolveEntries(store, args)
+	if err != nil {
+		return err
+	}
+
+	// Asked for up front, rather than partway through the entries
+	if err := store.GetKeyring().EnsureUnlocked(); err != nil {
+		return err
+	}
+	operations := make([]action_journal.Operation, 0, len(entries))
+	for _, entry := range entries {
+		operations = append(operations, action_journal.NewSetEncryptedOperation(store, entry.Path, isEncrypted))
+	}
+	return action_journal.NewBatchOperation("set encryption", operations).Do()
+}
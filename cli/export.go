@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/entry_filter"
+	"github.com/mieubrisse/cli-journal-go/exporter"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+)
+
+var exportSubcommand = subcommand{
+	name:      "export",
+	argsUsage: "[--format FORMAT] [--output FILE] [--title TITLE] [--entry NAME|PATH]... [FILTER...]",
+	description: "Export the given entries, or else the entries matching all the filters (like 'ls'), to one markdown " +
+		"document, an HTML page, or a tar or zip archive of the entry files",
+	minArgs: 0,
+	maxArgs: unlimitedArgs,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		formatName := flags.String("format", "", "Export format: markdown, html, tar or zip (defaults to the output file's extension, or markdown)")
+		outputFilepath := flags.String("output", "", "File to write the export to, rather than stdout")
+		title := flags.String("title", exporter.DefaultTitle, "Title heading the markdown & HTML documents")
+		var entryRefs RepeatedStringFlag
+		flags.Var(&entryRefs, "entry", "Entry to export, by name or path (can be repeated; can't be used with filters)")
+
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			format := exporter.MarkdownFormat
+			if len(*formatName) > 0 {
+				var err error
+				if format, err = exporter.ParseFormat(*formatName); err != nil {
+					return err
+				}
+			} else if outputFormat, found := exporter.GetFormatForFilepath(*outputFilepath); found {
+				format = outputFormat
+			}
+
+			if len(entryRefs) > 0 && len(args) > 0 {
+				return fmt.Errorf("entries can be given with --entry or chosen by filters, but not both")
+			}
+			entryPaths, err := getExportEntryPaths(store, entryRefs, args)
+			if err != nil {
+				return err
+			}
+			if len(entryPaths) == 0 {
+				return fmt.Errorf("there are no entries to export")
+			}
+
+			if len(*outputFilepath) == 0 {
+				return exporter.Export(store, entryPaths, format, *title, stdout)
+			}
+			return exporter.ExportToFile(store, entryPaths, format, *title, *outputFilepath)
+		}
+	},
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getExportEntryPaths(store journal_store.Store, entryRefs []string, filterArgs []string) ([]string, error) {
+	result := make([]string, 0)
+	if len(entryRefs) > 0 {
+		entries, err := resolveEntries(store, entryRefs)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			result = append(result, entry.Path)
+		}
+		return result, nil
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	filter := entry_filter.New(entry_filter.ParseFilterLines(filterArgs))
+	for _, entry := range entries {
+		if _, isMatch := filter.Match(entry.Name, entry.Tags); isMatch {
+			result = append(result, entry.Path)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/importer"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	jrnlTextImportFormat   = "jrnl"
+	jrnlJSONImportFormat   = "jrnl-json"
+	dayOneImportFormat     = "dayone"
+	obsidianImportFormat   = "obsidian"
+	importTimestampFormat  = "2006-01-02 15:04"
+	importReportColumnSpec = "%-9s %s  %s"
+)
+
+var importFormats = []string{
+	jrnlTextImportFormat,
+	jrnlJSONImportFormat,
+	dayOneImportFormat,
+	obsidianImportFormat,
+}
+
+var importSubcommand = subcommand{
+	name:      "import",
+	argsUsage: "[--dry-run] [--map-tag IMPORTED=TAG]... [--default-tag TAG] FORMAT PATH",
+	description: "Create entries from another journaling tool's export (formats: " + strings.Join(importFormats, ", ") +
+		"; PATH is a file, or the vault directory for obsidian), skipping entries already in the journal, and print a report",
+	minArgs: 2,
+	maxArgs: 2,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		isDryRun := flags.Bool("dry-run", false, "Only print the report, without creating anything")
+		var tagMappingRules RepeatedStringFlag
+		flags.Var(&tagMappingRules, "map-tag", "Give entries with the imported tag this tag, e.g. 'work=projects/work' or 'area/*=areas/*' (can be repeated; the first rule matching the entry's first matching tag wins)")
+		defaultTag := flags.String("default-tag", "", "Tag to give entries that no rule matches, rather than their first imported tag")
+
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			format, sourcePath := args[0], args[1]
+			tagMapper, err := importer.NewTagMapper(tagMappingRules, strings.TrimPrefix(*defaultTag, tagPrefix))
+			if err != nil {
+				return err
+			}
+
+			entries, err := readImportedEntries(format, sourcePath)
+			if err != nil {
+				return err
+			}
+			plan, err := importer.Plan(store, entries, tagMapper)
+			if err != nil {
+				return err
+			}
+			printImportReport(stdout, plan, *isDryRun)
+			if *isDryRun {
+				return nil
+			}
+
+			numCreated, err := importer.Apply(store, plan)
+			fmt.Fprintf(stdout, "Created %d entries\n", numCreated)
+			return err
+		}
+	},
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func readImportedEntries(format string, sourcePath string) ([]importer.ImportedEntry, error) {
+	if format == obsidianImportFormat {
+		return importer.ReadObsidianVault(sourcePath)
+	}
+
+	var read func(io.Reader, string) ([]importer.ImportedEntry, error)
+	switch format {
+	case jrnlTextImportFormat:
+		read = importer.ReadJrnlText
+	case jrnlJSONImportFormat:
+		read = importer.ReadJrnlJSON
+	case dayOneImportFormat:
+		read = importer.ReadDayOneJSON
+	default:
+		return nil, fmt.Errorf("unrecognized import format '%s'; expected one of: %s", format, strings.Join(importFormats, ", "))
+	}
+
+	file, err := os.Open(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return read(file, sourcePath)
+}
+
+// printImportReport prints a line per imported entry saying what happens to it, followed by totals
+func printImportReport(out io.Writer, plan []importer.PlannedEntry, isDryRun bool) {
+	numImportable, numDuplicates, numFailed := 0, 0, 0
+	for _, plannedEntry := range plan {
+		timestampStr := plannedEntry.Entry.Timestamp.Format(importTimestampFormat)
+		switch {
+		case plannedEntry.Err != nil:
+			numFailed++
+			fmt.Fprintf(out, importReportColumnSpec+"\n", "error", timestampStr, fmt.Sprintf("%s: %v", plannedEntry.Entry.Source, plannedEntry.Err))
+		case len(plannedEntry.DuplicateOfPath) > 0:
+			numDuplicates++
+			fmt.Fprintf(out, importReportColumnSpec+"\n", "duplicate", timestampStr, fmt.Sprintf("%s (already at %s)", plannedEntry.Entry.Source, plannedEntry.DuplicateOfPath))
+		default:
+			numImportable++
+			fmt.Fprintf(out, importReportColumnSpec+"\n", "create", timestampStr, fmt.Sprintf("%s (from %s)", plannedEntry.Path, plannedEntry.Entry.Source))
+		}
+	}
+
+	verb := "will be created"
+	if isDryRun {
+		verb = "would be created"
+	}
+	fmt.Fprintf(out, "\n%d entries %s, %d duplicates skipped, %d errors\n", numImportable, verb, numDuplicates, numFailed)
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/entry_filter"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"strings"
+	"time"
+)
+
+/*
+Version of the JSON entry records, which every record carries so that scripts can check they understand it
+
+Adding a field is backwards-compatible and doesn't change the version; removing, renaming or changing the meaning of
+a field does. The schema is documented in docs/json_output.md, which must be kept in sync with the types below.
+*/
+const jsonSchemaVersion = 1
+
+type outputFormat string
+
+const (
+	textOutputFormat   outputFormat = "text"
+	jsonOutputFormat   outputFormat = "json"
+	ndjsonOutputFormat outputFormat = "ndjson"
+)
+
+var allOutputFormats = []outputFormat{
+	textOutputFormat,
+	jsonOutputFormat,
+	ndjsonOutputFormat,
+}
+
+// The JSON form of an entry
+type jsonEntryRecord struct {
+	SchemaVersion int `json:"schema_version"`
+
+	// RFC 3339, with the local time zone's offset
+	Timestamp string `json:"timestamp"`
+
+	Name string `json:"name"`
+
+	// Never null; an untagged entry has an empty list
+	Tags []string `json:"tags"`
+
+	// Absolute path of the entry's file
+	Path string `json:"path"`
+
+	// Whether the entry's file is encrypted
+	Encrypted bool `json:"encrypted"`
+
+	// Only present when asked for; empty if there were no name filters
+	MatchPositions *[]jsonMatchPosition `json:"match_positions,omitempty"`
+}
+
+// Where a name filter's term matched in the entry's name, as byte offsets into the UTF-8 name: [start, end)
+type jsonMatchPosition struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+func parseOutputFormat(name string) (outputFormat, error) {
+	for _, format := range allOutputFormats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+
+	validNames := make([]string, 0, len(allOutputFormats))
+	for _, format := range allOutputFormats {
+		validNames = append(validNames, string(format))
+	}
+	return "", fmt.Errorf("unrecognized output format '%s'; valid formats are: %s", name, strings.Join(validNames, ", "))
+}
+
+func newJSONEntryRecord(
+	store journal_store.Store,
+	entry content_item.ContentItem,
+	filter entry_filter.Filter,
+	isIncludingMatchPositions bool,
+) jsonEntryRecord {
+	tags := append([]string{}, entry.Tags...)
+
+	var matchPositions *[]jsonMatchPosition
+	if isIncludingMatchPositions {
+		positions := make([]jsonMatchPosition, 0)
+		for _, matchRange := range filter.GetNameMatchRanges(entry.Name) {
+			positions = append(positions, jsonMatchPosition{Start: matchRange.Start, End: matchRange.End})
+		}
+		matchPositions = &positions
+	}
+
+	return jsonEntryRecord{
+		SchemaVersion:  jsonSchemaVersion,
+		Timestamp:      entry.Timestamp.Format(time.RFC3339),
+		Name:           entry.Name,
+		Tags:           tags,
+		Path:           store.GetAbsolutePath(entry.Path),
+		Encrypted:      entry.IsEncrypted,
+		MatchPositions: matchPositions,
+	}
+}
+
+// writeJSONEntryRecords writes the records as a single JSON array, or as one JSON object per line for NDJSON
+func writeJSONEntryRecords(out io.Writer, records []jsonEntryRecord, format outputFormat) error {
+	encoder := json.NewEncoder(out)
+	if format == ndjsonOutputFormat {
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return fmt.Errorf("an error occurred writing the JSON for entry '%s': %w", record.Path, err)
+			}
+		}
+		return nil
+	}
+
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("an error occurred writing the entries as JSON: %w", err)
+	}
+	return nil
+}
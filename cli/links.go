@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"github.com/mieubrisse/cli-journal-go/wiki_links"
+	"io"
+)
+
+var linksSubcommand = subcommand{
+	name:        "links",
+	argsUsage:   "[NAME|PATH]",
+	description: "List the entries that an entry [[links]] to (or that link to it), or every broken link",
+	minArgs:     0,
+	maxArgs:     1,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		isBacklinks := flags.Bool("backlinks", false, "List the entries that link to the entry, rather than the ones it links to")
+		isBroken := flags.Bool("broken", false, "List every link to an entry that doesn't exist, as the linking entry's path & the link")
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			if *isBroken {
+				if len(args) > 0 || *isBacklinks {
+					return fmt.Errorf("--broken lists the broken links in every entry, so it doesn't take an entry or --backlinks")
+				}
+				return printBrokenLinks(store, stdout)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("an entry to list the links of is required (or --broken)")
+			}
+
+			index, err := wiki_links.BuildIndex(store)
+			if err != nil {
+				return err
+			}
+			entries, err := store.List()
+			if err != nil {
+				return err
+			}
+			entry, err := resolveEntry(entries, args[0])
+			if err != nil {
+				return err
+			}
+
+			if *isBacklinks {
+				for _, backlink := range index.GetBacklinks(entry.Path) {
+					fmt.Fprintln(stdout, store.GetAbsolutePath(backlink.Path))
+				}
+				return nil
+			}
+			// Each entry is listed once, in the order it's first linked to
+			seenPaths := map[string]bool{}
+			for _, link := range index.GetLinks(entry.Path) {
+				if link.IsBroken() || seenPaths[link.TargetPath] {
+					continue
+				}
+				seenPaths[link.TargetPath] = true
+				fmt.Fprintln(stdout, store.GetAbsolutePath(link.TargetPath))
+			}
+			return nil
+		}
+	},
+}
+
+func printBrokenLinks(store journal_store.Store, stdout io.Writer) error {
+	index, err := wiki_links.BuildIndex(store)
+	if err != nil {
+		return err
+	}
+	for _, brokenLink := range index.GetBrokenLinks() {
+		fmt.Fprintf(stdout, "%s\t[[%s]]\n", store.GetAbsolutePath(brokenLink.SourcePath), brokenLink.Target)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/entry_filter"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"strings"
+)
+
+var lsSubcommand = subcommand{
+	name:      "ls",
+	argsUsage: "[--paths | --format FORMAT [--match-positions]] [FILTER...]",
+	description: "List the entries matching all the filters, newest first; like in the UI, a filter starting with '#' " +
+		"filters on tags, and a filter's words must appear in order",
+	minArgs: 0,
+	maxArgs: unlimitedArgs,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		isPrintingOnlyPaths := flags.Bool("paths", false, "Print only the entries' paths, one per line")
+		formatName := flags.String("format", string(textOutputFormat), "Output format: text, json (an array) or ndjson (one object per line), with the JSON schema described in docs/json_output.md")
+		isIncludingMatchPositions := flags.Bool("match-positions", false, "Include where the name filters matched each name (JSON formats only)")
+
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			format, err := parseOutputFormat(*formatName)
+			if err != nil {
+				return err
+			}
+			if *isPrintingOnlyPaths && format != textOutputFormat {
+				return fmt.Errorf("--paths can't be used with the '%s' format", format)
+			}
+			if *isIncludingMatchPositions && format == textOutputFormat {
+				return fmt.Errorf("--match-positions needs one of the JSON formats")
+			}
+
+			entries, err := store.List()
+			if err != nil {
+				return err
+			}
+
+			filter := entry_filter.New(entry_filter.ParseFilterLines(args))
+			jsonRecords := make([]jsonEntryRecord, 0)
+			for _, entry := range entries {
+				if _, isMatch := filter.Match(entry.Name, entry.Tags); !isMatch {
+					continue
+				}
+
+				if format != textOutputFormat {
+					jsonRecords = append(jsonRecords, newJSONEntryRecord(store, entry, filter, *isIncludingMatchPositions))
+					continue
+				}
+
+				absPath := store.GetAbsolutePath(entry.Path)
+				if *isPrintingOnlyPaths {
+					fmt.Fprintln(stdout, absPath)
+					continue
+				}
+				fmt.Fprintf(
+					stdout,
+					"%s\t%s\t%s\t%s\n",
+					entry.Timestamp.Format(cfg.TimestampFormat),
+					entry.Name,
+					strings.Join(entry.Tags, ","),
+					absPath,
+				)
+			}
+
+			if format == textOutputFormat {
+				return nil
+			}
+			return writeJSONEntryRecords(stdout, jsonRecords, format)
+		}
+	},
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"strings"
+	"time"
+)
+
+var newSubcommand = subcommand{
+	name:        "new",
+	argsUsage:   "[--tag TAG] NAME",
+	description: "Create an entry (from its tag's template, if there is one) and print its path",
+	minArgs:     1,
+	maxArgs:     1,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		tag := flags.String("tag", "", "Tag to give the entry")
+
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			name := args[0]
+			if !journal_store.IsValidEntryName(name) {
+				return fmt.Errorf("'%s' isn't a valid entry name; names can only contain letters, numbers, '.' and '-'", name)
+			}
+
+			timestamp := time.Now()
+			tagToUse := strings.TrimPrefix(*tag, tagPrefix)
+			body, err := store.RenderTemplate(timestamp, name, tagToUse)
+			if err != nil {
+				return err
+			}
+			entry, err := store.Create(timestamp, name, tagToUse, body)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(stdout, store.GetAbsolutePath(entry.Path))
+			return nil
+		}
+	},
+}
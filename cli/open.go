@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Used when no editor is configured and neither $VISUAL nor $EDITOR is set
+const fallbackEditor = "vi"
+
+var openSubcommand = subcommand{
+	name:        "open",
+	argsUsage:   "NAME|PATH",
+	description: "Open the entry in the configured editor, or $VISUAL or $EDITOR",
+	minArgs:     1,
+	maxArgs:     1,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			entries, err := resolveEntries(store, args)
+			if err != nil {
+				return err
+			}
+
+			editFilepath, doneEditing, err := store.OpenForEditing(entries[0].Path)
+			if err != nil {
+				return err
+			}
+
+			// The editor command can have args of its own (e.g. "code --wait")
+			editorCmdline := strings.Fields(getEditor(cfg))
+			editorArgs := append(editorCmdline[1:], editFilepath)
+			editorCmd := exec.Command(editorCmdline[0], editorArgs...)
+			editorCmd.Stdin = os.Stdin
+			editorCmd.Stdout = os.Stdout
+			editorCmd.Stderr = os.Stderr
+			// Whatever the editor managed to save is kept, even if it failed
+			runErr := editorCmd.Run()
+			if err := doneEditing(); err != nil {
+				return err
+			}
+			if runErr != nil {
+				return fmt.Errorf("an error occurred running editor '%s': %w", editorCmdline[0], runErr)
+			}
+			return nil
+		}
+	},
+}
+
+func getEditor(cfg config.Config) string {
+	if editor := strings.TrimSpace(cfg.Editor); len(editor) > 0 {
+		return editor
+	}
+	for _, envVar := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(envVar)); len(editor) > 0 {
+			return editor
+		}
+	}
+	return fallbackEditor
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"golang.org/x/term"
+	"os"
+)
+
+// Lets scripts give the passphrase, since there's no terminal to ask for it on
+const passphraseEnvVar = "CLI_JOURNAL_PASSPHRASE"
+
+// promptForPassphrase asks for the journal's passphrase on the terminal (or for a new one, typed twice), unless it's
+// in the environment
+func promptForPassphrase(isNewPassphrase bool) (string, error) {
+	if passphrase, found := os.LookupEnv(passphraseEnvVar); found {
+		return passphrase, nil
+	}
+
+	terminal, err := os.OpenFile(terminalFilepath, os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("the journal's passphrase is needed but there's no terminal to ask for it on; set $%s instead", passphraseEnvVar)
+	}
+	defer terminal.Close()
+
+	if !isNewPassphrase {
+		return readPassphrase(terminal, "Journal passphrase: ")
+	}
+	passphrase, err := readPassphrase(terminal, "New journal passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	confirmation, err := readPassphrase(terminal, "Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirmation != passphrase {
+		return "", fmt.Errorf("the passphrases didn't match")
+	}
+	return passphrase, nil
+}
+
+// readPassphrase reads a line from the terminal without echoing it
+func readPassphrase(terminal *os.File, prompt string) (string, error) {
+	fmt.Fprint(terminal, prompt)
+	passphrase, err := term.ReadPassword(int(terminal.Fd()))
+	// The user's enter wasn't echoed either
+	fmt.Fprintln(terminal)
+	if err != nil {
+		return "", fmt.Errorf("an error occurred reading the passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
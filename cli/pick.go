@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"github.com/muesli/termenv"
+	"io"
+	"os"
+	"strings"
+)
+
+// The UI talks to the terminal directly, leaving stdout free for the picked entries
+const terminalFilepath = "/dev/tty"
+
+var pickSubcommand = subcommand{
+	name:      "pick",
+	argsUsage: "[--names] [--mouse] [FILTER...]",
+	description: "Choose entries in the UI, starting with the filters applied, and print them; enter picks the selected " +
+		"entries (or the highlighted one)",
+	minArgs: 0,
+	maxArgs: unlimitedArgs,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		isPrintingNames := flags.Bool("names", false, "Print the entries' names rather than their paths")
+		isMouseEnabled := flags.Bool("mouse", false, "Enables scrolling & clicking with the mouse")
+
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			terminal, err := os.OpenFile(terminalFilepath, os.O_RDWR, 0)
+			if err != nil {
+				return fmt.Errorf("an error occurred opening the terminal at '%s', which picking needs: %w", terminalFilepath, err)
+			}
+			defer terminal.Close()
+
+			// Colors would otherwise be decided by stdout, which is usually a pipe here
+			lipgloss.DefaultRenderer().SetOutput(termenv.NewOutput(terminal))
+
+			// Each arg is a line of the filter pane
+			// Any changes made while picking are committed along with the rest of the command's
+			model, err := newAppModel(cfg, store, nil, strings.Join(args, "\n"))
+			if err != nil {
+				return err
+			}
+			model.EnablePickMode()
+
+			finalModel, err := runAppModel(model, *isMouseEnabled, tea.WithInput(terminal), tea.WithOutput(terminal))
+			if err != nil {
+				return err
+			}
+			entryPaths, wasPicked := finalModel.GetPickedEntryPaths()
+			if !wasPicked {
+				return fmt.Errorf("no entries were picked")
+			}
+
+			for _, entryPath := range entryPaths {
+				if !*isPrintingNames {
+					fmt.Fprintln(stdout, store.GetAbsolutePath(entryPath))
+					continue
+				}
+				entry, err := store.Get(entryPath)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(stdout, entry.Name)
+			}
+			return nil
+		}
+	},
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"flag"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"strings"
+)
+
+var retagSubcommand = subcommand{
+	name:        "retag",
+	argsUsage:   "TAG NAME|PATH...",
+	description: "Move the entries to the tag (an empty tag untags them); if any can't be moved, none are",
+	minArgs:     2,
+	maxArgs:     unlimitedArgs,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			newTag := strings.TrimPrefix(args[0], tagPrefix)
+			if err := journal_store.ValidateTag(newTag); err != nil {
+				return err
+			}
+			entries, err := resolveEntries(store, args[1:])
+			if err != nil {
+				return err
+			}
+
+			operations := make([]action_journal.Operation, 0, len(entries))
+			for _, entry := range entries {
+				operation, err := action_journal.NewMoveOperation(store, entry.Path, newTag, entry.Name)
+				if err != nil {
+					return err
+				}
+				operations = append(operations, operation)
+			}
+			return action_journal.NewBatchOperation("retag", operations).Do()
+		}
+	},
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"flag"
+	"github.com/mieubrisse/cli-journal-go/app_components/action_journal"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+)
+
+var rmSubcommand = subcommand{
+	name:        "rm",
+	argsUsage:   "NAME|PATH...",
+	description: "Delete the entries; if any can't be deleted, none are",
+	minArgs:     1,
+	maxArgs:     unlimitedArgs,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			entries, err := resolveEntries(store, args)
+			if err != nil {
+				return err
+			}
+
+			operations := make([]action_journal.Operation, 0, len(entries))
+			for _, entry := range entries {
+				operations = append(operations, action_journal.NewDeleteOperation(store, entry.Path))
+			}
+			return action_journal.NewBatchOperation("delete", operations).Do()
+		}
+	},
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/entry_filter"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"github.com/mieubrisse/cli-journal-go/site_generator"
+	"io"
+	"strings"
+)
+
+const defaultSiteTitle = "Journal"
+
+var siteSubcommand = subcommand{
+	name:      "site",
+	argsUsage: "[--title TITLE] [--tag TAG]... OUTPUT_DIR [FILTER...]",
+	description: "Generate a static site of the entries under the tags (or all of them), matching all the filters, " +
+		"that can be browsed & searched offline from OUTPUT_DIR",
+	minArgs: 1,
+	maxArgs: unlimitedArgs,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		title := flags.String("title", defaultSiteTitle, "Title of the site")
+		var tags RepeatedStringFlag
+		flags.Var(&tags, "tag", "Only publish entries with this tag or one under it, e.g. 'general-reference' (can be repeated)")
+
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			outputDirpath := args[0]
+			publishedTags := make([]string, 0, len(tags))
+			for _, tag := range tags {
+				tag = strings.TrimPrefix(tag, tagPrefix)
+				if err := journal_store.ValidateTag(tag); err != nil {
+					return err
+				}
+				publishedTags = append(publishedTags, tag)
+			}
+
+			entries, err := store.List()
+			if err != nil {
+				return err
+			}
+			filter := entry_filter.New(entry_filter.ParseFilterLines(args[1:]))
+			entryPaths := make([]string, 0)
+			for _, entry := range entries {
+				if _, isMatch := filter.Match(entry.Name, entry.Tags); !isMatch {
+					continue
+				}
+				if len(publishedTags) > 0 && !isUnderAnyTag(entry.Tags, publishedTags) {
+					continue
+				}
+				entryPaths = append(entryPaths, entry.Path)
+			}
+
+			if err := site_generator.Generate(store, entryPaths, *title, outputDirpath); err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "Generated a site of %d entries in %s\n", len(entryPaths), outputDirpath)
+			return nil
+		}
+	},
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func isUnderAnyTag(entryTags []string, parentTags []string) bool {
+	for _, entryTag := range entryTags {
+		for _, parentTag := range parentTags {
+			if entryTag == parentTag || strings.HasPrefix(entryTag, parentTag+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"sort"
+)
+
+var tagsSubcommand = subcommand{
+	name:        "tags",
+	argsUsage:   "",
+	description: "List the tags in use, with how many entries have each",
+	minArgs:     0,
+	maxArgs:     0,
+	prepare: func(flags *flag.FlagSet) runFunc {
+		return func(cfg config.Config, store journal_store.Store, args []string, stdin io.Reader, stdout io.Writer) error {
+			entries, err := store.List()
+			if err != nil {
+				return err
+			}
+
+			tagCounts := map[string]int{}
+			for _, entry := range entries {
+				for _, tag := range entry.Tags {
+					tagCounts[tag]++
+				}
+			}
+			tags := make([]string, 0, len(tagCounts))
+			for tag := range tagCounts {
+				tags = append(tags, tag)
+			}
+			sort.Strings(tags)
+
+			for _, tag := range tags {
+				fmt.Fprintf(stdout, "%s\t%d\n", tag, tagCounts[tag])
+			}
+			return nil
+		}
+	},
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mieubrisse/cli-journal-go/app_components/app_model"
+	"github.com/mieubrisse/cli-journal-go/config"
+	"github.com/mieubrisse/cli-journal-go/git_journal"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"strings"
+)
+
+// RunUI browses the configured journal in the full-screen UI, starting with the filter pane filled in with the
+// configured default filters followed by the filter text
+func RunUI(cfg config.Config, initialFilterText string, isMouseEnabled bool) error {
+	store, gitRepo, err := openStore(cfg, cfg.JournalRoot)
+	if err != nil {
+		return err
+	}
+	model, err := newAppModel(cfg, store, gitRepo, initialFilterText)
+	if err != nil {
+		return err
+	}
+	_, err = runAppModel(model, isMouseEnabled)
+	return err
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// The git repo commits the store's changes, and is nil if the journal isn't kept in git (or its changes are committed
+// some other way)
+func newAppModel(cfg config.Config, store journal_store.Store, gitRepo *git_journal.Repo, initialFilterText string) (app_model.Model, error) {
+	// The UI has the terminal, so it asks for the passphrase itself (with the 'unlock' command)
+	store.GetKeyring().SetPassphrasePrompt(nil)
+
+	// TODO deal with pagination
+	content, err := store.List()
+	if err != nil {
+		return app_model.Model{}, fmt.Errorf("an error occurred reading the journal: %w", err)
+	}
+
+	filterLines := append([]string{}, cfg.DefaultFilters...)
+	if len(strings.TrimSpace(initialFilterText)) > 0 {
+		filterLines = append(filterLines, initialFilterText)
+	}
+	filterText := strings.Join(filterLines, "\n")
+
+	model := app_model.New(
+		store,
+		content,
+		filterText,
+		cfg.GetSortMode(),
+		cfg.GetGroupingMode(),
+		cfg.GetColumns(),
+		cfg.Layout.FilterPaneHeight,
+	)
+
+	journals, currentJournalIdx := cfg.GetJournals()
+	if len(journals) == 0 {
+		model.SetGitRepo(gitRepo)
+		return model, nil
+	}
+
+	workspaces := make([]app_model.Workspace, 0, len(journals))
+	for journalIdx, journal := range journals {
+		workspace := app_model.Workspace{Name: journal.Name, Store: store, GitRepo: gitRepo, InitialFilterText: filterText}
+		if journalIdx != currentJournalIdx {
+			// Each journal is opened with its own config, so that its own encrypted tags, committing & filters apply
+			journalName := journal.Name
+			if _, isNamed := cfg.Journals[journal.Name]; !isNamed {
+				journalName = ""
+			}
+			journalCfg, err := config.Load(journalName)
+			if err != nil {
+				return app_model.Model{}, fmt.Errorf("an error occurred loading the config of journal '%s': %w", journal.Name, err)
+			}
+			journalStore, journalGitRepo, err := openStore(journalCfg, journal.Root)
+			if err != nil {
+				return app_model.Model{}, fmt.Errorf("an error occurred opening journal '%s': %w", journal.Name, err)
+			}
+			workspace = app_model.Workspace{
+				Name:              journal.Name,
+				Store:             journalStore,
+				GitRepo:           journalGitRepo,
+				InitialFilterText: strings.Join(journalCfg.DefaultFilters, "\n"),
+			}
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	model.SetWorkspaces(workspaces, currentJournalIdx)
+	return model, nil
+}
+
+// runAppModel runs the UI until the user exits, returning the model as it was at the end
+func runAppModel(model app_model.Model, isMouseEnabled bool, extraOpts ...tea.ProgramOption) (app_model.Model, error) {
+	programOpts := []tea.ProgramOption{tea.WithAltScreen()}
+	if isMouseEnabled {
+		programOpts = append(programOpts, tea.WithMouseCellMotion())
+	}
+	programOpts = append(programOpts, extraOpts...)
+
+	finalModel, err := tea.NewProgram(model, programOpts...).Run()
+	if err != nil {
+		return app_model.Model{}, fmt.Errorf("an error occurred running the UI: %w", err)
+	}
+	return finalModel.(app_model.Model), nil
+}
@@ -4,6 +4,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mieubrisse/cli-journal-go/components/filterable_checklist_item"
 	"github.com/mieubrisse/cli-journal-go/components/filterable_list"
+	"github.com/mieubrisse/cli-journal-go/helpers"
 )
 
 type implementation[T filterable_checklist_item.Component] struct {
@@ -14,6 +15,15 @@ type implementation[T filterable_checklist_item.Component] struct {
 	// Indices of selected items within the *unfiltered* list
 	selectedItemIndices map[int]bool
 
+	// In visual mode, the range between the anchor item and the highlighted item gets acted on as a whole
+	isInVisualMode bool
+
+	// Index within the *unfiltered* list of the item where visual mode was started
+	visualAnchorOriginalIdx int
+
+	// Indices within the *unfiltered* list of the items we highlighted to show the visual range
+	visualRangeHighlightedIndices map[int]bool
+
 	isFocused bool
 	width     int
 	height    int
@@ -23,22 +33,29 @@ type implementation[T filterable_checklist_item.Component] struct {
 func New[T filterable_checklist_item.Component]() Component[T] {
 	inner := filterable_list.New[T]()
 	return &implementation[T]{
-		innerList:           inner,
-		items:               make([]T, 0),
-		selectedItemIndices: make(map[int]bool, 0),
-		isFocused:           false,
-		width:               0,
-		height:              0,
+		innerList:                     inner,
+		items:                         make([]T, 0),
+		selectedItemIndices:           make(map[int]bool, 0),
+		isInVisualMode:                false,
+		visualAnchorOriginalIdx:       0,
+		visualRangeHighlightedIndices: make(map[int]bool, 0),
+		isFocused:                     false,
+		width:                         0,
+		height:                        0,
 	}
 }
 
-func (impl *implementation[T]) View() string {
+func (impl implementation[T]) View() string {
 	return impl.innerList.View()
 }
 
 func (impl *implementation[T]) Update(msg tea.Msg) tea.Cmd {
-	// Do nothing on non-Keymsgs
-	switch msg.(type) {
+	// Mouse events get routed to us by position, so we handle them whether or not we're focused
+	switch castedMsg := msg.(type) {
+	case tea.MouseMsg:
+		impl.handleMouse(castedMsg)
+		impl.refreshVisualRangeHighlights()
+		return nil
 	case tea.KeyMsg:
 		// Proceed to rest of function
 	default:
@@ -49,25 +66,10 @@ func (impl *implementation[T]) Update(msg tea.Msg) tea.Cmd {
 		return nil
 	}
 
-	// TODO allow for KeyMap overrides here?
-	var returnCmd tea.Cmd
-	castedMsg := msg.(tea.KeyMsg)
-	switch castedMsg.String() {
-	case "x":
-		impl.ToggleHighlightedItemSelection()
-	case "s":
-		impl.SetAllViewableItemsSelection(true)
-	case "d":
-		impl.SetAllViewableItemsSelection(false)
-	case "S":
-		impl.SetAllItemsSelection(true)
-	case "D":
-		impl.SetAllItemsSelection(false)
-	default:
-		returnCmd = impl.innerList.Update(msg)
-	}
-
-	return returnCmd
+	// Selecting & visual mode are left to the owner's commands (so their keys can be remapped), which call our methods
+	cmd := impl.innerList.Update(msg)
+	impl.refreshVisualRangeHighlights()
+	return cmd
 }
 
 func (impl implementation[T]) GetItems() []T {
@@ -76,14 +78,19 @@ func (impl implementation[T]) GetItems() []T {
 
 func (impl *implementation[T]) SetItems(items []T) {
 	// TODO something about preserving the selected item indices when the list changes??
+	impl.StopVisualMode()
 	impl.items = items
 	impl.selectedItemIndices = make(map[int]bool, 0)
 
 	impl.innerList.SetItems(items)
 }
 
-func (impl implementation[T]) GetFilterableList() filterable_list.Component[T] {
-	return impl.innerList
+func (impl *implementation[T]) GetFilterableList() filterable_list.Component[T] {
+	// Moving the highlight through the inner list moves the end of the visual range, so it gets caught up
+	return visualRangeTrackingList[T]{
+		Component: impl.innerList,
+		checklist: impl,
+	}
 }
 
 func (impl implementation[T]) GetSelectedItemOriginalIndices() map[int]bool {
@@ -91,24 +98,29 @@ func (impl implementation[T]) GetSelectedItemOriginalIndices() map[int]bool {
 }
 
 func (impl *implementation[T]) ToggleHighlightedItemSelection() {
-	filteredItemOriginalIndicies := impl.innerList.GetFilteredItemIndices()
-	if len(filteredItemOriginalIndicies) == 0 {
+	highlightedItemIdxInFilteredList, found := impl.innerList.GetHighlightedItemIndex()
+	if !found {
 		return
 	}
-	itemOriginalIdx := filteredItemOriginalIndicies[impl.innerList.GetHighlightedItemIndex()]
+	itemOriginalIdx := impl.innerList.GetFilteredItemIndices()[highlightedItemIdxInFilteredList]
 	item := impl.items[itemOriginalIdx]
 	isSelected := item.IsSelected()
 	impl.setItemSelection(itemOriginalIdx, !isSelected)
 }
 
-func (impl *implementation[T]) SetHighlightedItemSelection(isSelected bool) {
-	filteredItemIndices := impl.innerList.GetFilteredItemIndices()
-	if len(filteredItemIndices) == 0 {
+func (impl *implementation[T]) SetItemSelection(originalIdx int, isSelected bool) {
+	if originalIdx < 0 || originalIdx >= len(impl.items) {
 		return
 	}
+	impl.setItemSelection(originalIdx, isSelected)
+}
 
-	highlightedItemIdxInFilteredList := impl.innerList.GetHighlightedItemIndex()
-	highlightedItemIdxInOriginalList := filteredItemIndices[highlightedItemIdxInFilteredList]
+func (impl *implementation[T]) SetHighlightedItemSelection(isSelected bool) {
+	highlightedItemIdxInFilteredList, found := impl.innerList.GetHighlightedItemIndex()
+	if !found {
+		return
+	}
+	highlightedItemIdxInOriginalList := impl.innerList.GetFilteredItemIndices()[highlightedItemIdxInFilteredList]
 
 	impl.setItemSelection(highlightedItemIdxInOriginalList, isSelected)
 }
@@ -124,12 +136,92 @@ func (impl *implementation[T]) SetAllViewableItemsSelection(isSelected bool) {
 	}
 }
 
+func (impl *implementation[T]) SetHighlightedGroupSelection(isSelected bool) {
+	for _, originalItemIdx := range impl.innerList.GetHighlightedGroupItemIndices() {
+		impl.setItemSelection(originalItemIdx, isSelected)
+	}
+}
+
 func (impl *implementation[T]) SetAllItemsSelection(isSelected bool) {
 	for idx := range impl.items {
 		impl.setItemSelection(idx, isSelected)
 	}
 }
 
+func (impl *implementation[T]) StartVisualMode() {
+	highlightedFilteredIdx, found := impl.innerList.GetHighlightedItemIndex()
+	if !found {
+		return
+	}
+	impl.isInVisualMode = true
+	impl.visualAnchorOriginalIdx = impl.innerList.GetFilteredItemIndices()[highlightedFilteredIdx]
+	impl.refreshVisualRangeHighlights()
+}
+
+func (impl *implementation[T]) StopVisualMode() {
+	impl.isInVisualMode = false
+	impl.refreshVisualRangeHighlights()
+}
+
+func (impl implementation[T]) IsInVisualMode() bool {
+	return impl.isInVisualMode
+}
+
+func (impl implementation[T]) GetVisualRangeOriginalIndices() []int {
+	if !impl.isInVisualMode {
+		return []int{}
+	}
+
+	filteredItemIndices := impl.innerList.GetFilteredItemIndices()
+	anchorFilteredIdx := -1
+	for filteredIdx, originalIdx := range filteredItemIndices {
+		if originalIdx == impl.visualAnchorOriginalIdx {
+			anchorFilteredIdx = filteredIdx
+			break
+		}
+	}
+
+	// If the anchor got filtered out, the range shrinks down to just the highlighted item, and if the highlight is on
+	// a folded group's header then the range is just the anchor
+	highlightedFilteredIdx, found := impl.innerList.GetHighlightedItemIndex()
+	if !found {
+		highlightedFilteredIdx = anchorFilteredIdx
+	}
+	if anchorFilteredIdx < 0 {
+		anchorFilteredIdx = highlightedFilteredIdx
+	}
+	if anchorFilteredIdx < 0 {
+		return []int{}
+	}
+
+	rangeStart := helpers.GetMinInt(anchorFilteredIdx, highlightedFilteredIdx)
+	rangeEnd := helpers.GetMaxInt(anchorFilteredIdx, highlightedFilteredIdx)
+	result := make([]int, 0, rangeEnd-rangeStart+1)
+	for filteredIdx := rangeStart; filteredIdx <= rangeEnd && filteredIdx < len(filteredItemIndices); filteredIdx++ {
+		result = append(result, filteredItemIndices[filteredIdx])
+	}
+	return result
+}
+
+func (impl *implementation[T]) ToggleVisualRangeSelection() {
+	// Like vim, the range is toggled as a whole: if anything in it is unselected then everything gets selected
+	isAllSelected := true
+	for _, originalIdx := range impl.GetVisualRangeOriginalIndices() {
+		if !impl.selectedItemIndices[originalIdx] {
+			isAllSelected = false
+			break
+		}
+	}
+	impl.SetVisualRangeSelection(!isAllSelected)
+}
+
+func (impl *implementation[T]) SetVisualRangeSelection(isSelected bool) {
+	for _, originalIdx := range impl.GetVisualRangeOriginalIndices() {
+		impl.setItemSelection(originalIdx, isSelected)
+	}
+	impl.StopVisualMode()
+}
+
 func (impl *implementation[T]) Resize(width int, height int) {
 	impl.width = width
 	impl.height = height
@@ -176,3 +268,48 @@ func (impl *implementation[T]) setItemSelection(itemIdx int, isSelected bool) {
 		delete(impl.selectedItemIndices, itemIdx)
 	}
 }
+
+func (impl *implementation[T]) handleMouse(msg tea.MouseMsg) {
+	if msg.Type != tea.MouseLeft {
+		impl.innerList.Update(msg)
+		return
+	}
+
+	filteredItemIdx, found := impl.innerList.GetFilteredItemIndexAtLine(msg.Y)
+	if !found {
+		// Could be a group header, which the inner list knows how to handle
+		impl.innerList.Update(msg)
+		return
+	}
+
+	// The clicked item gets highlighted either way, and clicking its checkmark additionally toggles its selection
+	impl.innerList.Update(msg)
+	originalItemIdx := impl.innerList.GetFilteredItemIndices()[filteredItemIdx]
+	item := impl.items[originalItemIdx]
+	if msg.X < item.GetCheckmarkWidth() {
+		impl.setItemSelection(originalItemIdx, !item.IsSelected())
+	}
+}
+
+// refreshVisualRangeHighlights highlights every item in the visual range, and un-highlights the ones that have left it
+func (impl *implementation[T]) refreshVisualRangeHighlights() {
+	highlightedOriginalIdx := -1
+	if highlightedFilteredIdx, found := impl.innerList.GetHighlightedItemIndex(); found {
+		highlightedOriginalIdx = impl.innerList.GetFilteredItemIndices()[highlightedFilteredIdx]
+	}
+
+	newRangeIndices := make(map[int]bool, 0)
+	for _, originalIdx := range impl.GetVisualRangeOriginalIndices() {
+		newRangeIndices[originalIdx] = true
+	}
+
+	for originalIdx := range impl.visualRangeHighlightedIndices {
+		if !newRangeIndices[originalIdx] && originalIdx != highlightedOriginalIdx && originalIdx < len(impl.items) {
+			impl.items[originalIdx].SetHighlighted(false)
+		}
+	}
+	for originalIdx := range newRangeIndices {
+		impl.items[originalIdx].SetHighlighted(true)
+	}
+	impl.visualRangeHighlightedIndices = newRangeIndices
+}
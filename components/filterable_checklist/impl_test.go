@@ -0,0 +1,96 @@
+package filterable_checklist
+
+import (
+	"testing"
+)
+
+type testItem struct {
+	value         string
+	isHighlighted bool
+	isSelected    bool
+}
+
+func (item testItem) View() string {
+	return item.value
+}
+
+func (item *testItem) Resize(width int, height int) {
+}
+
+func (item testItem) GetWidth() int {
+	return len(item.value)
+}
+
+func (item testItem) GetHeight() int {
+	return 1
+}
+
+func (item testItem) GetValue() string {
+	return item.value
+}
+
+func (item testItem) GetNumLines() int {
+	return 1
+}
+
+func (item testItem) IsHighlighted() bool {
+	return item.isHighlighted
+}
+
+func (item *testItem) SetHighlighted(isHighlighted bool) {
+	item.isHighlighted = isHighlighted
+}
+
+func (item testItem) IsSelected() bool {
+	return item.isSelected
+}
+
+func (item *testItem) SetSelection(isSelected bool) {
+	item.isSelected = isSelected
+}
+
+func (item testItem) GetCheckmarkWidth() int {
+	return 0
+}
+
+func newTestChecklist(values ...string) (Component[*testItem], []*testItem) {
+	items := make([]*testItem, 0, len(values))
+	for _, value := range values {
+		items = append(items, &testItem{value: value, isHighlighted: false, isSelected: false})
+	}
+	checklist := New[*testItem]()
+	checklist.Resize(20, 10)
+	checklist.SetItems(items)
+	return checklist, items
+}
+
+func getHighlightedValues(items []*testItem) []string {
+	result := make([]string, 0)
+	for _, item := range items {
+		if item.IsHighlighted() {
+			result = append(result, item.GetValue())
+		}
+	}
+	return result
+}
+
+func TestVisualRangeFollowsTheHighlightWithoutRendering(t *testing.T) {
+	checklist, items := newTestChecklist("a", "b", "c", "d")
+	checklist.GetFilterableList().Scroll(1)
+	checklist.StartVisualMode()
+
+	checklist.GetFilterableList().Scroll(2)
+	if highlighted := getHighlightedValues(items); len(highlighted) != 3 || highlighted[0] != "b" || highlighted[2] != "d" {
+		t.Errorf("expected the visual range from b to d to be highlighted but got %v", highlighted)
+	}
+
+	checklist.GetFilterableList().Scroll(-1)
+	if highlighted := getHighlightedValues(items); len(highlighted) != 2 || highlighted[0] != "b" || highlighted[1] != "c" {
+		t.Errorf("expected the visual range to shrink to b & c but got %v", highlighted)
+	}
+
+	checklist.StopVisualMode()
+	if highlighted := getHighlightedValues(items); len(highlighted) != 1 || highlighted[0] != "c" {
+		t.Errorf("expected only the highlighted item to stay highlighted but got %v", highlighted)
+	}
+}
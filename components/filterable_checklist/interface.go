@@ -11,6 +11,7 @@ type Component[T filterable_checklist_item.Component] interface {
 
 	// Used for manipulations of the inner list (no need to reimplement all the functions)
 	// The items in the original list will match the items from GetItems
+	// Moving the highlight through it also moves the end of the visual range
 	GetFilterableList() filterable_list.Component[T]
 
 	SetItems(items []T)
@@ -21,12 +22,35 @@ type Component[T filterable_checklist_item.Component] interface {
 
 	ToggleHighlightedItemSelection()
 
+	// SetItemSelection sets the selection for the item at the given index within the current items list
+	SetItemSelection(originalIdx int, isSelected bool)
+
 	// SetHighlightedItemSelection sets the selection for the currently-highlighted item
 	SetHighlightedItemSelection(isSelected bool)
 
 	// SetAllViewableItemsSelection sets the selection for all items that are currently shown (i.e. matching the filter)
 	SetAllViewableItemsSelection(isSelected bool)
 
+	// SetHighlightedGroupSelection sets the selection for all shown items in the same group as the highlighted item
+	// If the list isn't grouped, this is the same as SetAllViewableItemsSelection
+	SetHighlightedGroupSelection(isSelected bool)
+
 	// SetAllItemsSelection sets the selection on ALL items in the list (whether they're viewable or not)
 	SetAllItemsSelection(isSelected bool)
+
+	// StartVisualMode anchors a range at the highlighted item, which then stretches to wherever the highlight moves
+	StartVisualMode()
+	StopVisualMode()
+	IsInVisualMode() bool
+
+	// GetVisualRangeOriginalIndices gets the indices within the current items list of the shown items in the visual
+	// range, in display order (empty if not in visual mode)
+	GetVisualRangeOriginalIndices() []int
+
+	// ToggleVisualRangeSelection selects every item in the visual range, or deselects them all if they're all already
+	// selected, and then leaves visual mode
+	ToggleVisualRangeSelection()
+
+	// SetVisualRangeSelection sets the selection for every item in the visual range, and then leaves visual mode
+	SetVisualRangeSelection(isSelected bool)
 }
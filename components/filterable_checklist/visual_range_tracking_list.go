@@ -0,0 +1,56 @@
+package filterable_checklist
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mieubrisse/cli-journal-go/components/filterable_checklist_item"
+	"github.com/mieubrisse/cli-journal-go/components/filterable_list"
+)
+
+// visualRangeTrackingList is the inner list as handed out by GetFilterableList, which catches the checklist's visual
+// range up whenever the list gets changed in a way that can move the highlight
+type visualRangeTrackingList[T filterable_checklist_item.Component] struct {
+	filterable_list.Component[T]
+
+	checklist *implementation[T]
+}
+
+func (list visualRangeTrackingList[T]) Update(msg tea.Msg) tea.Cmd {
+	cmd := list.Component.Update(msg)
+	list.checklist.refreshVisualRangeHighlights()
+	return cmd
+}
+
+func (list visualRangeTrackingList[T]) UpdateFilter(newFilter func(idx int, item T) bool) {
+	list.Component.UpdateFilter(newFilter)
+	list.checklist.refreshVisualRangeHighlights()
+}
+
+func (list visualRangeTrackingList[T]) SetOrdering(less func(a T, b T) bool) {
+	list.Component.SetOrdering(less)
+	list.checklist.refreshVisualRangeHighlights()
+}
+
+func (list visualRangeTrackingList[T]) SetGrouping(grouper func(item T) (string, string)) {
+	list.Component.SetGrouping(grouper)
+	list.checklist.refreshVisualRangeHighlights()
+}
+
+func (list visualRangeTrackingList[T]) ToggleHighlightedGroupFold() {
+	list.Component.ToggleHighlightedGroupFold()
+	list.checklist.refreshVisualRangeHighlights()
+}
+
+func (list visualRangeTrackingList[T]) UnfoldAllGroups() {
+	list.Component.UnfoldAllGroups()
+	list.checklist.refreshVisualRangeHighlights()
+}
+
+func (list visualRangeTrackingList[T]) Scroll(scrollOffset int) {
+	list.Component.Scroll(scrollOffset)
+	list.checklist.refreshVisualRangeHighlights()
+}
+
+func (list visualRangeTrackingList[T]) HighlightItem(filteredIdx int) {
+	list.Component.HighlightItem(filteredIdx)
+	list.checklist.refreshVisualRangeHighlights()
+}
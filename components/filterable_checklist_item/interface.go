@@ -9,4 +9,8 @@ type Component interface {
 
 	IsSelected() bool
 	SetSelection(isSelected bool)
+
+	// GetCheckmarkWidth gets how many cells on the left of the item are taken up by its checkmark, so that clicking
+	// there can toggle the selection (0 if the item isn't showing a checkmark)
+	GetCheckmarkWidth() int
 }
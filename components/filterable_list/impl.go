@@ -1,120 +1,120 @@
 package filterable_list
 
 import (
+	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mieubrisse/cli-journal-go/components/filterable_list_item"
+	"github.com/mieubrisse/cli-journal-go/global_styles"
 	"github.com/mieubrisse/cli-journal-go/helpers"
+	"sort"
 	"strings"
 )
 
+const (
+	unfoldedGroupMarker = "▾"
+	foldedGroupMarker   = "▸"
+)
+
+var groupHeaderStyle = lipgloss.NewStyle().
+	Foreground(global_styles.Peach).
+	Bold(true)
+var groupHeaderCountStyle = lipgloss.NewStyle().
+	Faint(true)
+var highlightedGroupHeaderStyle = lipgloss.NewStyle().
+	Background(global_styles.FocusedComponentBackgroundColor)
+
 /*
 Component for displaying a scrollable, filterable list of items
 */
 type implementation[T filterable_list_item.Component] struct {
 	unfilteredItems []T
 
+	// The indices of the items matching the filter within the unfiltered items list, in display order (including
+	// those inside folded groups)
+	matchingItemsOriginalIndices []int
+
 	// The indices of the filtered items within the unfiltered items list
+	// These are the items that are actually displayed (i.e. they match the filter and aren't in a folded group)
 	filteredItemsOriginalIndices []int
 
-	// The index of the highlighted item within the *filtered list*
+	// The index of the highlighted item within the *filtered list*, unless a folded group's header is highlighted
 	highlightedItemIdx int
 
+	// Whether the highlight is on the header of a folded group (whose items aren't displayed), and which group
+	isFoldedGroupHighlighted bool
+	highlightedGroupKey      string
+
+	// Ordering to display the filtered items in; nil means the order the items were given in
+	itemOrdering func(a T, b T) bool
+
+	// Gets the key and header text of the group an item belongs to; nil means no grouping
+	grouper func(item T) (string, string)
+
+	// Keys of the groups whose items are hidden
+	foldedGroupKeys map[string]bool
+
 	isFocused bool
 	width     int
 	height    int
 }
 
+// A row in the list display, which is either a group header or an item
+type displayRow struct {
+	isHeader bool
+
+	// Only set for item rows
+	filteredIdx int
+
+	// Only set for header rows
+	groupKey      string
+	groupHeader   string
+	numGroupItems int
+}
+
+// A place the highlight can rest: a displayed item, or the header of a folded group
+type cursorStop struct {
+	isHeader bool
+
+	// Only set for items
+	filteredIdx int
+
+	// Only set for headers
+	groupKey string
+}
+
 func New[T filterable_list_item.Component]() Component[T] {
 	return &implementation[T]{
 		unfilteredItems:              make([]T, 0),
+		matchingItemsOriginalIndices: make([]int, 0),
 		filteredItemsOriginalIndices: make([]int, 0),
 		highlightedItemIdx:           0,
+		isFoldedGroupHighlighted:     false,
+		highlightedGroupKey:          "",
+		itemOrdering:                 nil,
+		grouper:                      nil,
+		foldedGroupKeys:              make(map[string]bool, 0),
 		width:                        0,
 		height:                       0,
 	}
 }
 
 func (impl implementation[T]) View() string {
-	if len(impl.filteredItemsOriginalIndices) == 0 {
+	displayedLines, _ := impl.getDisplayedLines()
+	if len(displayedLines) == 0 {
 		return ""
 	}
 
-	/*
-		baseLineStyle := lipgloss.NewStyle().
-			Width(impl.width)
-
-	*/
-
-	// As aesthetic choices, when there are more item lines than display lines:
-	// 1. We want the entire list to scroll around the cursor if it's in the center of the screen, rather than
-	//    the user needing to scroll to top or bottom to get the list to move. This helps the user see more
-	//    relevant information at once
-	// 2. When the cursor is near the top or bottom of the list, scroll the cursor rather than the entire list
-	//    so that we don't get blank space
-	// The easiest way to accomplish this is to calculate the range of acceptable first-line indexes of the view,
-	//   which will range from [0, num_items - num_display_lines], and when the user is in the middle of the list
-	//   the view will have the cursor line in the center
-	halfHeight := impl.height / 2
-
-	// Ensure that, when near the bottom of the list, the cursor is no longer centered and scrolls to the bottom
-	firstDisplayedLineIdxInclusive := helpers.GetMinInt(
-		impl.highlightedItemIdx-halfHeight,
-		len(impl.filteredItemsOriginalIndices)-impl.height,
-	)
-
-	// Ensure that, when near the top of the list, the cursor is no longer centered and scrolls to the top
-	firstDisplayedLineIdxInclusive = helpers.GetMaxInt(
-		firstDisplayedLineIdxInclusive,
-		0,
-	)
-
-	lastDisplayedLineIdxExclusive := helpers.GetMinInt(
-		len(impl.filteredItemsOriginalIndices),
-		firstDisplayedLineIdxInclusive+impl.height,
-	)
-
-	if firstDisplayedLineIdxInclusive == lastDisplayedLineIdxExclusive {
-		return ""
-	}
-
-	displayedItems := impl.filteredItemsOriginalIndices[firstDisplayedLineIdxInclusive:lastDisplayedLineIdxExclusive]
-
-	// viewableLinesHighlightedItemIdx := impl.highlightedItemIdx - firstDisplayedLineIdxInclusive
-
-	resultLines := []string{}
-	for _, originalItemIdx := range displayedItems {
-		item := impl.unfilteredItems[originalItemIdx]
-
-		/*
-			lineStyle := baseLineStyle
-			if impl.isFocused && idx == viewableLinesHighlightedItemIdx {
-				// NOTE: this _may_ mess up the styling of the inner stuff
-				lineStyle = baseLineStyle.Copy().Background(global_styles.FocusedComponentBackgroundColor).Bold(true)
-			}
-			renderedLine := lineStyle.Render(item.View())
-
-		*/
-		renderedLine := item.View()
-
-		resultLines = append(resultLines, renderedLine)
-	}
-
-	result := strings.Join(resultLines, "\n")
-
-	// TODO truncating long lines by printable char
-
-	return lipgloss.NewStyle().
-		Width(impl.width).
-		Height(impl.height).
-		MaxWidth(impl.width).
-		MaxHeight(impl.height).
-		Render(result)
+	// Items are responsible for fitting their lines to the width they were given
+	return helpers.FitToSize(strings.Join(displayedLines, "\n"), impl.width, impl.height)
 }
 
 func (impl *implementation[T]) Update(msg tea.Msg) tea.Cmd {
-	// Do nothing on non-Keymsgs
-	switch msg.(type) {
+	// Mouse events get routed to us by position, so we handle them whether or not we're focused
+	switch castedMsg := msg.(type) {
+	case tea.MouseMsg:
+		impl.handleMouse(castedMsg)
+		return nil
 	case tea.KeyMsg:
 		// Proceed to rest of function
 	default:
@@ -136,48 +136,82 @@ func (impl *implementation[T]) Update(msg tea.Msg) tea.Cmd {
 		impl.Scroll(impl.height)
 	case "K":
 		impl.Scroll(-impl.height)
+	case "z":
+		impl.ToggleHighlightedGroupFold()
+	case "Z":
+		impl.UnfoldAllGroups()
 	}
 	return nil
 }
 
 func (impl *implementation[T]) UpdateFilter(newFilter func(idx int, item T) bool) {
-	// This is a hack to indicate "the filtered list was empty, so there's no highlighted item original idx"
-	oldHighlightedItemOriginalIdx := -1
-
-	// If there are items being displayed, de-highlight the current item (if there are any)
-	if len(impl.filteredItemsOriginalIndices) > 0 {
-		oldHighlightedItemOriginalIdx = impl.filteredItemsOriginalIndices[impl.highlightedItemIdx]
-		oldHighlightedItem := impl.unfilteredItems[oldHighlightedItemOriginalIdx]
-		oldHighlightedItem.SetHighlighted(false)
+	newMatchingItemOriginalIndices := []int{}
+	for idx, item := range impl.unfilteredItems {
+		if newFilter(idx, item) {
+			newMatchingItemOriginalIndices = append(newMatchingItemOriginalIndices, idx)
+		}
 	}
 
-	// By default, assume that the highlighted item in the pre-filter list doesn't exist in the
-	// post-filter list (but we'll fix this below if the assumption is false)
-	newHighlightedItemIdx := 0
+	// TODO maybe remove the highlight-preserving??? Seems confusing
+	impl.replaceMatchingItemsPreservingHighlight(newMatchingItemOriginalIndices)
+}
 
-	newFilteredItemOriginalIndices := []int{}
-	for idx, item := range impl.unfilteredItems {
-		if newFilter(idx, item) {
-			newFilteredItemOriginalIndices = append(newFilteredItemOriginalIndices, idx)
+func (impl *implementation[T]) SetOrdering(less func(a T, b T) bool) {
+	impl.itemOrdering = less
+	impl.replaceMatchingItemsPreservingHighlight(impl.copyMatchingItemIndices())
+}
 
-			// TODO maybe remove the highlight-preserving??? Seems confusing
-			// If the previously-highlighted item also exists in the post-filter list,
-			// leave it highlighted
-			if idx == oldHighlightedItemOriginalIdx {
-				newHighlightedItemIdx = len(newFilteredItemOriginalIndices) - 1
-			}
+func (impl *implementation[T]) SetGrouping(grouper func(item T) (string, string)) {
+	impl.grouper = grouper
+	impl.foldedGroupKeys = make(map[string]bool, 0)
+	impl.replaceMatchingItemsPreservingHighlight(impl.copyMatchingItemIndices())
+}
+
+func (impl *implementation[T]) ToggleHighlightedGroupFold() {
+	if impl.grouper == nil {
+		return
+	}
+
+	groupKey := impl.highlightedGroupKey
+	if !impl.isFoldedGroupHighlighted {
+		if len(impl.filteredItemsOriginalIndices) == 0 {
+			return
 		}
+		highlightedItemOriginalIdx := impl.filteredItemsOriginalIndices[impl.highlightedItemIdx]
+		groupKey, _ = impl.grouper(impl.unfilteredItems[highlightedItemOriginalIdx])
+	}
+
+	// Folding a group moves the highlight onto its header, and unfolding it from there moves it onto its first item
+	impl.foldedGroupKeys[groupKey] = !impl.foldedGroupKeys[groupKey]
+	impl.replaceMatchingItemsPreservingHighlight(impl.copyMatchingItemIndices())
+}
+
+func (impl *implementation[T]) UnfoldAllGroups() {
+	impl.foldedGroupKeys = make(map[string]bool, 0)
+	impl.replaceMatchingItemsPreservingHighlight(impl.copyMatchingItemIndices())
+}
+
+func (impl implementation[T]) GetHighlightedGroupItemIndices() []int {
+	if len(impl.filteredItemsOriginalIndices) == 0 || impl.isFoldedGroupHighlighted {
+		return []int{}
+	}
+
+	// Without grouping, the whole list is one big group
+	if impl.grouper == nil {
+		return impl.filteredItemsOriginalIndices
 	}
 
-	impl.filteredItemsOriginalIndices = newFilteredItemOriginalIndices
-	impl.highlightedItemIdx = newHighlightedItemIdx
+	highlightedItemOriginalIdx := impl.filteredItemsOriginalIndices[impl.highlightedItemIdx]
+	highlightedGroupKey, _ := impl.grouper(impl.unfilteredItems[highlightedItemOriginalIdx])
 
-	// Highlight the new item (if possible)
-	if len(impl.filteredItemsOriginalIndices) > 0 {
-		originalIdx := impl.filteredItemsOriginalIndices[impl.highlightedItemIdx]
-		item := impl.unfilteredItems[originalIdx]
-		item.SetHighlighted(true)
+	result := make([]int, 0)
+	for _, originalIdx := range impl.filteredItemsOriginalIndices {
+		groupKey, _ := impl.grouper(impl.unfilteredItems[originalIdx])
+		if groupKey == highlightedGroupKey {
+			result = append(result, originalIdx)
+		}
 	}
+	return result
 }
 
 func (impl *implementation[T]) SetItems(items []T) {
@@ -186,66 +220,77 @@ func (impl *implementation[T]) SetItems(items []T) {
 		filteredIndices = append(filteredIndices, idx)
 	}
 
+	for _, item := range items {
+		item.Resize(impl.width, impl.height)
+	}
+
 	impl.unfilteredItems = items
-	impl.filteredItemsOriginalIndices = filteredIndices
+	impl.matchingItemsOriginalIndices = impl.sortByOrderingAndGroup(filteredIndices)
+	impl.filteredItemsOriginalIndices = impl.getUnfoldedItemIndices(impl.matchingItemsOriginalIndices)
 	impl.highlightedItemIdx = 0
+	impl.isFoldedGroupHighlighted = false
+	impl.highlightedGroupKey = ""
 
-	if len(impl.filteredItemsOriginalIndices) > 0 {
-		highlightedItemOriginalIdx := impl.filteredItemsOriginalIndices[impl.highlightedItemIdx]
-		item := impl.unfilteredItems[highlightedItemOriginalIdx]
-		item.SetHighlighted(true)
+	if stops := impl.getCursorStops(); len(stops) > 0 {
+		impl.highlightStop(stops[0])
 	}
 }
 
-// Scrolls the highlighted selection down or up by the specified number of items, with safeguards to
-// prevent scrolling off the ends of the list
+// Scrolls the highlight down or up by the specified number of places, with safeguards to prevent scrolling off the
+// ends of the list
 func (impl *implementation[T]) Scroll(scrollOffset int) {
-	newHighlightedItemIdx := impl.highlightedItemIdx + scrollOffset
-	if newHighlightedItemIdx < 0 {
-		newHighlightedItemIdx = 0
-	}
-	if newHighlightedItemIdx >= len(impl.filteredItemsOriginalIndices) {
-		newHighlightedItemIdx = len(impl.filteredItemsOriginalIndices) - 1
-	}
-
-	if newHighlightedItemIdx == impl.highlightedItemIdx {
+	stops := impl.getCursorStops()
+	if len(stops) == 0 {
 		return
 	}
 
-	// De-highlight the previous item
-	oldHighlightOriginalIdx := impl.filteredItemsOriginalIndices[impl.highlightedItemIdx]
-	oldItem := impl.unfilteredItems[oldHighlightOriginalIdx]
-	oldItem.SetHighlighted(false)
-
-	// Highlight the new item
-	newHighlightOriginalIdx := impl.filteredItemsOriginalIndices[newHighlightedItemIdx]
-	newItem := impl.unfilteredItems[newHighlightOriginalIdx]
-	newItem.SetHighlighted(true)
+	newStopIdx := impl.getHighlightedStopIndex(stops) + scrollOffset
+	newStopIdx = helpers.GetMaxInt(0, helpers.GetMinInt(newStopIdx, len(stops)-1))
+	impl.highlightStop(stops[newStopIdx])
+}
 
-	impl.highlightedItemIdx = newHighlightedItemIdx
+func (impl *implementation[T]) HighlightItem(filteredIdx int) {
+	if filteredIdx < 0 || filteredIdx >= len(impl.filteredItemsOriginalIndices) {
+		return
+	}
+	impl.highlightStop(cursorStop{isHeader: false, filteredIdx: filteredIdx, groupKey: ""})
 }
 
 func (impl implementation[T]) GetItems() []T {
 	return impl.unfilteredItems
 }
 
+func (impl implementation[T]) GetFilteredItemIndexAtLine(lineIdx int) (int, bool) {
+	_, displayedRows := impl.getDisplayedLines()
+	if lineIdx < 0 || lineIdx >= len(displayedRows) {
+		return 0, false
+	}
+	row := displayedRows[lineIdx]
+	if row.isHeader {
+		return 0, false
+	}
+	return row.filteredIdx, true
+}
+
 // Gets the indices (within the original list) of the items currently being displayed
 func (impl implementation[T]) GetFilteredItemIndices() []int {
 	return impl.filteredItemsOriginalIndices
 }
 
-// GetHighlightedItemIndex returns the index *within the filtered list* of the highlighted item
-func (impl implementation[T]) GetHighlightedItemIndex() int {
-	return impl.highlightedItemIdx
+func (impl implementation[T]) GetHighlightedItemIndex() (int, bool) {
+	if len(impl.filteredItemsOriginalIndices) == 0 || impl.isFoldedGroupHighlighted {
+		return 0, false
+	}
+	return impl.highlightedItemIdx, true
 }
 
 func (impl *implementation[T]) Resize(width int, height int) {
 	impl.width = width
 	impl.height = height
 
+	// Items can be as tall as they like (e.g. if they wrap), up to the height of the list
 	for _, item := range impl.unfilteredItems {
-		// TODO Allow items to wrap (but requires a whole viewing framework)
-		item.Resize(width, 1)
+		item.Resize(width, height)
 	}
 }
 
@@ -270,3 +315,361 @@ func (impl *implementation[T]) Blur() tea.Cmd {
 func (impl implementation[T]) Focused() bool {
 	return impl.isFocused
 }
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// replaceMatchingItemsPreservingHighlight swaps in the new set of items matching the filter (ordering and grouping
+// them), keeping the highlight where it was if that's still displayed
+// If the highlighted item has been folded away the highlight moves onto its group's header, and if a highlighted
+// header's group has been unfolded the highlight moves onto the group's first item.
+func (impl *implementation[T]) replaceMatchingItemsPreservingHighlight(newMatchingItemOriginalIndices []int) {
+	// This is a hack to indicate "there's no highlighted item, so there's no highlighted item original idx"
+	oldHighlightedItemOriginalIdx := -1
+	oldHighlightedGroupKey := impl.highlightedGroupKey
+	isOldHighlightOnHeader := impl.isFoldedGroupHighlighted
+
+	// If an item is highlighted, de-highlight it
+	if highlightedIdx, found := impl.GetHighlightedItemIndex(); found {
+		oldHighlightedItemOriginalIdx = impl.filteredItemsOriginalIndices[highlightedIdx]
+		impl.unfilteredItems[oldHighlightedItemOriginalIdx].SetHighlighted(false)
+	}
+
+	impl.matchingItemsOriginalIndices = impl.sortByOrderingAndGroup(newMatchingItemOriginalIndices)
+	impl.filteredItemsOriginalIndices = impl.getUnfoldedItemIndices(impl.matchingItemsOriginalIndices)
+	impl.highlightedItemIdx = 0
+	impl.isFoldedGroupHighlighted = false
+	impl.highlightedGroupKey = ""
+
+	stops := impl.getCursorStops()
+	if len(stops) == 0 {
+		return
+	}
+
+	// The highlighted item stays highlighted if it's still displayed
+	for _, stop := range stops {
+		if !stop.isHeader && impl.filteredItemsOriginalIndices[stop.filteredIdx] == oldHighlightedItemOriginalIdx {
+			impl.highlightStop(stop)
+			return
+		}
+	}
+
+	// If the highlight was on a group that's been folded or unfolded, it goes to the first place in the group
+	isOldItemFoldedAway := false
+	if oldHighlightedItemOriginalIdx >= 0 && impl.isInFoldedGroup(oldHighlightedItemOriginalIdx) {
+		for _, originalIdx := range impl.matchingItemsOriginalIndices {
+			if originalIdx == oldHighlightedItemOriginalIdx {
+				isOldItemFoldedAway = true
+				oldHighlightedGroupKey, _ = impl.grouper(impl.unfilteredItems[originalIdx])
+				break
+			}
+		}
+	}
+	if isOldHighlightOnHeader || isOldItemFoldedAway {
+		for _, stop := range stops {
+			if impl.getStopGroupKey(stop) == oldHighlightedGroupKey {
+				impl.highlightStop(stop)
+				return
+			}
+		}
+	}
+
+	// Otherwise, what was highlighted in the old list doesn't exist in the new list
+	impl.highlightStop(stops[0])
+}
+
+// Copy so that we don't reorder the slice out from under anyone who got it earlier
+func (impl implementation[T]) copyMatchingItemIndices() []int {
+	result := make([]int, len(impl.matchingItemsOriginalIndices))
+	copy(result, impl.matchingItemsOriginalIndices)
+	return result
+}
+
+// sortByOrderingAndGroup sorts the given original indices in-place according to the item ordering (if any), and then
+// gathers items of the same group together
+// Groups are displayed in the order that their first item appears in the ordering
+func (impl implementation[T]) sortByOrderingAndGroup(originalIndices []int) []int {
+	if impl.itemOrdering == nil {
+		// Without an ordering, display items in the order we received them
+		sort.Ints(originalIndices)
+	} else {
+		sort.SliceStable(originalIndices, func(i, j int) bool {
+			return impl.itemOrdering(
+				impl.unfilteredItems[originalIndices[i]],
+				impl.unfilteredItems[originalIndices[j]],
+			)
+		})
+	}
+
+	if impl.grouper == nil {
+		return originalIndices
+	}
+
+	groupRanks := map[string]int{}
+	for _, originalIdx := range originalIndices {
+		groupKey, _ := impl.grouper(impl.unfilteredItems[originalIdx])
+		if _, found := groupRanks[groupKey]; !found {
+			groupRanks[groupKey] = len(groupRanks)
+		}
+	}
+	sort.SliceStable(originalIndices, func(i, j int) bool {
+		groupKeyI, _ := impl.grouper(impl.unfilteredItems[originalIndices[i]])
+		groupKeyJ, _ := impl.grouper(impl.unfilteredItems[originalIndices[j]])
+		return groupRanks[groupKeyI] < groupRanks[groupKeyJ]
+	})
+	return originalIndices
+}
+
+func (impl implementation[T]) getUnfoldedItemIndices(originalIndices []int) []int {
+	result := make([]int, 0, len(originalIndices))
+	for _, originalIdx := range originalIndices {
+		if !impl.isInFoldedGroup(originalIdx) {
+			result = append(result, originalIdx)
+		}
+	}
+	return result
+}
+
+func (impl implementation[T]) isInFoldedGroup(originalIdx int) bool {
+	if impl.grouper == nil {
+		return false
+	}
+	groupKey, _ := impl.grouper(impl.unfilteredItems[originalIdx])
+	return impl.foldedGroupKeys[groupKey]
+}
+
+// getDisplayedLines gets the lines that fit in the list's display space, along with the row that each line is part of
+func (impl implementation[T]) getDisplayedLines() ([]string, []displayRow) {
+	rows := impl.getDisplayRows()
+	if len(rows) == 0 {
+		return []string{}, []displayRow{}
+	}
+
+	// As aesthetic choices, when there are more item lines than display lines:
+	// 1. We want the entire list to scroll around the cursor if it's in the center of the screen, rather than
+	//    the user needing to scroll to top or bottom to get the list to move. This helps the user see more
+	//    relevant information at once
+	// 2. When the cursor is near the top or bottom of the list, scroll the cursor rather than the entire list
+	//    so that we don't get blank space
+	// The easiest way to accomplish this is to calculate the range of acceptable first-line indexes of the view,
+	//   which will range from [0, num_lines - num_display_lines], and when the user is in the middle of the list
+	//   the view will have the cursor line in the center
+	// Items can take up multiple lines, so all the calculations here are in rendered lines rather than items
+	// Group header rows take up lines too, and the cursor lands on those of folded groups
+	// Only the rows in view get rendered, so the lines are counted using the items' line counts instead
+	highlightedItemFirstLineIdx := 0
+	highlightedItemHeight := 0
+	rowFirstLineIndices := make([]int, 0, len(rows))
+	rowHeights := make([]int, 0, len(rows))
+	numLines := 0
+	for _, row := range rows {
+		rowHeight := 1
+		isHighlighted := impl.isFoldedGroupHighlighted && row.isHeader && row.groupKey == impl.highlightedGroupKey
+		if !row.isHeader {
+			originalItemIdx := impl.filteredItemsOriginalIndices[row.filteredIdx]
+			rowHeight = impl.unfilteredItems[originalItemIdx].GetNumLines()
+			isHighlighted = !impl.isFoldedGroupHighlighted && row.filteredIdx == impl.highlightedItemIdx
+		}
+		if isHighlighted {
+			highlightedItemFirstLineIdx = numLines
+			highlightedItemHeight = rowHeight
+		}
+
+		rowFirstLineIndices = append(rowFirstLineIndices, numLines)
+		rowHeights = append(rowHeights, rowHeight)
+		numLines += rowHeight
+	}
+
+	// Center the entire highlighted item (or as much of it as will fit, starting from its first line)
+	linesAroundHighlightedItem := helpers.GetMaxInt(0, impl.height-highlightedItemHeight)
+	halfLinesAroundHighlightedItem := linesAroundHighlightedItem / 2
+
+	// Ensure that, when near the bottom of the list, the cursor is no longer centered and scrolls to the bottom
+	firstDisplayedLineIdxInclusive := helpers.GetMinInt(
+		highlightedItemFirstLineIdx-halfLinesAroundHighlightedItem,
+		numLines-impl.height,
+	)
+
+	// Ensure that, when near the top of the list, the cursor is no longer centered and scrolls to the top
+	firstDisplayedLineIdxInclusive = helpers.GetMaxInt(
+		firstDisplayedLineIdxInclusive,
+		0,
+	)
+
+	lastDisplayedLineIdxExclusive := helpers.GetMinInt(
+		numLines,
+		firstDisplayedLineIdxInclusive+impl.height,
+	)
+
+	if firstDisplayedLineIdxInclusive >= lastDisplayedLineIdxExclusive {
+		return []string{}, []displayRow{}
+	}
+
+	displayedLines := make([]string, 0, lastDisplayedLineIdxExclusive-firstDisplayedLineIdxInclusive)
+	displayedLineRows := make([]displayRow, 0, lastDisplayedLineIdxExclusive-firstDisplayedLineIdxInclusive)
+	for rowIdx, row := range rows {
+		rowFirstLineIdx := rowFirstLineIndices[rowIdx]
+		rowHeight := rowHeights[rowIdx]
+		if rowFirstLineIdx+rowHeight <= firstDisplayedLineIdxInclusive {
+			continue
+		}
+		if rowFirstLineIdx >= lastDisplayedLineIdxExclusive {
+			break
+		}
+
+		var rowLines []string
+		if row.isHeader {
+			rowLines = []string{impl.renderGroupHeader(row)}
+		} else {
+			originalItemIdx := impl.filteredItemsOriginalIndices[row.filteredIdx]
+			rowLines = strings.Split(impl.unfilteredItems[originalItemIdx].View(), "\n")
+		}
+
+		// The row's lines are kept to its line count, so that the rows below are where they were counted to be
+		for lineIdx := 0; lineIdx < rowHeight; lineIdx++ {
+			absoluteLineIdx := rowFirstLineIdx + lineIdx
+			if absoluteLineIdx < firstDisplayedLineIdxInclusive || absoluteLineIdx >= lastDisplayedLineIdxExclusive {
+				continue
+			}
+			line := ""
+			if lineIdx < len(rowLines) {
+				line = rowLines[lineIdx]
+			}
+			displayedLines = append(displayedLines, line)
+			displayedLineRows = append(displayedLineRows, row)
+		}
+	}
+	return displayedLines, displayedLineRows
+}
+
+// handleMouse scrolls on the mouse wheel, and on click highlights the clicked item (or folds/unfolds the clicked group)
+// The mouse coordinates are expected to be relative to the top-left corner of the list
+func (impl *implementation[T]) handleMouse(msg tea.MouseMsg) {
+	switch msg.Type {
+	case tea.MouseWheelUp:
+		impl.Scroll(-1)
+	case tea.MouseWheelDown:
+		impl.Scroll(1)
+	case tea.MouseLeft:
+		_, displayedRows := impl.getDisplayedLines()
+		if msg.Y < 0 || msg.Y >= len(displayedRows) {
+			return
+		}
+
+		clickedRow := displayedRows[msg.Y]
+		if clickedRow.isHeader {
+			impl.foldedGroupKeys[clickedRow.groupKey] = !impl.foldedGroupKeys[clickedRow.groupKey]
+			impl.replaceMatchingItemsPreservingHighlight(impl.copyMatchingItemIndices())
+			return
+		}
+		impl.HighlightItem(clickedRow.filteredIdx)
+	}
+}
+
+// getDisplayRows gets the rows to display, with a header row before each group when grouping is enabled
+func (impl implementation[T]) getDisplayRows() []displayRow {
+	result := make([]displayRow, 0, len(impl.matchingItemsOriginalIndices))
+	if impl.grouper == nil {
+		for filteredIdx := range impl.filteredItemsOriginalIndices {
+			result = append(result, displayRow{isHeader: false, filteredIdx: filteredIdx})
+		}
+		return result
+	}
+
+	// The count in each header includes items hidden by folding, so we count everything that matched the filter
+	numItemsByGroupKey := map[string]int{}
+	for _, originalIdx := range impl.matchingItemsOriginalIndices {
+		groupKey, _ := impl.grouper(impl.unfilteredItems[originalIdx])
+		numItemsByGroupKey[groupKey]++
+	}
+
+	filteredIdx := 0
+	lastGroupKey := ""
+	for matchingIdx, originalIdx := range impl.matchingItemsOriginalIndices {
+		groupKey, groupHeader := impl.grouper(impl.unfilteredItems[originalIdx])
+		if matchingIdx == 0 || groupKey != lastGroupKey {
+			result = append(result, displayRow{
+				isHeader:      true,
+				groupKey:      groupKey,
+				groupHeader:   groupHeader,
+				numGroupItems: numItemsByGroupKey[groupKey],
+			})
+			lastGroupKey = groupKey
+		}
+
+		if impl.foldedGroupKeys[groupKey] {
+			continue
+		}
+		result = append(result, displayRow{isHeader: false, filteredIdx: filteredIdx})
+		filteredIdx++
+	}
+	return result
+}
+
+// getCursorStops gets the places the highlight can rest, in display order
+func (impl implementation[T]) getCursorStops() []cursorStop {
+	result := make([]cursorStop, 0, len(impl.filteredItemsOriginalIndices))
+	for _, row := range impl.getDisplayRows() {
+		if !row.isHeader {
+			result = append(result, cursorStop{isHeader: false, filteredIdx: row.filteredIdx, groupKey: ""})
+			continue
+		}
+		if impl.foldedGroupKeys[row.groupKey] {
+			result = append(result, cursorStop{isHeader: true, filteredIdx: 0, groupKey: row.groupKey})
+		}
+	}
+	return result
+}
+
+// getHighlightedStopIndex gets where the highlight is among the stops, defaulting to the first
+func (impl implementation[T]) getHighlightedStopIndex(stops []cursorStop) int {
+	for stopIdx, stop := range stops {
+		if stop.isHeader && impl.isFoldedGroupHighlighted && stop.groupKey == impl.highlightedGroupKey {
+			return stopIdx
+		}
+		if !stop.isHeader && !impl.isFoldedGroupHighlighted && stop.filteredIdx == impl.highlightedItemIdx {
+			return stopIdx
+		}
+	}
+	return 0
+}
+
+// highlightStop moves the highlight to the given stop, de-highlighting the previously-highlighted item (if any)
+func (impl *implementation[T]) highlightStop(stop cursorStop) {
+	if highlightedIdx, found := impl.GetHighlightedItemIndex(); found {
+		impl.unfilteredItems[impl.filteredItemsOriginalIndices[highlightedIdx]].SetHighlighted(false)
+	}
+
+	impl.isFoldedGroupHighlighted = stop.isHeader
+	impl.highlightedGroupKey = stop.groupKey
+	if stop.isHeader {
+		return
+	}
+	impl.highlightedItemIdx = stop.filteredIdx
+	impl.unfilteredItems[impl.filteredItemsOriginalIndices[stop.filteredIdx]].SetHighlighted(true)
+}
+
+func (impl implementation[T]) getStopGroupKey(stop cursorStop) string {
+	if stop.isHeader {
+		return stop.groupKey
+	}
+	groupKey, _ := impl.grouper(impl.unfilteredItems[impl.filteredItemsOriginalIndices[stop.filteredIdx]])
+	return groupKey
+}
+
+func (impl implementation[T]) renderGroupHeader(row displayRow) string {
+	marker := unfoldedGroupMarker
+	if impl.foldedGroupKeys[row.groupKey] {
+		marker = foldedGroupMarker
+	}
+
+	headerStyle, countStyle := groupHeaderStyle, groupHeaderCountStyle
+	if impl.isFoldedGroupHighlighted && row.groupKey == impl.highlightedGroupKey {
+		headerStyle = headerStyle.Copy().Inherit(highlightedGroupHeaderStyle)
+		countStyle = countStyle.Copy().Inherit(highlightedGroupHeaderStyle)
+	}
+	return headerStyle.Render(marker+" "+row.groupHeader) +
+		countStyle.Render(fmt.Sprintf(" (%d)", row.numGroupItems))
+}
@@ -0,0 +1,157 @@
+package filterable_list
+
+import (
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/components/filterable_list_item"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestList makes a list of the given values, grouped by their first letter
+func newTestList(values ...string) Component[filterable_list_item.Component] {
+	items := make([]filterable_list_item.Component, 0, len(values))
+	for _, value := range values {
+		items = append(items, filterable_list_item.New(value))
+	}
+	list := New[filterable_list_item.Component]()
+	list.Resize(20, 10)
+	list.SetItems(items)
+	list.SetGrouping(func(item filterable_list_item.Component) (string, string) {
+		groupKey := item.GetValue()[:1]
+		return groupKey, strings.ToUpper(groupKey)
+	})
+	return list
+}
+
+func getHighlightedValue(t *testing.T, list Component[filterable_list_item.Component]) string {
+	highlightedIdx, found := list.GetHighlightedItemIndex()
+	if !found {
+		t.Fatalf("expected an item to be highlighted, but none is")
+	}
+	return list.GetItems()[list.GetFilteredItemIndices()[highlightedIdx]].GetValue()
+}
+
+func getDisplayedValues(list Component[filterable_list_item.Component]) []string {
+	result := make([]string, 0)
+	for _, originalIdx := range list.GetFilteredItemIndices() {
+		result = append(result, list.GetItems()[originalIdx].GetValue())
+	}
+	return result
+}
+
+func TestItemsAreSortedThenGatheredIntoGroups(t *testing.T) {
+	list := newTestList("b2", "a2", "b1", "a1")
+	list.SetOrdering(func(a filterable_list_item.Component, b filterable_list_item.Component) bool {
+		return a.GetValue() > b.GetValue()
+	})
+
+	// The groups come in the order of their first item, and keep the ordering within them
+	expected := []string{"b2", "b1", "a2", "a1"}
+	if actual := getDisplayedValues(list); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected the items in order %v but got %v", expected, actual)
+	}
+
+	lines := strings.Split(helpers.ClearFormatting(list.View()), "\n")
+	if !strings.HasPrefix(lines[0], unfoldedGroupMarker+" B (2)") || !strings.HasPrefix(lines[3], unfoldedGroupMarker+" A (2)") {
+		t.Errorf("expected a header before each group, but got:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestScrollingStopsAtTheEndsOfTheList(t *testing.T) {
+	list := newTestList("a1", "a2", "b1")
+
+	list.Scroll(-1)
+	if value := getHighlightedValue(t, list); value != "a1" {
+		t.Errorf("expected scrolling up from the top to stay on the first item, but '%s' is highlighted", value)
+	}
+	list.Scroll(10)
+	if value := getHighlightedValue(t, list); value != "b1" {
+		t.Errorf("expected scrolling past the bottom to stop on the last item, but '%s' is highlighted", value)
+	}
+	list.HighlightItem(1)
+	if value := getHighlightedValue(t, list); value != "a2" {
+		t.Errorf("expected the second item to be highlighted, but '%s' is", value)
+	}
+}
+
+func TestFoldedGroupsCanBeUnfoldedFromTheirHeaders(t *testing.T) {
+	list := newTestList("a1", "a2", "b1")
+	list.Scroll(1)
+
+	// Folding keeps the highlight on the group, so the same key unfolds it again
+	list.ToggleHighlightedGroupFold()
+	if _, found := list.GetHighlightedItemIndex(); found {
+		t.Errorf("expected the folded group's header to be highlighted rather than an item")
+	}
+	if actual := getDisplayedValues(list); !reflect.DeepEqual(actual, []string{"b1"}) {
+		t.Errorf("expected only the unfolded group's item to be displayed, but got %v", actual)
+	}
+	list.Scroll(1)
+	if value := getHighlightedValue(t, list); value != "b1" {
+		t.Errorf("expected scrolling off the folded header to land on the next group's item, but '%s' is highlighted", value)
+	}
+	list.Scroll(-1)
+	list.ToggleHighlightedGroupFold()
+	if value := getHighlightedValue(t, list); value != "a1" {
+		t.Errorf("expected unfolding from the header to highlight the group's first item, but '%s' is highlighted", value)
+	}
+
+	// With every group folded, the headers are still displayed and can still be unfolded
+	list.ToggleHighlightedGroupFold()
+	list.Scroll(1)
+	list.ToggleHighlightedGroupFold()
+	if actual := getDisplayedValues(list); len(actual) != 0 {
+		t.Fatalf("expected no items to be displayed with every group folded, but got %v", actual)
+	}
+	view := helpers.ClearFormatting(list.View())
+	if !strings.Contains(view, foldedGroupMarker+" A (2)") || !strings.Contains(view, foldedGroupMarker+" B (1)") {
+		t.Errorf("expected both folded headers to be displayed, but got:\n%s", view)
+	}
+	list.ToggleHighlightedGroupFold()
+	if value := getHighlightedValue(t, list); value != "b1" {
+		t.Errorf("expected the highlighted folded group to unfold, but '%s' is highlighted", value)
+	}
+}
+
+// countingItem is a two-line item that counts how many times it's been rendered
+type countingItem struct {
+	filterable_list_item.Component
+
+	numViews *int
+}
+
+func (item countingItem) View() string {
+	*item.numViews++
+	return item.Component.View() + "\n" + item.GetValue() + " continued"
+}
+
+func (item countingItem) GetNumLines() int {
+	return 2
+}
+
+func TestOnlyItemsInViewAreRendered(t *testing.T) {
+	numViews := 0
+	items := make([]countingItem, 0)
+	for idx := 0; idx < 100; idx++ {
+		items = append(items, countingItem{Component: filterable_list_item.New(fmt.Sprintf("item%d", idx)), numViews: &numViews})
+	}
+	list := New[countingItem]()
+	list.Resize(20, 6)
+	list.SetItems(items)
+	list.Scroll(50)
+
+	lines := strings.Split(helpers.ClearFormatting(list.View()), "\n")
+	if numViews > 4 {
+		t.Errorf("expected only the items in view to be rendered, but %d were", numViews)
+	}
+
+	// The highlighted item is centered, with the items around it cut off at the edges of the view
+	expectedLines := []string{"item49", "item49 continued", "item50", "item50 continued", "item51", "item51 continued"}
+	for idx, expectedLine := range expectedLines {
+		if strings.TrimSpace(lines[idx]) != expectedLine {
+			t.Fatalf("expected the lines %v but got %v", expectedLines, lines)
+		}
+	}
+}
@@ -9,9 +9,44 @@ type Component[T filterable_list_item.Component] interface {
 	components.InteractiveComponent
 
 	UpdateFilter(newFilter func(idx int, item T) bool)
+
+	// SetOrdering sets the order that the filtered items are displayed in, keeping the highlighted item highlighted
+	// A nil ordering displays the items in the order they were given to SetItems
+	SetOrdering(less func(a T, b T) bool)
+
+	// SetGrouping gathers items into groups, displayed under non-selectable header rows
+	// The grouper returns the key of the group that the item belongs to and the header text for that group; a nil
+	// grouper turns grouping off
+	SetGrouping(grouper func(item T) (string, string))
+
+	// ToggleHighlightedGroupFold folds or unfolds the group containing the highlighted item (or whose header is
+	// highlighted)
+	// Items in folded groups aren't displayed, though their group headers are, and the highlight can rest on a folded
+	// group's header so that it can be unfolded again
+	ToggleHighlightedGroupFold()
+	UnfoldAllGroups()
+
+	// GetHighlightedGroupItemIndices gets the original indices of the displayed items in the same group as the
+	// highlighted item (none if a folded group's header is highlighted)
+	GetHighlightedGroupItemIndices() []int
+
 	SetItems(items []T)
+
+	// Scroll moves the highlight by the given number of places, which are the displayed items and the headers of
+	// folded groups
 	Scroll(scrollOffset int)
+
+	// HighlightItem moves the highlight to the item at the given index *within the filtered list*
+	HighlightItem(filteredIdx int)
+
 	GetItems() []T
 	GetFilteredItemIndices() []int
-	GetHighlightedItemIndex() int
+
+	// GetHighlightedItemIndex gets the index *within the filtered list* of the highlighted item, returning false if no
+	// item is highlighted (the list is empty, or the highlight is on a folded group's header)
+	GetHighlightedItemIndex() (int, bool)
+
+	// GetFilteredItemIndexAtLine gets the index *within the filtered list* of the item displayed on the given line of
+	// the list's view, returning false if there's no item on that line (e.g. it's a group header)
+	GetFilteredItemIndexAtLine(lineIdx int) (int, bool)
 }
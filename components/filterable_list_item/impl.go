@@ -4,6 +4,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mieubrisse/cli-journal-go/components/text_block"
 	"github.com/mieubrisse/cli-journal-go/global_styles"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"strings"
 )
 
 // implementation is a basic implementation of a list item
@@ -35,7 +37,7 @@ func (impl implementation) View() string {
 		lineStyle = lineStyle.Background(global_styles.FocusedComponentBackgroundColor).Bold(true)
 	}
 
-	// TODO do the cute little '...' cutoff
+	// The inner component takes care of cutting off contents that are too wide
 	return lineStyle.Render(impl.innerComponent.View())
 }
 
@@ -57,8 +59,16 @@ func (impl implementation) GetValue() string {
 	return impl.contents
 }
 
+func (impl implementation) GetNumLines() int {
+	numLines := strings.Count(impl.contents, "\n") + 1
+	if impl.height > 0 {
+		numLines = helpers.GetMinInt(numLines, impl.height)
+	}
+	return numLines
+}
+
 func (impl implementation) IsHighlighted() bool {
-	return impl.IsHighlighted()
+	return impl.isHighlighted
 }
 
 func (impl *implementation) SetHighlighted(isHighlighted bool) {
@@ -11,4 +11,8 @@ type Component interface {
 	IsHighlighted() bool
 	SetHighlighted(isHighlighted bool)
 	GetValue() string
+
+	// GetNumLines gets how many lines the item's view takes up, so the list can work out which items are in view
+	// without rendering them all
+	GetNumLines() int
 }
@@ -1,6 +1,9 @@
 package text_block
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"strings"
+)
 
 type impl struct {
 	contents string
@@ -24,11 +27,19 @@ func (item impl) GetContents() string {
 }
 
 func (item impl) View() string {
-	// TODO add the nice '...' for when the item is cut off
-	return lipgloss.NewStyle().
-		MaxWidth(item.width).
-		MaxHeight(item.height).
-		Render(item.contents)
+	lines := strings.Split(item.contents, "\n")
+	if item.height > 0 && len(lines) > item.height {
+		lines = lines[:item.height]
+	}
+
+	// A width of 0 means we haven't been sized yet, so there's nothing to cut off to
+	if item.width > 0 {
+		for idx, line := range lines {
+			lines[idx] = helpers.TruncateToWidth(line, item.width)
+		}
+	}
+
+	return strings.Join(lines, "\n")
 }
 
 func (item *impl) Resize(width int, height int) {
@@ -9,6 +9,9 @@ import (
 	"github.com/muesli/ansi"
 )
 
+// Shown in place of each character of a masked value
+const maskCharacter = '•'
+
 type Model struct {
 	input           textinput.Model
 	foregroundColor lipgloss.Color
@@ -66,6 +69,11 @@ func (model Model) GetValue() string {
 	return model.input.Value()
 }
 
+// CursorEnd moves the cursor to the end of the value
+func (model *Model) CursorEnd() {
+	model.input.CursorEnd()
+}
+
 func (model *Model) Focus() tea.Cmd {
 	model.isFocused = true
 	return model.input.Focus()
@@ -81,6 +89,16 @@ func (model Model) Focused() bool {
 	return model.isFocused
 }
 
+// SetMasked sets whether the value is hidden as it's typed (e.g. for passphrases)
+func (model *Model) SetMasked(isMasked bool) {
+	if isMasked {
+		model.input.EchoMode = textinput.EchoPassword
+		model.input.EchoCharacter = maskCharacter
+		return
+	}
+	model.input.EchoMode = textinput.EchoNormal
+}
+
 func (model *Model) SetForegroundColor(color lipgloss.Color) {
 	model.foregroundColor = color
 }
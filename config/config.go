@@ -0,0 +1,448 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/app_components/entry_item"
+	"github.com/mieubrisse/cli-journal-go/app_components/entry_list"
+	"github.com/mieubrisse/cli-journal-go/entry_filter"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	appDirname     = "cli-journal"
+	configFilename = "config.json"
+
+	// Lives in the journal root, hidden so it isn't listed as an entry
+	JournalConfigFilename = ".cli-journal.json"
+
+	defaultJournalDirname   = "journal"
+	defaultFilterPaneHeight = 6
+
+	minFilterPaneHeight = 2
+	maxFilterPaneHeight = 30
+
+	homeDirPrefix = "~/"
+
+	// What the journal at 'journal_root' is called when it isn't one of the named journals
+	unnamedDefaultJournalName = "default"
+)
+
+var journalNameRegex = regexp.MustCompile("^[a-zA-Z0-9._-]+$")
+
+/*
+Config is the user's settings, as loaded from JSON config files layered on top of the defaults
+
+The user's config file (in the XDG config directory) is applied first, then the journal's own config file (in the
+journal root) on top of that. Each file only needs the settings it changes.
+*/
+type Config struct {
+	// Absolute, though a config file can start it with "~/" to mean the home directory
+	// This is the journal in use, which is the named journal if one was asked for
+	JournalRoot string `json:"journal_root"`
+
+	// Other journals that can be switched to, by name, with roots in the same form as 'journal_root'
+	Journals map[string]string `json:"journals"`
+
+	// Command to edit entries with (which can include args, e.g. "code --wait"); $VISUAL or $EDITOR is used if empty
+	Editor string `json:"editor"`
+
+	// Go time layout for showing entry timestamps
+	TimestampFormat string `json:"timestamp_format"`
+
+	Layout LayoutConfig `json:"layout"`
+
+	DefaultSort     string `json:"default_sort"`
+	DefaultGrouping string `json:"default_grouping"`
+
+	// Filters that the UI starts with, in the same form as the filter pane's lines (tag filters start with a '#')
+	DefaultFilters []string `json:"default_filters"`
+
+	Git GitConfig `json:"git"`
+
+	Encryption EncryptionConfig `json:"encryption"`
+
+	// The config files that were found & applied, in the order they were applied
+	loadedFilepaths []string
+
+	// The journal used when no journal name is given
+	defaultJournalRoot string
+}
+
+// Journal is a journal that can be switched to
+type Journal struct {
+	Name string
+	Root string
+}
+
+type GitConfig struct {
+	// Commit every change made through the app to the git repository the journal is in (creating one if needed)
+	AutoCommit bool `json:"auto_commit"`
+}
+
+type EncryptionConfig struct {
+	// Entries with these tags (or tags inside them) are encrypted on disk, with the journal's passphrase
+	Tags []string `json:"tags"`
+}
+
+type LayoutConfig struct {
+	FilterPaneHeight int `json:"filter_pane_height"`
+	MaxNameWidth     int `json:"max_name_width"`
+
+	// The list's columns, left to right
+	Columns []ColumnConfig `json:"columns"`
+}
+
+type ColumnConfig struct {
+	// One of the column type names, e.g. "relative-time"
+	Type string `json:"type"`
+
+	// When there isn't room for every column, the lowest-priority columns get dropped first
+	Priority int `json:"priority"`
+
+	// Go time layout for timestamp & last-modified columns; timestamp columns use 'timestamp_format' if empty
+	TimeFormat string `json:"time_format"`
+
+	// Widest that a name, tags or path column grows; name columns use 'layout.max_name_width' if 0
+	MaxWidth int `json:"max_width"`
+}
+
+// Default gets the settings used when there are no config files
+func Default() (Config, error) {
+	homeDirpath, err := os.UserHomeDir()
+	if err != nil {
+		return Config{}, fmt.Errorf("an error occurred getting the home directory: %w", err)
+	}
+	return Config{
+		JournalRoot:     filepath.Join(homeDirpath, defaultJournalDirname),
+		Editor:          "",
+		TimestampFormat: entry_item.DefaultTimestampFormat,
+		Layout: LayoutConfig{
+			FilterPaneHeight: defaultFilterPaneHeight,
+			MaxNameWidth:     entry_item.DefaultMaxNameWidth,
+			Columns:          getDefaultColumnConfigs(),
+		},
+		DefaultSort:     entry_list.TimestampDescending.String(),
+		DefaultGrouping: entry_list.NoGrouping.String(),
+		DefaultFilters:  []string{},
+		Git: GitConfig{
+			AutoCommit: false,
+		},
+		Encryption: EncryptionConfig{
+			Tags: []string{},
+		},
+		Journals:        map[string]string{},
+		loadedFilepaths: []string{},
+		// Filled in by Load
+		defaultJournalRoot: "",
+	}, nil
+}
+
+// GetUserConfigFilepath gets where the user's config file lives: $XDG_CONFIG_HOME/cli-journal/config.json, or
+// ~/.config/cli-journal/config.json if $XDG_CONFIG_HOME isn't set
+func GetUserConfigFilepath() (string, error) {
+	configDirpath := os.Getenv("XDG_CONFIG_HOME")
+	if len(configDirpath) == 0 || !filepath.IsAbs(configDirpath) {
+		homeDirpath, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("an error occurred getting the home directory: %w", err)
+		}
+		configDirpath = filepath.Join(homeDirpath, ".config")
+	}
+	return filepath.Join(configDirpath, appDirname, configFilename), nil
+}
+
+// GetJournalConfigFilepath gets where the config file that applies only to the given journal lives
+func GetJournalConfigFilepath(journalRoot string) string {
+	return filepath.Join(journalRoot, JournalConfigFilename)
+}
+
+// Load gets the settings from the user's config file and then the journal's, checking that they're valid
+// If a journal name is given, that journal is used rather than the one at 'journal_root'
+func Load(journalName string) (Config, error) {
+	config, err := Default()
+	if err != nil {
+		return Config{}, err
+	}
+
+	userConfigFilepath, err := GetUserConfigFilepath()
+	if err != nil {
+		return Config{}, err
+	}
+	if err := config.applyFile(userConfigFilepath); err != nil {
+		return Config{}, err
+	}
+
+	journalRoot, err := expandJournalRoot(config.JournalRoot)
+	if err != nil {
+		return Config{}, fmt.Errorf("the journal root is invalid: %w", err)
+	}
+	config.defaultJournalRoot = journalRoot
+	for name, root := range config.Journals {
+		expandedRoot, err := expandJournalRoot(root)
+		if err != nil {
+			return Config{}, fmt.Errorf("the root of journal '%s' is invalid: %w", name, err)
+		}
+		config.Journals[name] = expandedRoot
+	}
+	if len(journalName) > 0 {
+		namedJournalRoot, found := config.Journals[journalName]
+		if !found {
+			return Config{}, fmt.Errorf("no journal named '%s' is configured; the configured journals are: %s", journalName, strings.Join(config.getJournalNames(), ", "))
+		}
+		journalRoot = namedJournalRoot
+	}
+
+	// The journal's config can't change which journals there are, since it's found via the journal root
+	journalConfigFilepath := GetJournalConfigFilepath(journalRoot)
+	journals := config.Journals
+	config.JournalRoot = ""
+	config.Journals = nil
+	if err := config.applyFile(journalConfigFilepath); err != nil {
+		return Config{}, err
+	}
+	if len(config.JournalRoot) > 0 || config.Journals != nil {
+		return Config{}, fmt.Errorf("config file '%s' can't set 'journal_root' or 'journals', since it's inside a journal", journalConfigFilepath)
+	}
+	config.JournalRoot = journalRoot
+	config.Journals = journals
+
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("the config is invalid (loaded from: %s): %w", config.describeLoadedFilepaths(), err)
+	}
+	return config, nil
+}
+
+// Validate checks that every setting can be used
+func (config Config) Validate() error {
+	if !filepath.IsAbs(config.JournalRoot) {
+		return fmt.Errorf("'journal_root' must be an absolute path (or start with '%s'), but was '%s'", homeDirPrefix, config.JournalRoot)
+	}
+	for _, name := range config.getJournalNames() {
+		if !journalNameRegex.MatchString(name) {
+			return fmt.Errorf("journal name '%s' is invalid; names can only contain letters, numbers, '.', '_' and '-'", name)
+		}
+		if root := config.Journals[name]; !filepath.IsAbs(root) {
+			return fmt.Errorf("the root of journal '%s' must be an absolute path (or start with '%s'), but was '%s'", name, homeDirPrefix, root)
+		}
+	}
+	if len(strings.TrimSpace(config.TimestampFormat)) == 0 {
+		return fmt.Errorf("'timestamp_format' can't be empty")
+	}
+	if config.Layout.FilterPaneHeight < minFilterPaneHeight || config.Layout.FilterPaneHeight > maxFilterPaneHeight {
+		return fmt.Errorf(
+			"'layout.filter_pane_height' must be between %d and %d, but was %d",
+			minFilterPaneHeight,
+			maxFilterPaneHeight,
+			config.Layout.FilterPaneHeight,
+		)
+	}
+	columns, err := config.parseColumns()
+	if err != nil {
+		return fmt.Errorf("'layout.columns' is invalid: %w", err)
+	}
+	if err := entry_item.ValidateColumnSpecs(columns); err != nil {
+		return fmt.Errorf("'layout.columns' is invalid: %w", err)
+	}
+	if err := entry_item.ValidateMaxWidth(entry_item.NameColumn, config.Layout.MaxNameWidth); err != nil {
+		return fmt.Errorf("'layout.max_name_width' is invalid: %w", err)
+	}
+	if _, err := entry_list.ParseSortMode(config.DefaultSort); err != nil {
+		return fmt.Errorf("'default_sort' is invalid: %w", err)
+	}
+	if _, err := entry_list.ParseGroupingMode(config.DefaultGrouping); err != nil {
+		return fmt.Errorf("'default_grouping' is invalid: %w", err)
+	}
+	if nameFilterLines, tagFilterLines := entry_filter.ParseFilterLines(config.DefaultFilters); len(nameFilterLines)+len(tagFilterLines) != len(config.DefaultFilters) {
+		return fmt.Errorf("'default_filters' can't have empty filters")
+	}
+	for _, tag := range config.GetEncryptedTags() {
+		if len(tag) == 0 {
+			return fmt.Errorf("'encryption.tags' can't have empty tags")
+		}
+		if err := journal_store.ValidateTag(tag); err != nil {
+			return fmt.Errorf("'encryption.tags' is invalid: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetEncryptedTags gets the tags whose entries are encrypted, without any leading '#'s
+func (config Config) GetEncryptedTags() []string {
+	result := make([]string, 0, len(config.Encryption.Tags))
+	for _, tag := range config.Encryption.Tags {
+		result = append(result, strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	}
+	return result
+}
+
+// GetSortMode gets the sort that the list starts with
+// Only valid on a validated config
+func (config Config) GetSortMode() entry_list.SortMode {
+	mode, _ := entry_list.ParseSortMode(config.DefaultSort)
+	return mode
+}
+
+// GetGroupingMode gets the grouping that the list starts with
+// Only valid on a validated config
+func (config Config) GetGroupingMode() entry_list.GroupingMode {
+	mode, _ := entry_list.ParseGroupingMode(config.DefaultGrouping)
+	return mode
+}
+
+// GetColumns gets the list's columns, with the configured timestamp format & name width applied to the columns that
+// don't set their own
+// Only valid on a validated config
+func (config Config) GetColumns() []entry_item.ColumnSpec {
+	columns, _ := config.parseColumns()
+	result := make([]entry_item.ColumnSpec, 0, len(columns))
+	for _, spec := range columns {
+		switch {
+		case spec.Type == entry_item.TimestampColumn && len(spec.TimeFormat) == 0:
+			spec.TimeFormat = config.TimestampFormat
+		case spec.Type == entry_item.NameColumn && spec.MaxWidth == 0:
+			spec.MaxWidth = config.Layout.MaxNameWidth
+		}
+		result = append(result, spec)
+	}
+	return result
+}
+
+// GetJournals gets the journals that can be switched between, sorted by name, along with the index of the one in use
+// The journal at 'journal_root' is included (as "default") if it isn't one of the named journals
+func (config Config) GetJournals() ([]Journal, int) {
+	result := make([]Journal, 0, len(config.Journals)+1)
+	isDefaultJournalNamed := false
+	for _, name := range config.getJournalNames() {
+		root := config.Journals[name]
+		result = append(result, Journal{Name: name, Root: root})
+		if root == config.defaultJournalRoot {
+			isDefaultJournalNamed = true
+		}
+	}
+	if !isDefaultJournalNamed && len(config.defaultJournalRoot) > 0 {
+		result = append([]Journal{{Name: unnamedDefaultJournalName, Root: config.defaultJournalRoot}}, result...)
+	}
+
+	currentIdx := 0
+	for idx, journal := range result {
+		if journal.Root == config.JournalRoot {
+			currentIdx = idx
+			break
+		}
+	}
+	return result, currentIdx
+}
+
+// GetLoadedFilepaths gets the config files that were applied, in the order they were applied
+func (config Config) GetLoadedFilepaths() []string {
+	return append([]string{}, config.loadedFilepaths...)
+}
+
+// ToJSON renders the settings in the same form as a config file, so the output can be used to start one
+func (config Config) ToJSON() (string, error) {
+	result, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("an error occurred rendering the config as JSON: %w", err)
+	}
+	return string(result), nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// applyFile overwrites the settings with the ones in the config file, doing nothing if it doesn't exist
+func (config *Config) applyFile(configFilepath string) error {
+	fileBytes, err := os.ReadFile(configFilepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred reading config file '%s': %w", configFilepath, err)
+	}
+
+	// Unknown settings are most likely typos, so they're errors rather than being silently ignored
+	decoder := json.NewDecoder(bytes.NewReader(fileBytes))
+	decoder.DisallowUnknownFields()
+	// Decoding a list into an existing one reuses its elements, which would leave the replaced columns' settings in
+	// columns that don't set them
+	columns := config.Layout.Columns
+	config.Layout.Columns = nil
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("config file '%s' isn't valid: %w", configFilepath, err)
+	}
+	if config.Layout.Columns == nil {
+		config.Layout.Columns = columns
+	}
+	config.loadedFilepaths = append(config.loadedFilepaths, configFilepath)
+	return nil
+}
+
+// parseColumns gets the columns as configured, without the timestamp format & name width applied
+func (config Config) parseColumns() ([]entry_item.ColumnSpec, error) {
+	result := make([]entry_item.ColumnSpec, 0, len(config.Layout.Columns))
+	for idx, column := range config.Layout.Columns {
+		columnType, err := entry_item.ParseColumnType(column.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column #%d is invalid: %w", idx+1, err)
+		}
+		result = append(result, entry_item.ColumnSpec{
+			Type:       columnType,
+			Priority:   column.Priority,
+			TimeFormat: column.TimeFormat,
+			MaxWidth:   column.MaxWidth,
+		})
+	}
+	return result, nil
+}
+
+// getDefaultColumnConfigs gets the default columns, leaving their time format & max width to 'timestamp_format' and
+// 'layout.max_name_width'
+func getDefaultColumnConfigs() []ColumnConfig {
+	result := make([]ColumnConfig, 0, len(entry_item.DefaultColumnSpecs))
+	for _, spec := range entry_item.DefaultColumnSpecs {
+		result = append(result, ColumnConfig{
+			Type:       spec.Type.String(),
+			Priority:   spec.Priority,
+			TimeFormat: "",
+			MaxWidth:   0,
+		})
+	}
+	return result
+}
+
+func (config Config) getJournalNames() []string {
+	result := make([]string, 0, len(config.Journals))
+	for name := range config.Journals {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (config Config) describeLoadedFilepaths() string {
+	if len(config.loadedFilepaths) == 0 {
+		return "no config files"
+	}
+	return strings.Join(config.loadedFilepaths, ", ")
+}
+
+func expandJournalRoot(journalRoot string) (string, error) {
+	if !strings.HasPrefix(journalRoot, homeDirPrefix) {
+		return filepath.Clean(journalRoot), nil
+	}
+	homeDirpath, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("an error occurred getting the home directory to expand '%s': %w", journalRoot, err)
+	}
+	return filepath.Join(homeDirpath, strings.TrimPrefix(journalRoot, homeDirPrefix)), nil
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"github.com/mieubrisse/cli-journal-go/app_components/entry_item"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJournalConfigOverridesUserConfig(t *testing.T) {
+	journalRoot := setUpConfigFiles(
+		t,
+		`"timestamp_format": "Jan 2", "default_sort": "name", "layout": {"max_name_width": 30}`,
+		`{"default_sort": "oldest", "default_filters": ["#work"]}`,
+	)
+
+	config, err := Load("")
+	if err != nil {
+		t.Fatalf("expected the config to load, but got: %v", err)
+	}
+	if config.JournalRoot != journalRoot || config.TimestampFormat != "Jan 2" || config.DefaultSort != "oldest" {
+		t.Errorf("expected the journal's config to be applied on top of the user's, but got %+v", config)
+	}
+	if config.Layout.MaxNameWidth != 30 || config.Layout.FilterPaneHeight != defaultFilterPaneHeight {
+		t.Errorf("expected settings missing from the files to keep their defaults, but got layout %+v", config.Layout)
+	}
+	if len(config.GetLoadedFilepaths()) != 2 {
+		t.Errorf("expected both config files to have been loaded, but got %v", config.GetLoadedFilepaths())
+	}
+}
+
+func TestLoadUsesNamedJournal(t *testing.T) {
+	workJournalRoot := t.TempDir()
+	defaultJournalRoot := setUpConfigFiles(t, `"journals": {"work": "`+workJournalRoot+`"}`, "")
+	mustWriteFile(t, GetJournalConfigFilepath(workJournalRoot), `{"default_grouping": "tag"}`)
+
+	config, err := Load("work")
+	if err != nil {
+		t.Fatalf("expected the config to load, but got: %v", err)
+	}
+	if config.JournalRoot != workJournalRoot || config.DefaultGrouping != "tag" {
+		t.Errorf("expected the work journal (with its own config applied) to be in use, but got %+v", config)
+	}
+
+	expectedJournals := []Journal{{Name: "default", Root: defaultJournalRoot}, {Name: "work", Root: workJournalRoot}}
+	journals, currentJournalIdx := config.GetJournals()
+	if !reflect.DeepEqual(journals, expectedJournals) || currentJournalIdx != 1 {
+		t.Errorf("expected journals %v with the work one current, but got %v with #%d current", expectedJournals, journals, currentJournalIdx)
+	}
+
+	if _, err := Load("play"); err == nil || !strings.Contains(err.Error(), "no journal named 'play'") {
+		t.Errorf("expected loading an unknown journal to fail, but got: %v", err)
+	}
+}
+
+func TestColumnsCanBeConfigured(t *testing.T) {
+	setUpConfigFiles(
+		t,
+		`"timestamp_format": "Jan 2", "layout": {"max_name_width": 30}`,
+		`{"layout": {"columns": [{"type": "name", "priority": 5}, {"type": "timestamp", "priority": 1}, {"type": "last-modified", "priority": 2, "time_format": "15:04"}]}}`,
+	)
+
+	config, err := Load("")
+	if err != nil {
+		t.Fatalf("expected the config to load, but got: %v", err)
+	}
+	expectedColumns := []entry_item.ColumnSpec{
+		{Type: entry_item.NameColumn, Priority: 5, TimeFormat: "", MaxWidth: 30},
+		{Type: entry_item.TimestampColumn, Priority: 1, TimeFormat: "Jan 2", MaxWidth: 0},
+		{Type: entry_item.LastModifiedColumn, Priority: 2, TimeFormat: "15:04", MaxWidth: 0},
+	}
+	if columns := config.GetColumns(); !reflect.DeepEqual(columns, expectedColumns) {
+		t.Errorf("expected columns %+v, but got %+v", expectedColumns, columns)
+	}
+}
+
+func TestLoadReportsInvalidSettings(t *testing.T) {
+	invalidJournalConfigsToExpectedErrors := map[string]string{
+		`{"default_sort": "random"}`:                                          "'default_sort' is invalid",
+		`{"layout": {"filter_pane_height": 1}}`:                               "'layout.filter_pane_height' must be between",
+		`{"layout": {"max_name_width": 5}}`:                                   "'layout.max_name_width' is invalid",
+		`{"layout": {"columns": [{"type": "size"}]}}`:                         "unrecognized column type 'size'",
+		`{"layout": {"columns": []}}`:                                         "'layout.columns' is invalid",
+		`{"layout": {"columns": [{"type": "tags", "time_format": "Jan 2"}]}}`: "doesn't take a time format",
+		`{"layout": {"columns": [{"type": "path", "max_width": 3}]}}`:         "'layout.columns' is invalid: column #1 is invalid: 'path' columns need a max width of at least",
+		`{"layout": {"columns": [{"type": "tags"}], "max_name_width": 5}}`:    "'layout.max_name_width' is invalid",
+		`{"journal_root": "/somewhere/else"}`:                                 "can't set 'journal_root'",
+		`{"defualt_grouping": "day"}`:                                         "unknown field",
+	}
+	for journalConfig, expectedError := range invalidJournalConfigsToExpectedErrors {
+		setUpConfigFiles(t, "", journalConfig)
+		if _, err := Load(""); err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected journal config %s to fail with an error containing %q, but got: %v", journalConfig, expectedError, err)
+		}
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// setUpConfigFiles writes the user's config (pointing at a new journal, plus the extra fields) and the journal's config
+// (unless it's empty), returning the journal root
+func setUpConfigFiles(t *testing.T, extraUserConfigFields string, journalConfig string) string {
+	t.Helper()
+	configHome := t.TempDir()
+	journalRoot := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	userConfigFields := `"journal_root": "` + journalRoot + `"`
+	if len(extraUserConfigFields) > 0 {
+		userConfigFields += ", " + extraUserConfigFields
+	}
+	mustWriteFile(t, filepath.Join(configHome, appDirname, configFilename), "{"+userConfigFields+"}")
+	if len(journalConfig) > 0 {
+		mustWriteFile(t, GetJournalConfigFilepath(journalRoot), journalConfig)
+	}
+	return journalRoot
+}
+
+func mustWriteFile(t *testing.T, filepathToWrite string, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filepathToWrite), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepathToWrite, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
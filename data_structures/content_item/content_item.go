@@ -3,7 +3,19 @@ package content_item
 import "time"
 
 type ContentItem struct {
-	Timestamp time.Time
-	Name      string
-	Tags      []string
+	Timestamp    time.Time
+	LastModified time.Time
+	Name         string
+	Tags         []string
+
+	// The first sentence of the body
+	Preview string
+
+	WordCount int
+
+	// Whether the entry is encrypted on disk; its preview & word count are empty while the journal is locked
+	IsEncrypted bool
+
+	// Location of the entry, relative to the journal root
+	Path string
 }
@@ -0,0 +1,110 @@
+package entry_crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Encrypted entries are wrapped in these lines, so they can be told apart from plaintext ones and still look like
+	// text to tools such as git
+	armorHeader = "-----BEGIN CLI-JOURNAL ENCRYPTED ENTRY-----"
+	armorFooter = "-----END CLI-JOURNAL ENCRYPTED ENTRY-----"
+
+	armorLineLength = 64
+
+	keyLength = 32
+)
+
+// ErrLocked is returned when an encrypted entry is read or written while the journal is locked
+var ErrLocked = errors.New("the journal is locked; unlock it to read or write encrypted entries")
+
+// ErrWrongPassphrase is returned when unlocking with a passphrase that isn't the journal's
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// IsEncrypted gets whether an entry file's contents are encrypted
+func IsEncrypted(contents []byte) bool {
+	return bytes.HasPrefix(contents, []byte(armorHeader))
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// seal encrypts the plaintext with AES-GCM, putting the random nonce in front of the ciphertext
+func seal(key []byte, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("an error occurred generating a nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open reverses seal, failing if the data wasn't sealed with the key or has been tampered with
+func open(key []byte, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("the encrypted data is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("the encrypted data couldn't be decrypted with the journal's key: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred creating the cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred creating the cipher: %w", err)
+	}
+	return aead, nil
+}
+
+func armor(sealed []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(sealed)
+
+	var result strings.Builder
+	result.WriteString(armorHeader + "\n")
+	for len(encoded) > armorLineLength {
+		result.WriteString(encoded[:armorLineLength] + "\n")
+		encoded = encoded[armorLineLength:]
+	}
+	if len(encoded) > 0 {
+		result.WriteString(encoded + "\n")
+	}
+	result.WriteString(armorFooter + "\n")
+	return []byte(result.String())
+}
+
+func dearmor(contents []byte) ([]byte, error) {
+	text := strings.TrimSpace(string(contents))
+	if !strings.HasPrefix(text, armorHeader) || !strings.HasSuffix(text, armorFooter) {
+		return nil, fmt.Errorf("the encrypted entry is missing its header or footer line")
+	}
+	encoded := strings.Join(strings.Fields(text[len(armorHeader):len(text)-len(armorFooter)]), "")
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("the encrypted entry isn't valid base64: %w", err)
+	}
+	return sealed, nil
+}
@@ -0,0 +1,94 @@
+package entry_crypto
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeriveKeyMatchesPBKDF2TestVectors(t *testing.T) {
+	// The widely published PBKDF2-HMAC-SHA256 vectors for password "password" and salt "salt"
+	testCases := []struct {
+		iterations int
+		expected   string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+	}
+	for _, testCase := range testCases {
+		key := deriveKey("password", []byte("salt"), testCase.iterations, 32)
+		if actual := hex.EncodeToString(key); actual != testCase.expected {
+			t.Errorf("With %d iterations, expected key %s but got %s", testCase.iterations, testCase.expected, actual)
+		}
+	}
+}
+
+func TestEncryptedEntriesNeedTheRightPassphrase(t *testing.T) {
+	journalRoot := t.TempDir()
+	keyring := NewKeyring(journalRoot)
+	if err := keyring.SetPassphrase("correct horse"); err != nil {
+		t.Fatalf("Setting the passphrase failed: %v", err)
+	}
+
+	body := "Met with Alex\n\n- [ ] follow up on feedback\n"
+	encrypted, err := keyring.Encrypt([]byte(body))
+	if err != nil {
+		t.Fatalf("Encrypting failed: %v", err)
+	}
+	if !IsEncrypted(encrypted) || strings.Contains(string(encrypted), "Alex") {
+		t.Fatalf("Expected armored ciphertext but got:\n%s", encrypted)
+	}
+
+	keyring.Lock()
+	if _, err := keyring.Decrypt(encrypted); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected decrypting while locked to fail with ErrLocked, but got: %v", err)
+	}
+
+	// A fresh keyring is what a new session starts with
+	keyring = NewKeyring(journalRoot)
+	if err := keyring.Unlock("wrong horse"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected the wrong passphrase to be rejected, but got: %v", err)
+	}
+	if err := keyring.Unlock("correct horse"); err != nil {
+		t.Fatalf("Unlocking failed: %v", err)
+	}
+	decrypted, err := keyring.Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("Decrypting failed: %v", err)
+	}
+	if string(decrypted) != body {
+		t.Errorf("Expected the body to round-trip but got: %q", decrypted)
+	}
+}
+
+func TestKeyFilesWithTooFewIterationsAreRejected(t *testing.T) {
+	journalRoot := t.TempDir()
+	if err := NewKeyring(journalRoot).SetPassphrase("correct horse"); err != nil {
+		t.Fatalf("Setting the passphrase failed: %v", err)
+	}
+	keyFilepath := filepath.Join(journalRoot, KeyFilename)
+	fileContents, err := os.ReadFile(keyFilepath)
+	if err != nil {
+		t.Fatalf("Reading the key file failed: %v", err)
+	}
+	var parsed keyFile
+	if err := json.Unmarshal(fileContents, &parsed); err != nil {
+		t.Fatalf("Parsing the key file failed: %v", err)
+	}
+	parsed.Iterations = 1
+	weakened, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatalf("Serializing the key file failed: %v", err)
+	}
+	if err := os.WriteFile(keyFilepath, weakened, 0600); err != nil {
+		t.Fatalf("Writing the key file failed: %v", err)
+	}
+
+	if err := NewKeyring(journalRoot).Unlock("correct horse"); err == nil || !strings.Contains(err.Error(), "iterations") {
+		t.Errorf("Expected unlocking with a weakened key file to fail, but got: %v", err)
+	}
+}
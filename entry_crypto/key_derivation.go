@@ -0,0 +1,12 @@
+package entry_crypto
+
+import (
+	"crypto/sha256"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// deriveKey stretches the passphrase into a key with PBKDF2-HMAC-SHA256 (RFC 8018), so that guessing passphrases is
+// slow
+func deriveKey(passphrase string, salt []byte, iterations int, length int) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, iterations, length, sha256.New)
+}
@@ -0,0 +1,207 @@
+package entry_crypto
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// Lives in the journal root, hidden so it isn't listed as an entry
+	KeyFilename = ".cli-journal-key.json"
+
+	keyFileVersion = 1
+	keyFilePerms   = 0600
+
+	// Per the OWASP recommendation for PBKDF2-HMAC-SHA256
+	defaultKeyDerivationIterations = 600000
+
+	saltLength = 16
+
+	// Encrypted with the key when the passphrase is set, so that a passphrase can be checked without any entries
+	passphraseCheckPlaintext = "cli-journal passphrase check"
+)
+
+// The key file holds what's needed to turn the passphrase back into the key, but not the key itself
+type keyFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+
+	// The passphrase check text, sealed with the key
+	Check []byte `json:"check"`
+}
+
+/*
+Keyring holds the key that a journal's entries are encrypted with, while the journal is unlocked
+
+There's one key per journal, derived from its passphrase. Locking the keyring forgets the key, so encrypted entries
+can't be read or written until it's unlocked again.
+*/
+type Keyring struct {
+	keyFilepath string
+
+	// Nil while locked
+	key []byte
+
+	// Asked for the passphrase when an encrypted entry is needed while locked (told whether it's choosing a new one);
+	// nil to fail with ErrLocked instead
+	passphrasePrompt func(isNewPassphrase bool) (string, error)
+}
+
+func NewKeyring(journalRootDirpath string) *Keyring {
+	return &Keyring{
+		keyFilepath:      filepath.Join(journalRootDirpath, KeyFilename),
+		key:              nil,
+		passphrasePrompt: nil,
+	}
+}
+
+// SetPassphrasePrompt makes the keyring ask for the passphrase when an encrypted entry is needed while it's locked, or
+// for a new one if the journal doesn't have a passphrase yet
+func (keyring *Keyring) SetPassphrasePrompt(prompt func(isNewPassphrase bool) (string, error)) {
+	keyring.passphrasePrompt = prompt
+}
+
+// HasPassphrase gets whether the journal has had a passphrase set
+func (keyring *Keyring) HasPassphrase() bool {
+	_, err := os.Stat(keyring.keyFilepath)
+	return err == nil
+}
+
+// SetPassphrase sets the journal's passphrase, which can only be done once, and unlocks the keyring with it
+func (keyring *Keyring) SetPassphrase(passphrase string) error {
+	if keyring.HasPassphrase() {
+		return fmt.Errorf("the journal already has a passphrase")
+	}
+	if len(passphrase) == 0 {
+		return fmt.Errorf("the passphrase can't be empty")
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("an error occurred generating a salt: %w", err)
+	}
+	key := deriveKey(passphrase, salt, defaultKeyDerivationIterations, keyLength)
+	check, err := seal(key, []byte(passphraseCheckPlaintext))
+	if err != nil {
+		return err
+	}
+
+	fileContents, err := json.MarshalIndent(keyFile{
+		Version:    keyFileVersion,
+		Iterations: defaultKeyDerivationIterations,
+		Salt:       salt,
+		Check:      check,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("an error occurred serializing the key file: %w", err)
+	}
+	file, err := os.OpenFile(keyring.keyFilepath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, keyFilePerms)
+	if err != nil {
+		return fmt.Errorf("an error occurred creating key file '%s': %w", keyring.keyFilepath, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(fileContents, '\n')); err != nil {
+		return fmt.Errorf("an error occurred writing key file '%s': %w", keyring.keyFilepath, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("an error occurred closing key file '%s': %w", keyring.keyFilepath, err)
+	}
+
+	keyring.key = key
+	return nil
+}
+
+// Unlock derives the key from the passphrase, returning ErrWrongPassphrase if it isn't the journal's
+func (keyring *Keyring) Unlock(passphrase string) error {
+	fileContents, err := os.ReadFile(keyring.keyFilepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("the journal has no passphrase yet, so nothing is encrypted")
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred reading key file '%s': %w", keyring.keyFilepath, err)
+	}
+	var parsed keyFile
+	if err := json.Unmarshal(fileContents, &parsed); err != nil {
+		return fmt.Errorf("an error occurred parsing key file '%s': %w", keyring.keyFilepath, err)
+	}
+	if parsed.Version != keyFileVersion {
+		return fmt.Errorf("key file '%s' has unsupported version %d", keyring.keyFilepath, parsed.Version)
+	}
+	// A tampered key file could otherwise make the passphrase cheap to guess from what it encrypts
+	if parsed.Iterations < defaultKeyDerivationIterations {
+		return fmt.Errorf(
+			"key file '%s' has %d key derivation iterations, but at least %d are required",
+			keyring.keyFilepath,
+			parsed.Iterations,
+			defaultKeyDerivationIterations,
+		)
+	}
+
+	key := deriveKey(passphrase, parsed.Salt, parsed.Iterations, keyLength)
+	if check, err := open(key, parsed.Check); err != nil || string(check) != passphraseCheckPlaintext {
+		return ErrWrongPassphrase
+	}
+	keyring.key = key
+	return nil
+}
+
+// Lock forgets the key
+func (keyring *Keyring) Lock() {
+	for idx := range keyring.key {
+		keyring.key[idx] = 0
+	}
+	keyring.key = nil
+}
+
+func (keyring *Keyring) IsUnlocked() bool {
+	return keyring.key != nil
+}
+
+// EnsureUnlocked asks for the passphrase if the keyring is locked and has a prompt, returning ErrLocked if it's still
+// locked after that
+func (keyring *Keyring) EnsureUnlocked() error {
+	if keyring.IsUnlocked() {
+		return nil
+	}
+	if keyring.passphrasePrompt == nil {
+		return ErrLocked
+	}
+	isNewPassphrase := !keyring.HasPassphrase()
+	passphrase, err := keyring.passphrasePrompt(isNewPassphrase)
+	if err != nil {
+		return err
+	}
+	if isNewPassphrase {
+		return keyring.SetPassphrase(passphrase)
+	}
+	return keyring.Unlock(passphrase)
+}
+
+// Encrypt gets the encrypted form of an entry's body, as it's stored on disk
+func (keyring *Keyring) Encrypt(plaintext []byte) ([]byte, error) {
+	if err := keyring.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+	sealed, err := seal(keyring.key, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return armor(sealed), nil
+}
+
+// Decrypt reverses Encrypt
+func (keyring *Keyring) Decrypt(contents []byte) ([]byte, error) {
+	if err := keyring.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+	sealed, err := dearmor(contents)
+	if err != nil {
+		return nil, err
+	}
+	return open(keyring.key, sealed)
+}
@@ -0,0 +1,166 @@
+package entry_filter
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// Filter lines starting with this filter on tags rather than names
+	tagFilterLineLeader = "#"
+
+	// How much each character that a match spans counts against it, relative to each character before the match
+	// starts, so that a tight match beats a loose one that starts a little earlier
+	matchSpanWeight = 2
+)
+
+/*
+Filter decides which entries match the user's filters, and how well
+
+Every name filter must match the entry's name, and at least one of the entry's tags must match every tag filter. A
+filter line's whitespace-separated terms must appear in order (case-insensitively), with anything in between.
+*/
+type Filter struct {
+	nameFilterRegexes []*regexp.Regexp
+	tagFilterRegexes  []*regexp.Regexp
+}
+
+// MatchRange is where one of a name filter's terms matched in an entry's name, as byte offsets [Start, End)
+type MatchRange struct {
+	Start int
+	End   int
+}
+
+func New(nameFilterLines []string, tagFilterLines []string) Filter {
+	return Filter{
+		nameFilterRegexes: transformTermsToFilterRegexes(nameFilterLines),
+		tagFilterRegexes:  transformTermsToFilterRegexes(tagFilterLines),
+	}
+}
+
+// ParseFilterLines splits filter lines into name filters and tag filters, where tag filter lines start with a '#'
+// Returns nameFilterLines, tagFilterLines
+func ParseFilterLines(rawLines []string) ([]string, []string) {
+	nameFilterLines := make([]string, 0)
+	tagFilterLines := make([]string, 0)
+	for _, rawLine := range rawLines {
+		filter := strings.TrimSpace(rawLine)
+
+		isTagFilter := false
+		if strings.HasPrefix(filter, tagFilterLineLeader) {
+			isTagFilter = true
+			filter = filter[len(tagFilterLineLeader):]
+		}
+
+		if len(filter) == 0 {
+			continue
+		}
+
+		if isTagFilter {
+			tagFilterLines = append(tagFilterLines, filter)
+		} else {
+			nameFilterLines = append(nameFilterLines, filter)
+		}
+	}
+
+	return nameFilterLines, tagFilterLines
+}
+
+// Match gets whether the entry matches the filters, and if so how well it matched the name filters (lower is better)
+func (filter Filter) Match(name string, tags []string) (int, bool) {
+	// The way this predicate is structured is as a "gauntlet" - there are many opportunities for an item to be discarded,
+	// and only if it passes those will it be in
+	// I believe this to be the best way to structure predicates, because it makes it easier to think about
+
+	// Filter out non-matching names
+	matchScore := 0
+	for _, nameRegex := range filter.nameFilterRegexes {
+		matchLocation := nameRegex.FindStringIndex(name)
+		if matchLocation == nil {
+			return 0, false
+		}
+
+		// Tighter and earlier matches are better
+		matchStart, matchEnd := matchLocation[0], matchLocation[1]
+		matchScore += matchSpanWeight*(matchEnd-matchStart) + matchStart
+	}
+
+	// If no tag filters are specified, skip this step of the gauntlet
+	if len(filter.tagFilterRegexes) > 0 {
+		// If we have tag filters, we run a sub-gauntlet for tags, where at least one tag must make it through
+		hasTagMatch := false
+	tagLoop:
+		for _, tag := range tags {
+			for _, tagRegex := range filter.tagFilterRegexes {
+				if !tagRegex.MatchString(tag) {
+					continue tagLoop
+				}
+			}
+
+			// A tag made it through the gauntlet; no need to process further tags
+			hasTagMatch = true
+			break
+		}
+		if !hasTagMatch {
+			return 0, false
+		}
+	}
+
+	return matchScore, true
+}
+
+// GetNameMatchRanges gets where each name filter's terms matched in the name, in order of where they start
+// Names that don't match every name filter get no ranges
+func (filter Filter) GetNameMatchRanges(name string) []MatchRange {
+	result := make([]MatchRange, 0)
+	for _, nameRegex := range filter.nameFilterRegexes {
+		// Each term is a capturing group, so the submatches after the whole match are the terms
+		submatchLocations := nameRegex.FindStringSubmatchIndex(name)
+		if submatchLocations == nil {
+			return make([]MatchRange, 0)
+		}
+		for idx := 2; idx+1 < len(submatchLocations); idx += 2 {
+			result = append(result, MatchRange{Start: submatchLocations[idx], End: submatchLocations[idx+1]})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Start < result[j].Start
+	})
+	return result
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Each line that has text, will produce a regex filter that must match
+func transformTermsToFilterRegexes(lines []string) []*regexp.Regexp {
+	result := make([]*regexp.Regexp, 0)
+	for _, line := range lines {
+		terms := strings.Fields(line)
+
+		// Don't bother creating a regex for an empty line
+		if len(terms) == 0 {
+			continue
+		}
+
+		// We have terms, so we need to escape them
+		// Each gets its own group so we can find where it matched; this doesn't change what the regex matches
+		escapedTerms := make([]string, 0, len(terms))
+		for _, term := range terms {
+			escapedTerms = append(escapedTerms, "("+regexp.QuoteMeta(term)+")")
+		}
+
+		// The (?i) makes the search case-insensitive, and the lazy .*? finds the tightest match from where the first
+		// term is found rather than the loosest
+		regexStr := "(?i)" + strings.Join(escapedTerms, ".*?")
+
+		// Okay to use MustCompile here because we quote the user's input so it should be safe
+		result = append(result, regexp.MustCompile(regexStr))
+	}
+
+	return result
+}
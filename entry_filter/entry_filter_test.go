@@ -0,0 +1,54 @@
+package entry_filter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchRequiresTagMatchingAllTagFilters(t *testing.T) {
+	filter := New(ParseFilterLines([]string{"stand notes", "#wo", "#rk"}))
+
+	if _, isMatch := filter.Match("standup-notes", []string{"home", "work"}); !isMatch {
+		t.Errorf("expected the entry to match, since its name has the terms in order and one tag matches both tag filters")
+	}
+	if _, isMatch := filter.Match("notes-standup", []string{"work"}); isMatch {
+		t.Errorf("expected the entry not to match, since its name has the terms out of order")
+	}
+	if _, isMatch := filter.Match("standup-notes", []string{"wo", "rk"}); isMatch {
+		t.Errorf("expected the entry not to match, since no single tag matches both tag filters")
+	}
+}
+
+func TestGetNameMatchRanges(t *testing.T) {
+	filter := New([]string{"NOTES", "stand"}, []string{})
+
+	expected := []MatchRange{{Start: 0, End: 5}, {Start: 8, End: 13}}
+	if actual := filter.GetNameMatchRanges("standup-notes"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected match ranges %v but got %v", expected, actual)
+	}
+	if actual := filter.GetNameMatchRanges("groceries"); len(actual) != 0 {
+		t.Errorf("expected a non-matching name to have no match ranges, but got %v", actual)
+	}
+}
+
+func TestTighterMatchesScoreBetter(t *testing.T) {
+	filter := New([]string{"stand notes"}, []string{})
+
+	looseScore, isMatch := filter.Match("standing-desk-notes-and-notes", []string{})
+	if !isMatch {
+		t.Fatalf("expected the loose name to match")
+	}
+	tightScore, isMatch := filter.Match("my-standup-notes", []string{})
+	if !isMatch {
+		t.Fatalf("expected the tight name to match")
+	}
+	if tightScore >= looseScore {
+		t.Errorf("expected the tight match (score %d) to score better than the loose one that starts earlier (score %d)", tightScore, looseScore)
+	}
+
+	// The terms are matched as close together as they can be, not as far apart
+	expected := []MatchRange{{Start: 0, End: 5}, {Start: 14, End: 19}}
+	if actual := filter.GetNameMatchRanges("standing-desk-notes-and-notes"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected match ranges %v but got %v", expected, actual)
+	}
+}
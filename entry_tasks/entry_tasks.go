@@ -0,0 +1,86 @@
+package entry_tasks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	codeFenceMarker = "```"
+
+	doneMark = "x"
+	openMark = " "
+)
+
+// Matches list items starting with a checkbox, like "- [ ] call the bank" and "  1. [x] file the taxes"
+// The groups are everything up to the mark, the mark, and the task's text
+var taskRegex = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[)([ xX])\]\s+(.*?)\s*$`)
+
+// Task is a "- [ ]" checkbox in an entry's body
+type Task struct {
+	// Index of the task's line within the body
+	LineIdx int
+
+	Text string
+
+	IsDone bool
+}
+
+// Parse finds the tasks in the body, in the order they appear, ignoring any in fenced code blocks
+func Parse(body string) []Task {
+	result := make([]Task, 0)
+	isInCodeFence := false
+	for lineIdx, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), codeFenceMarker) {
+			isInCodeFence = !isInCodeFence
+			continue
+		}
+		if isInCodeFence {
+			continue
+		}
+		if task, found := parseLine(lineIdx, line); found {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// SetDone checks or unchecks the task's box in the body, erroring if the body no longer has the task on its line (e.g.
+// because the entry was edited after the task was read)
+func SetDone(body string, task Task, isDone bool) (string, error) {
+	lines := strings.Split(body, "\n")
+	if task.LineIdx < 0 || task.LineIdx >= len(lines) {
+		return "", fmt.Errorf("task '%s' is no longer in the entry", task.Text)
+	}
+	line := lines[task.LineIdx]
+	currentTask, found := parseLine(task.LineIdx, line)
+	if !found || currentTask.Text != task.Text {
+		return "", fmt.Errorf("task '%s' has moved or changed in the entry since it was read", task.Text)
+	}
+
+	mark := openMark
+	if isDone {
+		mark = doneMark
+	}
+	match := taskRegex.FindStringSubmatchIndex(line)
+	lines[task.LineIdx] = line[:match[4]] + mark + line[match[5]:]
+	return strings.Join(lines, "\n"), nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func parseLine(lineIdx int, line string) (Task, bool) {
+	match := taskRegex.FindStringSubmatch(line)
+	if match == nil || len(match[3]) == 0 {
+		return Task{}, false
+	}
+	return Task{
+		LineIdx: lineIdx,
+		Text:    match[3],
+		IsDone:  match[2] != openMark,
+	}, true
+}
@@ -0,0 +1,41 @@
+package entry_tasks
+
+import (
+	"testing"
+)
+
+func TestTasksAreFoundAndCheckedOffInPlace(t *testing.T) {
+	body := "# Monday\n\n- [ ] call the bank\n  * [X] file the taxes  \n1. [ ] buy milk\n- [] not a task\n```\n- [ ] example\n```\n"
+
+	tasks := Parse(body)
+	if len(tasks) != 3 {
+		t.Fatalf("Expected 3 tasks outside the code block but got %d: %+v", len(tasks), tasks)
+	}
+	expectedTasks := []Task{
+		{LineIdx: 2, Text: "call the bank", IsDone: false},
+		{LineIdx: 3, Text: "file the taxes", IsDone: true},
+		{LineIdx: 4, Text: "buy milk", IsDone: false},
+	}
+	for idx, expectedTask := range expectedTasks {
+		if tasks[idx] != expectedTask {
+			t.Errorf("Expected task %d to be %+v but got %+v", idx, expectedTask, tasks[idx])
+		}
+	}
+
+	newBody, err := SetDone(body, tasks[0], true)
+	if err != nil {
+		t.Fatalf("Expected no error checking off the task but got: %v", err)
+	}
+	newBody, err = SetDone(newBody, tasks[1], false)
+	if err != nil {
+		t.Fatalf("Expected no error unchecking the task but got: %v", err)
+	}
+	expectedBody := "# Monday\n\n- [x] call the bank\n  * [ ] file the taxes  \n1. [ ] buy milk\n- [] not a task\n```\n- [ ] example\n```\n"
+	if newBody != expectedBody {
+		t.Errorf("Expected only the checkboxes to change, giving %q, but got %q", expectedBody, newBody)
+	}
+
+	if _, err := SetDone("# Monday\n\n- [ ] call the bank soon\n", tasks[0], true); err == nil {
+		t.Error("Expected an error checking off a task whose line has changed, but got none")
+	}
+}
@@ -0,0 +1,221 @@
+package exporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"github.com/mieubrisse/cli-journal-go/markdown_html"
+	"html"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+type Format string
+
+const (
+	// All the entries in one markdown document, with a header for each
+	MarkdownFormat Format = "markdown"
+
+	// All the entries in one HTML page that needs nothing else to be viewed
+	HTMLFormat Format = "html"
+
+	// The entry files, laid out in their tag directories like in the journal
+	TarFormat Format = "tar"
+	ZipFormat Format = "zip"
+
+	// Heads exported documents when no other title is given
+	DefaultTitle = "Journal export"
+
+	entryHeaderTimestampFormat = "2006-01-02 15:04"
+
+	archiveFileMode = 0644
+)
+
+var allFormats = []Format{MarkdownFormat, HTMLFormat, TarFormat, ZipFormat}
+
+// The file extensions that GetFormatForFilepath recognizes
+var formatsByExtension = map[string]Format{
+	".md":       MarkdownFormat,
+	".markdown": MarkdownFormat,
+	".html":     HTMLFormat,
+	".htm":      HTMLFormat,
+	".tar":      TarFormat,
+	".zip":      ZipFormat,
+}
+
+// ParseFormat gets the format with the given name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range allFormats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+	formatNames := make([]string, 0, len(allFormats))
+	for _, format := range allFormats {
+		formatNames = append(formatNames, string(format))
+	}
+	return "", fmt.Errorf("unrecognized export format '%s'; expected one of: %s", name, strings.Join(formatNames, ", "))
+}
+
+// GetFormatForFilepath gets the format that a file's extension implies, returning false if it doesn't imply one
+func GetFormatForFilepath(filepath string) (Format, bool) {
+	format, found := formatsByExtension[strings.ToLower(path.Ext(filepath))]
+	return format, found
+}
+
+/*
+Export writes the entries at the given paths to the output in the format, oldest first
+
+The title heads the markdown & HTML documents; the archives contain only the entry files, at their paths in the journal.
+*/
+func Export(store journal_store.Store, entryPaths []string, format Format, title string, out io.Writer) error {
+	entries := make([]content_item.ContentItem, 0, len(entryPaths))
+	bodies := make(map[string]string, len(entryPaths))
+	for _, entryPath := range entryPaths {
+		entry, err := store.Get(entryPath)
+		if err != nil {
+			return err
+		}
+		body, err := store.Read(entryPath)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		bodies[entryPath] = body
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	switch format {
+	case MarkdownFormat:
+		return writeMarkdown(entries, bodies, title, out)
+	case HTMLFormat:
+		return writeHTML(entries, bodies, title, out)
+	case TarFormat:
+		return writeTar(entries, bodies, out)
+	case ZipFormat:
+		return writeZip(entries, bodies, out)
+	default:
+		return fmt.Errorf("unrecognized export format '%s'", format)
+	}
+}
+
+// ExportToFile exports the entries to the file, replacing it if it exists
+func ExportToFile(store journal_store.Store, entryPaths []string, format Format, title string, filepath string) error {
+	file, err := os.Create(filepath)
+	if err != nil {
+		return fmt.Errorf("an error occurred creating export file '%s': %w", filepath, err)
+	}
+	if err := Export(store, entryPaths, format, title, file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("an error occurred writing export file '%s': %w", filepath, err)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func writeMarkdown(entries []content_item.ContentItem, bodies map[string]string, title string, out io.Writer) error {
+	builder := &strings.Builder{}
+	builder.WriteString("# " + title + "\n")
+	for _, entry := range entries {
+		builder.WriteString("\n## " + getEntryHeader(entry) + "\n\n")
+		if tagsLine := getTagsLine(entry); len(tagsLine) > 0 {
+			builder.WriteString("_" + tagsLine + "_\n\n")
+		}
+		builder.WriteString(strings.TrimSpace(bodies[entry.Path]) + "\n")
+	}
+	_, err := io.WriteString(out, builder.String())
+	return err
+}
+
+func writeHTML(entries []content_item.ContentItem, bodies map[string]string, title string, out io.Writer) error {
+	builder := &strings.Builder{}
+	builder.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	builder.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	builder.WriteString("<style>\n" + pageStyle + "</style>\n</head>\n<body>\n")
+	builder.WriteString("<h1>" + html.EscapeString(title) + "</h1>\n")
+	for _, entry := range entries {
+		builder.WriteString("<article>\n<h2>" + html.EscapeString(getEntryHeader(entry)) + "</h2>\n")
+		if tagsLine := getTagsLine(entry); len(tagsLine) > 0 {
+			builder.WriteString(`<p class="tags">` + html.EscapeString(tagsLine) + "</p>\n")
+		}
+		builder.WriteString(markdown_html.ToHTML(bodies[entry.Path]))
+		builder.WriteString("</article>\n")
+	}
+	builder.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(out, builder.String())
+	return err
+}
+
+func writeTar(entries []content_item.ContentItem, bodies map[string]string, out io.Writer) error {
+	writer := tar.NewWriter(out)
+	for _, entry := range entries {
+		body := bodies[entry.Path]
+		header := &tar.Header{
+			Name:    entry.Path,
+			Mode:    archiveFileMode,
+			Size:    int64(len(body)),
+			ModTime: entry.LastModified,
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return fmt.Errorf("an error occurred adding '%s' to the archive: %w", entry.Path, err)
+		}
+		if _, err := io.WriteString(writer, body); err != nil {
+			return fmt.Errorf("an error occurred adding '%s' to the archive: %w", entry.Path, err)
+		}
+	}
+	return writer.Close()
+}
+
+func writeZip(entries []content_item.ContentItem, bodies map[string]string, out io.Writer) error {
+	writer := zip.NewWriter(out)
+	for _, entry := range entries {
+		header := &zip.FileHeader{
+			Name:     entry.Path,
+			Method:   zip.Deflate,
+			Modified: entry.LastModified,
+		}
+		header.SetMode(archiveFileMode)
+		fileWriter, err := writer.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("an error occurred adding '%s' to the archive: %w", entry.Path, err)
+		}
+		if _, err := io.WriteString(fileWriter, bodies[entry.Path]); err != nil {
+			return fmt.Errorf("an error occurred adding '%s' to the archive: %w", entry.Path, err)
+		}
+	}
+	return writer.Close()
+}
+
+func getEntryHeader(entry content_item.ContentItem) string {
+	return entry.Timestamp.Format(entryHeaderTimestampFormat) + " · " + entry.Name
+}
+
+func getTagsLine(entry content_item.ContentItem) string {
+	tags := make([]string, 0, len(entry.Tags))
+	for _, tag := range entry.Tags {
+		tags = append(tags, "#"+tag)
+	}
+	return strings.Join(tags, " ")
+}
+
+const pageStyle = `body { max-width: 48em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.5; color: #222; }
+article { border-top: 1px solid #ddd; padding-top: 0.5em; margin-top: 2em; }
+.tags { color: #777; font-style: italic; }
+pre { background: #f4f4f4; padding: 0.75em; overflow-x: auto; }
+li.task { list-style: none; }
+blockquote { border-left: 3px solid #ddd; margin-left: 0; padding-left: 1em; color: #555; }
+`
@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarkdownExportIsOrderedByTimestamp(t *testing.T) {
+	store, entryPaths := createTestEntries(t)
+
+	out := &bytes.Buffer{}
+	if err := Export(store, entryPaths, MarkdownFormat, "Notes", out); err != nil {
+		t.Fatalf("Exporting failed: %v", err)
+	}
+	expected := "# Notes\n\n" +
+		"## 2023-01-01 09:00 · older\n\n_#work/meetings_\n\nFirst\n\n" +
+		"## 2023-02-01 09:00 · newer\n\nSecond\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, out.String())
+	}
+}
+
+func TestTarExportKeepsDirectoryLayout(t *testing.T) {
+	store, entryPaths := createTestEntries(t)
+
+	out := &bytes.Buffer{}
+	if err := Export(store, entryPaths, TarFormat, "Notes", out); err != nil {
+		t.Fatalf("Exporting failed: %v", err)
+	}
+
+	reader := tar.NewReader(out)
+	archivedPaths := make([]string, 0)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Reading the archive failed: %v", err)
+		}
+		archivedPaths = append(archivedPaths, header.Name)
+	}
+
+	expected := []string{"work/meetings/20230101-090000_older", "20230201-090000_newer"}
+	if !reflect.DeepEqual(archivedPaths, expected) {
+		t.Errorf("Expected archive paths %v but got %v", expected, archivedPaths)
+	}
+}
+
+func TestGetFormatForFilepath(t *testing.T) {
+	if format, found := GetFormatForFilepath("out/Notes.HTML"); !found || format != HTMLFormat {
+		t.Errorf("Expected the html format but got '%s' (found: %v)", format, found)
+	}
+	if _, found := GetFormatForFilepath("notes.txt"); found {
+		t.Errorf("Expected no format for a .txt file")
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// createTestEntries creates two entries, returning their paths newest first
+func createTestEntries(t *testing.T) (journal_store.Store, []string) {
+	store := journal_store.New(t.TempDir())
+	newer, err := store.Create(time.Date(2023, time.February, 1, 9, 0, 0, 0, time.Local), "newer", "", "Second\n")
+	if err != nil {
+		t.Fatalf("Creating an entry failed: %v", err)
+	}
+	older, err := store.Create(time.Date(2023, time.January, 1, 9, 0, 0, 0, time.Local), "older", "work/meetings", "First")
+	if err != nil {
+		t.Fatalf("Creating an entry failed: %v", err)
+	}
+	return store, []string{newer.Path, older.Path}
+}
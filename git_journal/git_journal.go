@@ -0,0 +1,216 @@
+package git_journal
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	gitBinary = "git"
+
+	// Keeps commit subjects readable in 'git log --oneline'
+	maxCommitSubjectLength = 72
+)
+
+/*
+Repo records the changes made to a journal as commits in the git repository it's in
+
+The journal root can be the repository itself or a directory inside one. Only the entries that the app changed are
+committed, so changes made some other way stay uncommitted (and are counted by GetNumOutsideChanges). Nothing here
+talks to remotes; pushing & pulling is left to the user.
+*/
+type Repo struct {
+	rootDirpath string
+
+	// The journal root relative to the top of the repository (e.g. "journal/"), or empty if it's the top
+	pathPrefix string
+
+	// The root-relative paths changed since the last commit
+	pendingEntryPaths map[string]bool
+}
+
+// Open gets the repository the journal root is in, initializing one at the root if it isn't in one
+func Open(rootDirpath string) (*Repo, error) {
+	if _, err := exec.LookPath(gitBinary); err != nil {
+		return nil, fmt.Errorf("git isn't installed, so the journal can't be kept in git: %w", err)
+	}
+
+	repo := &Repo{
+		rootDirpath:       rootDirpath,
+		pathPrefix:        "",
+		pendingEntryPaths: map[string]bool{},
+	}
+	isInsideWorkTree, err := repo.runGit("rev-parse", "--is-inside-work-tree")
+	if err != nil || strings.TrimSpace(isInsideWorkTree) != "true" {
+		if _, err := repo.runGit("init", "--quiet"); err != nil {
+			return nil, fmt.Errorf("an error occurred creating a git repository for the journal: %w", err)
+		}
+	}
+
+	pathPrefix, err := repo.runGit("rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	repo.pathPrefix = strings.TrimSpace(pathPrefix)
+	return repo, nil
+}
+
+// OnEntriesChanged records the paths to be included in the next commit
+func (repo *Repo) OnEntriesChanged(entryPaths ...string) {
+	for _, entryPath := range entryPaths {
+		repo.pendingEntryPaths[entryPath] = true
+	}
+}
+
+// CommitPending commits the changes to the entries changed since the last commit, with the description as the message
+// Nothing is committed if they ended up back as they were (e.g. an entry created & then deleted)
+func (repo *Repo) CommitPending(description string) error {
+	if len(repo.pendingEntryPaths) == 0 {
+		return nil
+	}
+
+	// Paths that don't exist and were never committed can't be given to git
+	committablePaths := make([]string, 0, len(repo.pendingEntryPaths))
+	trackedPaths, err := repo.getTrackedPaths(repo.getPendingPaths())
+	if err != nil {
+		return err
+	}
+	for _, entryPath := range repo.getPendingPaths() {
+		if _, err := os.Stat(filepath.Join(repo.rootDirpath, filepath.FromSlash(entryPath))); err == nil || trackedPaths[entryPath] {
+			committablePaths = append(committablePaths, entryPath)
+		}
+	}
+	repo.pendingEntryPaths = map[string]bool{}
+	if len(committablePaths) == 0 {
+		return nil
+	}
+
+	if _, err := repo.runGit(append([]string{"add", "--all", "--"}, committablePaths...)...); err != nil {
+		return err
+	}
+	if _, err := repo.runGit(append([]string{"diff", "--cached", "--quiet", "--"}, committablePaths...)...); err == nil {
+		// Nothing changed
+		return nil
+	}
+	commitArgs := append([]string{"commit", "--quiet", "--no-verify", "--message", getCommitMessage(description), "--"}, committablePaths...)
+	if _, err := repo.runGit(commitArgs...); err != nil {
+		return fmt.Errorf("an error occurred committing '%s': %w", description, err)
+	}
+	return nil
+}
+
+// GetNumOutsideChanges counts the uncommitted changes under the journal root that the app didn't make
+func (repo *Repo) GetNumOutsideChanges() (int, error) {
+	changedPaths, err := repo.GetChangedPaths()
+	if err != nil {
+		return 0, err
+	}
+	return repo.CountOutsideChanges(changedPaths), nil
+}
+
+// GetChangedPaths gets the root-relative paths of the uncommitted changes under the journal root
+// This only runs git, so unlike the rest of the repo's methods it can be called while others are (e.g. in the
+// background)
+func (repo *Repo) GetChangedPaths() ([]string, error) {
+	output, err := repo.runGit("status", "--porcelain=v1", "-z", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	records := strings.Split(output, "\x00")
+	for idx := 0; idx < len(records); idx++ {
+		record := records[idx]
+		if len(record) < 4 {
+			continue
+		}
+		// Renames & copies are followed by the path they came from
+		if record[0] == 'R' || record[0] == 'C' {
+			idx++
+		}
+		result = append(result, strings.TrimPrefix(record[3:], repo.pathPrefix))
+	}
+	return result, nil
+}
+
+// CountOutsideChanges counts the changed paths (from GetChangedPaths) that the app didn't make
+func (repo *Repo) CountOutsideChanges(changedPaths []string) int {
+	result := 0
+	for _, changedPath := range changedPaths {
+		if !repo.pendingEntryPaths[changedPath] {
+			result++
+		}
+	}
+	return result
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (repo *Repo) runGit(args ...string) (string, error) {
+	cmd := exec.Command(gitBinary, append([]string{"-C", repo.rootDirpath}, args...)...)
+	cmd.Env = append(
+		os.Environ(),
+		// Never wait on a prompt (e.g. for credentials) that the UI would hide
+		"GIT_TERMINAL_PROMPT=0",
+		// Status checks run in the background, so they mustn't take the index lock that a commit might need meanwhile
+		"GIT_OPTIONAL_LOCKS=0",
+	)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); len(message) > 0 {
+			return "", fmt.Errorf("'git %s' failed: %s", args[0], message)
+		}
+		return "", fmt.Errorf("'git %s' failed: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+func (repo *Repo) getPendingPaths() []string {
+	result := make([]string, 0, len(repo.pendingEntryPaths))
+	for entryPath := range repo.pendingEntryPaths {
+		result = append(result, entryPath)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// getTrackedPaths gets which of the root-relative paths git knows about
+func (repo *Repo) getTrackedPaths(entryPaths []string) (map[string]bool, error) {
+	output, err := repo.runGit(append([]string{"ls-files", "-z", "--"}, entryPaths...)...)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool)
+	for _, trackedPath := range strings.Split(output, "\x00") {
+		if len(trackedPath) > 0 {
+			result[trackedPath] = true
+		}
+	}
+	return result, nil
+}
+
+// getCommitMessage turns a description of a change (e.g. "retag 3 entries to #work") into a commit message
+func getCommitMessage(description string) string {
+	subject := strings.TrimSpace(description)
+	if len(subject) == 0 {
+		subject = "update entries"
+	}
+	// Cut by rune & display width, since descriptions can have entry names & tags in any script
+	firstRune, firstRuneSize := utf8.DecodeRuneInString(subject)
+	subject = string(unicode.ToUpper(firstRune)) + subject[firstRuneSize:]
+	return helpers.TruncateToWidth(subject, maxCommitSubjectLength)
+}
@@ -0,0 +1,204 @@
+package git_journal
+
+import (
+	"github.com/mieubrisse/cli-journal-go/helpers"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCommitsOnlyTheAppsChanges(t *testing.T) {
+	setGitIdentity(t)
+	rootDirpath := t.TempDir()
+	repo, err := Open(rootDirpath)
+	if err != nil {
+		t.Fatalf("Opening the repo failed: %v", err)
+	}
+	store := journal_store.New(rootDirpath).WithChangeListener(repo)
+
+	entry, err := store.Create(time.Date(2023, time.May, 1, 9, 0, 0, 0, time.Local), "standup", "work", "Notes")
+	if err != nil {
+		t.Fatalf("Creating an entry failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(rootDirpath, "outside.txt"), []byte("not from the app"), 0644); err != nil {
+		t.Fatalf("Writing a file failed: %v", err)
+	}
+	if numOutsideChanges, err := repo.GetNumOutsideChanges(); err != nil || numOutsideChanges != 1 {
+		t.Errorf("Expected 1 outside change before committing but got %d (error: %v)", numOutsideChanges, err)
+	}
+	if err := repo.CommitPending("create 'standup'"); err != nil {
+		t.Fatalf("Committing failed: %v", err)
+	}
+
+	if _, err := store.Move(entry.Path, "meetings", "standup"); err != nil {
+		t.Fatalf("Moving the entry failed: %v", err)
+	}
+	if err := repo.CommitPending("retag 'standup' to #meetings"); err != nil {
+		t.Fatalf("Committing failed: %v", err)
+	}
+
+	expectedLog := "Retag 'standup' to #meetings\nCreate 'standup'"
+	if log := runGit(t, rootDirpath, "log", "--format=%s"); log != expectedLog {
+		t.Errorf("Expected log:\n%s\nbut got:\n%s", expectedLog, log)
+	}
+	if files := runGit(t, rootDirpath, "ls-files"); files != "meetings/20230501-090000_standup" {
+		t.Errorf("Expected only the moved entry to be committed but got:\n%s", files)
+	}
+	if numOutsideChanges, err := repo.GetNumOutsideChanges(); err != nil || numOutsideChanges != 1 {
+		t.Errorf("Expected the outside change to stay uncommitted but got %d outside changes (error: %v)", numOutsideChanges, err)
+	}
+}
+
+func TestCreatingAndDeletingBeforeCommittingCommitsNothing(t *testing.T) {
+	setGitIdentity(t)
+	rootDirpath := t.TempDir()
+	repo, err := Open(rootDirpath)
+	if err != nil {
+		t.Fatalf("Opening the repo failed: %v", err)
+	}
+	store := journal_store.New(rootDirpath).WithChangeListener(repo)
+
+	entry, err := store.Create(time.Now(), "scratch", "", "")
+	if err != nil {
+		t.Fatalf("Creating an entry failed: %v", err)
+	}
+	if err := store.Delete(entry.Path); err != nil {
+		t.Fatalf("Deleting the entry failed: %v", err)
+	}
+	if err := repo.CommitPending("delete 'scratch'"); err != nil {
+		t.Fatalf("Expected committing nothing to succeed but got: %v", err)
+	}
+	if output, err := exec.Command("git", "-C", rootDirpath, "rev-parse", "HEAD").Output(); err == nil {
+		t.Errorf("Expected no commits but HEAD is %s", output)
+	}
+}
+
+func TestCommitSubjectsAreCutBetweenCharacters(t *testing.T) {
+	description := "éditer " + strings.Repeat("日記", 40)
+	subject := getCommitMessage(description)
+	if !utf8.ValidString(subject) || !strings.HasPrefix(subject, "Éditer ") || !strings.HasSuffix(subject, "…") {
+		t.Errorf("expected the subject to be capitalized & cut between characters, but got %q", subject)
+	}
+	if width := helpers.GetDisplayWidth(subject); width > maxCommitSubjectLength {
+		t.Errorf("expected the subject to be at most %d wide, but it was %d", maxCommitSubjectLength, width)
+	}
+}
+
+func TestHistoryFollowsRetags(t *testing.T) {
+	setGitIdentity(t)
+	rootDirpath := t.TempDir()
+	repo, err := Open(rootDirpath)
+	if err != nil {
+		t.Fatalf("Opening the repo failed: %v", err)
+	}
+	store := journal_store.New(rootDirpath).WithChangeListener(repo)
+
+	entry, err := store.Create(time.Date(2023, time.May, 1, 9, 0, 0, 0, time.Local), "standup", "work", "First\n")
+	if err != nil {
+		t.Fatalf("Creating an entry failed: %v", err)
+	}
+	if err := repo.CommitPending("create 'standup'"); err != nil {
+		t.Fatalf("Committing failed: %v", err)
+	}
+	if _, err := store.Write(entry.Path, "Second\n"); err != nil {
+		t.Fatalf("Writing the entry failed: %v", err)
+	}
+	if err := repo.CommitPending("edit 'standup'"); err != nil {
+		t.Fatalf("Committing failed: %v", err)
+	}
+	movedEntry, err := store.Move(entry.Path, "meetings", "standup")
+	if err != nil {
+		t.Fatalf("Moving the entry failed: %v", err)
+	}
+	if err := repo.CommitPending("retag 'standup' to #meetings"); err != nil {
+		t.Fatalf("Committing failed: %v", err)
+	}
+
+	versions, err := repo.GetHistory(movedEntry.Path)
+	if err != nil {
+		t.Fatalf("Getting the history failed: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("Expected 3 versions but got %d", len(versions))
+	}
+	expectedBodies := []string{"Second\n", "Second\n", "First\n"}
+	for idx, version := range versions {
+		body, err := repo.ReadVersion(version)
+		if err != nil {
+			t.Fatalf("Reading version #%d failed: %v", idx, err)
+		}
+		if body != expectedBodies[idx] {
+			t.Errorf("Expected version #%d to contain '%s' but got '%s'", idx, expectedBodies[idx], body)
+		}
+	}
+}
+
+func TestHistorySkipsDeletionsOfRestoredEntries(t *testing.T) {
+	setGitIdentity(t)
+	rootDirpath := t.TempDir()
+	repo, err := Open(rootDirpath)
+	if err != nil {
+		t.Fatalf("Opening the repo failed: %v", err)
+	}
+	store := journal_store.New(rootDirpath).WithChangeListener(repo)
+
+	entry, err := store.Create(time.Date(2023, time.May, 1, 9, 0, 0, 0, time.Local), "standup", "work", "First\n")
+	if err != nil {
+		t.Fatalf("Creating an entry failed: %v", err)
+	}
+	if err := repo.CommitPending("create 'standup'"); err != nil {
+		t.Fatalf("Committing failed: %v", err)
+	}
+	if err := store.Delete(entry.Path); err != nil {
+		t.Fatalf("Deleting the entry failed: %v", err)
+	}
+	if err := repo.CommitPending("delete 'standup'"); err != nil {
+		t.Fatalf("Committing failed: %v", err)
+	}
+	// As undoing the delete does
+	if _, err := store.CreateAtPath(entry.Path, "First\n"); err != nil {
+		t.Fatalf("Restoring the entry failed: %v", err)
+	}
+	if err := repo.CommitPending("undo delete 'standup'"); err != nil {
+		t.Fatalf("Committing failed: %v", err)
+	}
+
+	versions, err := repo.GetHistory(entry.Path)
+	if err != nil {
+		t.Fatalf("Getting the history failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected the restoring & creating versions, but got %d versions: %+v", len(versions), versions)
+	}
+	for idx, version := range versions {
+		if body, err := repo.ReadVersion(version); err != nil || body != "First\n" {
+			t.Errorf("Expected version #%d to contain 'First' but got '%s' and error: %v", idx, body, err)
+		}
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// setGitIdentity gives the test's commits an author, whatever the machine's git config
+func setGitIdentity(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+}
+
+func runGit(t *testing.T, dirpath string, args ...string) string {
+	output, err := exec.Command("git", append([]string{"-C", dirpath}, args...)...).Output()
+	if err != nil {
+		t.Fatalf("Running 'git %s' failed: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(output))
+}
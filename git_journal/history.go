@@ -0,0 +1,83 @@
+package git_journal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Older versions than this aren't listed, to keep the history quick to load
+	maxHistoryLength = 200
+
+	historyRecordSeparator = "\x1e"
+	historyFieldSeparator  = "\x00"
+)
+
+// Version is an entry as it was in a commit
+type Version struct {
+	CommitHash string
+	Timestamp  time.Time
+
+	// The commit's subject, which describes the change (e.g. "Retag 'standup' to #meetings")
+	Description string
+
+	// Where the entry was in the commit, relative to the top of the repository (it may have been moved since)
+	repoRelativePath string
+}
+
+// GetHistory gets the committed versions of the entry, newest first, following it across renames & retags
+func (repo *Repo) GetHistory(entryPath string) ([]Version, error) {
+	output, err := repo.runGit(
+		"log",
+		"--follow",
+		fmt.Sprintf("--max-count=%d", maxHistoryLength),
+		// Git writes the separators from these escapes, since args can't contain NULs
+		"--format=%x1e%H%x00%ct%x00%s",
+		"--name-only",
+		// Commits that deleted the entry have no version of it to show (which happens when a deletion gets undone)
+		"--diff-filter=d",
+		"--",
+		entryPath,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred getting the history of entry '%s': %w", entryPath, err)
+	}
+
+	result := make([]Version, 0)
+	for _, record := range strings.Split(output, historyRecordSeparator) {
+		if len(strings.TrimSpace(record)) == 0 {
+			continue
+		}
+		header, fileList, _ := strings.Cut(record, "\n")
+		fields := strings.SplitN(header, historyFieldSeparator, 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected git log output '%s'", header)
+		}
+		unixSeconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected commit time '%s': %w", fields[1], err)
+		}
+		repoRelativePath := strings.TrimSpace(fileList)
+		if len(repoRelativePath) == 0 {
+			continue
+		}
+		result = append(result, Version{
+			CommitHash:       fields[0],
+			Timestamp:        time.Unix(unixSeconds, 0),
+			Description:      fields[2],
+			repoRelativePath: repoRelativePath,
+		})
+	}
+	return result, nil
+}
+
+// ReadVersion gets what the entry contained in the version
+func (repo *Repo) ReadVersion(version Version) (string, error) {
+	body, err := repo.runGit("show", version.CommitHash+":"+version.repoRelativePath)
+	if err != nil {
+		return "", fmt.Errorf("an error occurred reading the version from commit %s: %w", version.CommitHash, err)
+	}
+	return body, nil
+}
@@ -3,11 +3,16 @@ package helpers
 import (
 	"github.com/acarl005/stripansi"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
 	"strings"
 )
 
 var overlayBackgroundStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#333333"))
 
+// Used when a string is too wide to be displayed in full
+const ellipsis = "…"
+
 // We don't want the modal to be too far down the screen if it's really small, so for small modals this is the maximum distance
 // from the top of the screen that the modal will be displayed (expressed as a % of the total height of the background)
 const maxModalTopOffsetPercentage = 0.3
@@ -26,27 +31,34 @@ func GetMinInt(a int, b int) int {
 	return b
 }
 
-// TODO FIX BUG IN THIS - we can't just overlay using string length, because non-printing chars mess this up (e.g. color chars)
-// TODO add a way to dim the background
-func OverlayString(background string, overlay string) string {
-	backgroundWidth, backgroundHeight := lipgloss.Size(background)
-	overlayWidth, overlayHeight := lipgloss.Size(overlay)
-
+// GetOverlayPosition gets the line & column where OverlayString will put the top-left corner of an overlay of the given
+// size, so that e.g. mouse clicks can be checked against it
+func GetOverlayPosition(backgroundWidth int, backgroundHeight int, overlayWidth int, overlayHeight int) (int, int) {
 	maxFirstOverlaidLineIdx := int(float64(backgroundHeight) * maxModalTopOffsetPercentage)
 
-	// The index of the first line that will suffer replacement
 	// We don't want it too far down the screen, so we have a cap
 	firstOverlaidLineIdx := GetMinInt(
 		(backgroundHeight/2)-(overlayHeight/2),
 		maxFirstOverlaidLineIdx,
 	)
 
+	cutpointIdx := (backgroundWidth / 2) - (overlayWidth / 2)
+
+	return firstOverlaidLineIdx, cutpointIdx
+}
+
+// TODO FIX BUG IN THIS - we can't just overlay using string length, because non-printing chars mess this up (e.g. color chars)
+// TODO add a way to dim the background
+func OverlayString(background string, overlay string) string {
+	backgroundWidth, backgroundHeight := lipgloss.Size(background)
+	overlayWidth, overlayHeight := lipgloss.Size(overlay)
+
+	// The index of the first line that will suffer replacement, and the index of the first column that will be replaced
+	firstOverlaidLineIdx, cutpointIdx := GetOverlayPosition(backgroundWidth, backgroundHeight, overlayWidth, overlayHeight)
+
 	// The index of the line that switches back to being background again
 	resumeLineIdx := firstOverlaidLineIdx + overlayHeight
 
-	// The index of the first column that will be replaced
-	cutpointIdx := (backgroundWidth / 2) - (overlayWidth / 2)
-
 	// The index of the column that switches back to being background again
 	resumepointIdx := backgroundWidth - cutpointIdx
 
@@ -74,3 +86,98 @@ func OverlayString(background string, overlay string) string {
 func ClearFormatting(input string) string {
 	return stripansi.Strip(input)
 }
+
+// GetDisplayWidth gets the number of terminal cells the string takes up when printed
+// This isn't the same as the number of bytes or runes: CJK characters and emoji take up two cells, and combining
+// characters take up none
+func GetDisplayWidth(input string) int {
+	return runewidth.StringWidth(input)
+}
+
+// TruncateToWidth cuts the string down to fit in the given number of terminal cells, ending it with an ellipsis if
+// anything got cut off
+// The string is only ever cut between grapheme clusters, so multi-byte characters & emoji never get split
+func TruncateToWidth(input string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return runewidth.Truncate(input, width, ellipsis)
+}
+
+// PadToWidth pads the string with spaces so that it takes up exactly the given number of terminal cells, truncating
+// it if it's too wide
+func PadToWidth(input string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	truncated := TruncateToWidth(input, width)
+	return truncated + strings.Repeat(" ", width-GetDisplayWidth(truncated))
+}
+
+// WrapToWidth breaks the string into lines no wider than the given number of terminal cells, breaking on spaces where
+// possible and between grapheme clusters where a single word is too wide to fit on a line
+func WrapToWidth(input string, width int) []string {
+	if width <= 0 {
+		return []string{}
+	}
+
+	result := make([]string, 0)
+	currentLine := ""
+	currentLineWidth := 0
+	for _, word := range strings.Fields(input) {
+		wordWidth := GetDisplayWidth(word)
+
+		// The word fits on the current line (after a space, if the line already has something)
+		if currentLineWidth > 0 && currentLineWidth+1+wordWidth <= width {
+			currentLine += " " + word
+			currentLineWidth += 1 + wordWidth
+			continue
+		}
+
+		if currentLineWidth > 0 {
+			result = append(result, currentLine)
+			currentLine = ""
+			currentLineWidth = 0
+		}
+
+		// The word is too wide for even a line of its own, so it gets split between grapheme clusters
+		graphemes := uniseg.NewGraphemes(word)
+		for graphemes.Next() {
+			cluster := graphemes.Str()
+			clusterWidth := GetDisplayWidth(cluster)
+			if currentLineWidth+clusterWidth > width && currentLineWidth > 0 {
+				result = append(result, currentLine)
+				currentLine = ""
+				currentLineWidth = 0
+			}
+			currentLine += cluster
+			currentLineWidth += clusterWidth
+		}
+	}
+	if currentLineWidth > 0 || len(result) == 0 {
+		result = append(result, currentLine)
+	}
+	return result
+}
+
+// FitToSize pads out (or cuts off) the lines of the string so that it's exactly the given number of lines, each at
+// least the given number of terminal cells wide
+// Unlike lipgloss, this measures width by grapheme cluster, so it won't rewrap lines containing emoji sequences that
+// were already laid out to fit
+func FitToSize(input string, width int, height int) string {
+	lines := strings.Split(input, "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+
+	for idx, line := range lines {
+		lineWidth := GetDisplayWidth(ClearFormatting(line))
+		if lineWidth < width {
+			lines[idx] = line + strings.Repeat(" ", width-lineWidth)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,93 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGetDisplayWidth(t *testing.T) {
+	testCases := map[string]int{
+		"journal.md":    10,
+		"日記.md":         7,
+		"café":          4,
+		"cafe\u0301":    4,
+		"🎉 party":       8,
+		"👩‍💻-notes":     8,
+		"Ελληνικά":      8,
+		"한국어 메모":        11,
+		"":              0,
+		"mixed 中文 text": 15,
+	}
+	for input, expectedWidth := range testCases {
+		if actualWidth := GetDisplayWidth(input); actualWidth != expectedWidth {
+			t.Errorf("Expected '%s' to be %d cells wide but was %d", input, expectedWidth, actualWidth)
+		}
+	}
+}
+
+func TestTruncateToWidth(t *testing.T) {
+	type testCase struct {
+		input    string
+		width    int
+		expected string
+	}
+	testCases := []testCase{
+		{input: "journal.md", width: 20, expected: "journal.md"},
+		{input: "journal.md", width: 10, expected: "journal.md"},
+		{input: "journal.md", width: 8, expected: "journal…"},
+		{input: "journal.md", width: 1, expected: "…"},
+		{input: "journal.md", width: 0, expected: ""},
+
+		// A wide character that doesn't fit gets dropped entirely rather than split, leaving the result a cell short
+		{input: "日記と思い出.md", width: 6, expected: "日記…"},
+		{input: "日記と思い出.md", width: 7, expected: "日記と…"},
+
+		// Combining characters stay attached to their base character
+		{input: "cafe\u0301-notes", width: 5, expected: "cafe\u0301…"},
+
+		// Emoji made of several code points are never split up
+		{input: "👩‍💻👩‍💻👩‍💻", width: 5, expected: "👩‍💻👩‍💻…"},
+	}
+	for _, test := range testCases {
+		actual := TruncateToWidth(test.input, test.width)
+		if actual != test.expected {
+			t.Errorf("Expected '%s' truncated to %d cells to be '%s' but was '%s'", test.input, test.width, test.expected, actual)
+		}
+		if !utf8.ValidString(actual) {
+			t.Errorf("Truncating '%s' to %d cells produced invalid UTF-8", test.input, test.width)
+		}
+		if GetDisplayWidth(actual) > test.width {
+			t.Errorf("Truncating '%s' to %d cells produced '%s', which is %d cells wide", test.input, test.width, actual, GetDisplayWidth(actual))
+		}
+	}
+}
+
+func TestWrapToWidth(t *testing.T) {
+	type testCase struct {
+		input    string
+		width    int
+		expected []string
+	}
+	testCases := []testCase{
+		{input: "short name", width: 20, expected: []string{"short name"}},
+		{input: "meeting notes from the offsite", width: 14, expected: []string{"meeting notes", "from the", "offsite"}},
+		{input: "日記 と 思い出 の まとめ", width: 8, expected: []string{"日記 と", "思い出", "の", "まとめ"}},
+
+		// Words too long for a line get broken between grapheme clusters, never through a wide character
+		{input: "二〇二三年四月の振り返り", width: 7, expected: []string{"二〇二", "三年四", "月の振", "り返り"}},
+		{input: "👩‍💻👩‍💻👩‍💻👩‍💻", width: 5, expected: []string{"👩‍💻👩‍💻", "👩‍💻👩‍💻"}},
+		{input: "", width: 5, expected: []string{""}},
+	}
+	for _, test := range testCases {
+		actual := WrapToWidth(test.input, test.width)
+		if strings.Join(actual, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("Expected '%s' wrapped to %d cells to be %q but was %q", test.input, test.width, test.expected, actual)
+		}
+		for _, line := range actual {
+			if GetDisplayWidth(line) > test.width {
+				t.Errorf("Wrapping '%s' to %d cells produced line '%s', which is %d cells wide", test.input, test.width, line, GetDisplayWidth(line))
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Day One escapes markdown punctuation in its exported text (e.g. '\.' and '\-')
+var dayOneEscapeRegex = regexp.MustCompile(`\\([\\.\-!#*+()\[\]{}_` + "`" + `>|])`)
+
+type dayOneExport struct {
+	Entries []dayOneEntry `json:"entries"`
+}
+
+type dayOneEntry struct {
+	UUID         string   `json:"uuid"`
+	CreationDate string   `json:"creationDate"`
+	Text         string   `json:"text"`
+	Tags         []string `json:"tags"`
+}
+
+// ReadDayOneJSON reads the Journal.json file of a Day One JSON export
+// The entry's first line becomes its title, and its tags (which aren't in the text) are kept in the order Day One has them
+func ReadDayOneJSON(reader io.Reader, sourceName string) ([]ImportedEntry, error) {
+	var export dayOneExport
+	if err := json.NewDecoder(reader).Decode(&export); err != nil {
+		return nil, fmt.Errorf("%s isn't a Day One JSON export: %w", sourceName, err)
+	}
+
+	result := make([]ImportedEntry, 0, len(export.Entries))
+	for idx, jsonEntry := range export.Entries {
+		source := fmt.Sprintf("%s entry #%d", sourceName, idx+1)
+		if len(jsonEntry.UUID) > 0 {
+			source = fmt.Sprintf("%s (%s)", source, jsonEntry.UUID)
+		}
+
+		timestamp, err := time.Parse(time.RFC3339, jsonEntry.CreationDate)
+		if err != nil {
+			return nil, fmt.Errorf("%s has an invalid creation date: %w", source, err)
+		}
+
+		text := dayOneEscapeRegex.ReplaceAllString(jsonEntry.Text, "$1")
+		firstLine, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+
+		result = append(result, ImportedEntry{
+			Timestamp:     timestamp.Local(),
+			Title:         strings.TrimLeft(firstLine, "# "),
+			Body:          strings.TrimSpace(text) + "\n",
+			Tags:          jsonEntry.Tags,
+			AreTagsInBody: false,
+			Source:        source,
+		})
+	}
+	return result, nil
+}
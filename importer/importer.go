@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"strings"
+	"time"
+)
+
+const (
+	// Names made from imported titles are kept short enough to show in full in the list
+	maxImportedNameLength = 40
+
+	// Used when an imported entry's title has nothing that can go in a name
+	fallbackImportedName = "imported"
+)
+
+// ImportedEntry is an entry read from another journaling tool, before it's been fitted to this journal
+type ImportedEntry struct {
+	Timestamp time.Time
+
+	// Becomes the entry's name
+	Title string
+
+	Body string
+
+	// In order of importance, since the entry only gets one of them (see TagMapper)
+	Tags []string
+
+	// Whether the body already mentions the tags (e.g. as inline '#tags'); if not, the ones that don't become the
+	// entry's tag are added to the end of the body so they aren't lost
+	AreTagsInBody bool
+
+	// Where the entry came from (e.g. a file & line), for reports
+	Source string
+}
+
+// PlannedEntry is what importing an entry will do
+type PlannedEntry struct {
+	Entry ImportedEntry
+
+	Name string
+	Tag  string
+
+	// Where the entry will be created, relative to the journal root
+	Path string
+
+	// If the entry is already in the journal (or earlier in the import), the path of the existing entry; it won't be
+	// imported again
+	DuplicateOfPath string
+
+	// If the entry can't be imported, why not
+	Err error
+}
+
+func (plannedEntry PlannedEntry) IsImportable() bool {
+	return plannedEntry.Err == nil && len(plannedEntry.DuplicateOfPath) == 0
+}
+
+/*
+Plan works out what importing the entries would do, without changing the journal
+
+An entry is a duplicate if the journal (or an earlier entry in the import) already has an entry with the same name and
+timestamp, whatever its tag, so importing the same export twice doesn't create anything the second time.
+*/
+func Plan(store journal_store.Store, entries []ImportedEntry, tagMapper TagMapper) ([]PlannedEntry, error) {
+	existingEntries, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	existingPathsByKey := make(map[string]string, len(existingEntries))
+	for _, existingEntry := range existingEntries {
+		existingPathsByKey[getDuplicateKey(existingEntry.Timestamp, existingEntry.Name)] = existingEntry.Path
+	}
+
+	result := make([]PlannedEntry, 0, len(entries))
+	for _, entry := range entries {
+		plannedEntry := PlannedEntry{
+			Entry:           entry,
+			Name:            "",
+			Tag:             "",
+			Path:            "",
+			DuplicateOfPath: "",
+			Err:             nil,
+		}
+
+		name, isValid := journal_store.ToEntryName(entry.Title, maxImportedNameLength)
+		if !isValid {
+			name = fallbackImportedName
+		}
+		plannedEntry.Name = name
+
+		tag, err := tagMapper.GetTag(entry.Tags)
+		if err != nil {
+			plannedEntry.Err = err
+			result = append(result, plannedEntry)
+			continue
+		}
+		plannedEntry.Tag = tag
+
+		entryPath, err := journal_store.GetEntryPath(entry.Timestamp, name, tag)
+		if err != nil {
+			plannedEntry.Err = err
+			result = append(result, plannedEntry)
+			continue
+		}
+		plannedEntry.Path = entryPath
+
+		// Entry timestamps only go down to the second
+		duplicateKey := getDuplicateKey(entry.Timestamp, name)
+		if existingPath, found := existingPathsByKey[duplicateKey]; found {
+			plannedEntry.DuplicateOfPath = existingPath
+		} else {
+			existingPathsByKey[duplicateKey] = entryPath
+		}
+		result = append(result, plannedEntry)
+	}
+	return result, nil
+}
+
+// Apply creates the importable entries in the plan, returning how many were created
+// Entries that fail to be created don't stop the rest from being imported; the errors are returned together
+func Apply(store journal_store.Store, plan []PlannedEntry) (int, error) {
+	numCreated := 0
+	errorMessages := make([]string, 0)
+	for _, plannedEntry := range plan {
+		if !plannedEntry.IsImportable() {
+			continue
+		}
+		if _, err := store.CreateAtPath(plannedEntry.Path, getBody(plannedEntry)); err != nil {
+			errorMessages = append(errorMessages, fmt.Sprintf("%s: %v", plannedEntry.Entry.Source, err))
+			continue
+		}
+		numCreated++
+	}
+
+	if len(errorMessages) > 0 {
+		return numCreated, fmt.Errorf("%d entries couldn't be imported:\n%s", len(errorMessages), strings.Join(errorMessages, "\n"))
+	}
+	return numCreated, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getDuplicateKey(timestamp time.Time, name string) string {
+	return fmt.Sprintf("%d/%s", timestamp.Unix(), name)
+}
+
+// getBody gets what the created entry will contain, which mentions any tags that would otherwise be lost
+func getBody(plannedEntry PlannedEntry) string {
+	body := plannedEntry.Entry.Body
+	if plannedEntry.Entry.AreTagsInBody {
+		return body
+	}
+
+	otherTags := make([]string, 0)
+	for _, tag := range plannedEntry.Entry.Tags {
+		if tag != plannedEntry.Tag && !strings.Contains(body, "#"+tag) {
+			otherTags = append(otherTags, "#"+tag)
+		}
+	}
+	if len(otherTags) == 0 {
+		return body
+	}
+	return strings.TrimRight(body, "\n") + "\n\nTags: " + strings.Join(otherTags, " ") + "\n"
+}
@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadJrnlTextSplitsEntriesAndFindsTags(t *testing.T) {
+	text := "[2023-01-02 09:30] Standup notes. Talked about @work stuff\nMore @Work and @health\n\n" +
+		"[2023-01-03 21:00] Evening walk\n"
+
+	entries, err := ReadJrnlText(strings.NewReader(text), "journal.txt")
+	if err != nil {
+		t.Fatalf("Reading the jrnl text failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries but got %d", len(entries))
+	}
+
+	expectedTimestamp := time.Date(2023, 1, 2, 9, 30, 0, 0, time.Local)
+	if !entries[0].Timestamp.Equal(expectedTimestamp) {
+		t.Errorf("Expected timestamp %v but got %v", expectedTimestamp, entries[0].Timestamp)
+	}
+	if entries[0].Title != "Standup notes" {
+		t.Errorf("Expected the title to be the first sentence but got '%s'", entries[0].Title)
+	}
+	if !reflect.DeepEqual(entries[0].Tags, []string{"work", "health"}) {
+		t.Errorf("Expected tags [work health] but got %v", entries[0].Tags)
+	}
+	if entries[1].Body != "Evening walk\n" {
+		t.Errorf("Expected the second entry's body to be only its own text but got '%s'", entries[1].Body)
+	}
+}
+
+func TestReadObsidianVaultUsesFrontMatterAndSkipsHiddenDirs(t *testing.T) {
+	vaultDirpath := t.TempDir()
+	writeFile(t, filepath.Join(vaultDirpath, "projects", "Launch plan.md"), "---\ndate: 2023-04-05 10:00\ntags:\n  - planning\n---\nSee #review too\n")
+	writeFile(t, filepath.Join(vaultDirpath, ".obsidian", "workspace.md"), "not a note")
+
+	entries, err := ReadObsidianVault(vaultDirpath)
+	if err != nil {
+		t.Fatalf("Reading the vault failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry but got %d", len(entries))
+	}
+
+	entry := entries[0]
+	if !entry.Timestamp.Equal(time.Date(2023, 4, 5, 10, 0, 0, 0, time.Local)) {
+		t.Errorf("Expected the front matter date to be used but got %v", entry.Timestamp)
+	}
+	if !reflect.DeepEqual(entry.Tags, []string{"planning", "review", "projects"}) {
+		t.Errorf("Expected tags [planning review projects] but got %v", entry.Tags)
+	}
+	if entry.Body != "See #review too\n" {
+		t.Errorf("Expected the front matter to be left out of the body but got '%s'", entry.Body)
+	}
+}
+
+func TestTagMapperPicksFirstMatchingTag(t *testing.T) {
+	mapper, err := NewTagMapper([]string{"health=personal/health", "area/*=areas/*"}, "")
+	if err != nil {
+		t.Fatalf("Creating the tag mapper failed: %v", err)
+	}
+
+	cases := map[string][]string{
+		"personal/health": {"misc", "health"},
+		"areas/finance":   {"area/finance", "health"},
+		"misc":            {"misc", "other"},
+		"":                {},
+	}
+	for expectedTag, importedTags := range cases {
+		tag, err := mapper.GetTag(importedTags)
+		if err != nil {
+			t.Errorf("Mapping %v failed: %v", importedTags, err)
+			continue
+		}
+		if tag != expectedTag {
+			t.Errorf("Expected %v to map to '%s' but got '%s'", importedTags, expectedTag, tag)
+		}
+	}
+}
+
+func TestPlanSkipsEntriesAlreadyImported(t *testing.T) {
+	store := journal_store.New(t.TempDir())
+	mapper, err := NewTagMapper(nil, "")
+	if err != nil {
+		t.Fatalf("Creating the tag mapper failed: %v", err)
+	}
+	entries := []ImportedEntry{
+		{
+			Timestamp:     time.Date(2023, 1, 2, 9, 30, 0, 0, time.Local),
+			Title:         "Standup notes",
+			Body:          "Standup notes\n",
+			Tags:          []string{"work", "meetings"},
+			AreTagsInBody: false,
+			Source:        "test #1",
+		},
+	}
+
+	plan, err := Plan(store, entries, mapper)
+	if err != nil {
+		t.Fatalf("Planning the import failed: %v", err)
+	}
+	numCreated, err := Apply(store, plan)
+	if err != nil || numCreated != 1 {
+		t.Fatalf("Expected 1 entry to be created but got %d (error: %v)", numCreated, err)
+	}
+	body, err := store.Read(plan[0].Path)
+	if err != nil {
+		t.Fatalf("Reading the imported entry failed: %v", err)
+	}
+	if !strings.Contains(body, "#meetings") {
+		t.Errorf("Expected the tag that didn't become the entry's tag to be kept in the body but got '%s'", body)
+	}
+
+	secondPlan, err := Plan(store, entries, mapper)
+	if err != nil {
+		t.Fatalf("Planning the second import failed: %v", err)
+	}
+	if secondPlan[0].IsImportable() || secondPlan[0].DuplicateOfPath != plan[0].Path {
+		t.Errorf("Expected the second import to be a duplicate of '%s' but got %+v", plan[0].Path, secondPlan[0])
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func writeFile(t *testing.T, filepathStr string, contents string) {
+	if err := os.MkdirAll(filepath.Dir(filepathStr), 0755); err != nil {
+		t.Fatalf("Creating the directory for '%s' failed: %v", filepathStr, err)
+	}
+	if err := os.WriteFile(filepathStr, []byte(contents), 0644); err != nil {
+		t.Fatalf("Writing '%s' failed: %v", filepathStr, err)
+	}
+}
@@ -0,0 +1,187 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// jrnl prefixes its tags with this
+	jrnlTagPrefix = "@"
+
+	// How the date & time fields of jrnl's JSON export look
+	jrnlJSONDateFormat = "2006-01-02"
+	jrnlJSONTimeFormat = "15:04"
+)
+
+// jrnl's default timestamp formats, which users can change; these are the ones we recognize
+var jrnlTextTimestampFormats = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02 03:04 PM",
+	"2006-01-02 03:04PM",
+}
+
+// An entry's first line in jrnl's plain text export, which is '[TIMESTAMP] TITLE' (or without the brackets in older
+// versions)
+var jrnlEntryHeaderRegex = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2} \d{1,2}:\d{2}(?::\d{2})?(?: ?[AP]M)?)\]? ?(.*)$`)
+
+var jrnlTagRegex = regexp.MustCompile(`(?:^|\s)` + jrnlTagPrefix + `([\w/-]+)`)
+
+// jrnl ends the title at the first sentence
+var jrnlTitleEndRegex = regexp.MustCompile(`[.?!](\s|$)`)
+
+// ReadJrnlText reads jrnl's plain text export (what 'jrnl --export txt' or the journal file itself contains)
+// Tags are jrnl's inline '@tags', which are left in the body
+func ReadJrnlText(reader io.Reader, sourceName string) ([]ImportedEntry, error) {
+	result := make([]ImportedEntry, 0)
+
+	var currentEntry *ImportedEntry
+	bodyLines := make([]string, 0)
+	finishEntry := func() {
+		if currentEntry == nil {
+			return
+		}
+		currentEntry.Body = strings.TrimSpace(strings.Join(bodyLines, "\n")) + "\n"
+		currentEntry.Tags = getJrnlTags(currentEntry.Body)
+		result = append(result, *currentEntry)
+		bodyLines = bodyLines[:0]
+	}
+
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		matches := jrnlEntryHeaderRegex.FindStringSubmatch(line)
+		if matches == nil {
+			if currentEntry == nil {
+				if len(strings.TrimSpace(line)) > 0 {
+					return nil, fmt.Errorf("%s:%d: expected an entry starting with a timestamp but got '%s'", sourceName, lineNum, line)
+				}
+				continue
+			}
+			bodyLines = append(bodyLines, line)
+			continue
+		}
+
+		timestamp, err := parseJrnlTextTimestamp(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", sourceName, lineNum, err)
+		}
+		finishEntry()
+
+		titleLine := strings.TrimSpace(matches[2])
+		currentEntry = &ImportedEntry{
+			Timestamp:     timestamp,
+			Title:         getJrnlTitle(titleLine),
+			Body:          "",
+			Tags:          nil,
+			AreTagsInBody: true,
+			Source:        fmt.Sprintf("%s:%d", sourceName, lineNum),
+		}
+		bodyLines = append(bodyLines, titleLine)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("an error occurred reading %s: %w", sourceName, err)
+	}
+	finishEntry()
+
+	return result, nil
+}
+
+type jrnlJSONExport struct {
+	Entries []jrnlJSONEntry `json:"entries"`
+}
+
+type jrnlJSONEntry struct {
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Date  string   `json:"date"`
+	Time  string   `json:"time"`
+	Tags  []string `json:"tags"`
+}
+
+// ReadJrnlJSON reads jrnl's JSON export ('jrnl --export json')
+func ReadJrnlJSON(reader io.Reader, sourceName string) ([]ImportedEntry, error) {
+	var export jrnlJSONExport
+	if err := json.NewDecoder(reader).Decode(&export); err != nil {
+		return nil, fmt.Errorf("%s isn't a jrnl JSON export: %w", sourceName, err)
+	}
+
+	result := make([]ImportedEntry, 0, len(export.Entries))
+	for idx, jsonEntry := range export.Entries {
+		source := fmt.Sprintf("%s entry #%d", sourceName, idx+1)
+		timestamp, err := time.ParseInLocation(
+			jrnlJSONDateFormat+" "+jrnlJSONTimeFormat,
+			jsonEntry.Date+" "+jsonEntry.Time,
+			time.Local,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s has an invalid date or time: %w", source, err)
+		}
+
+		tags := make([]string, 0, len(jsonEntry.Tags))
+		for _, tag := range jsonEntry.Tags {
+			tags = append(tags, strings.TrimPrefix(tag, jrnlTagPrefix))
+		}
+
+		body := strings.TrimSpace(jsonEntry.Title)
+		if trimmedBody := strings.TrimSpace(jsonEntry.Body); len(trimmedBody) > 0 {
+			body += "\n\n" + trimmedBody
+		}
+
+		result = append(result, ImportedEntry{
+			Timestamp: timestamp,
+			Title:     jsonEntry.Title,
+			Body:      body + "\n",
+			Tags:      tags,
+			// jrnl's tags are the '@tags' in the text
+			AreTagsInBody: true,
+			Source:        source,
+		})
+	}
+	return result, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func parseJrnlTextTimestamp(timestampStr string) (time.Time, error) {
+	for _, format := range jrnlTextTimestampFormats {
+		if timestamp, err := time.ParseInLocation(format, timestampStr, time.Local); err == nil {
+			return timestamp, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp '%s'", timestampStr)
+}
+
+func getJrnlTitle(titleLine string) string {
+	if loc := jrnlTitleEndRegex.FindStringIndex(titleLine); loc != nil {
+		return titleLine[:loc[0]]
+	}
+	return titleLine
+}
+
+// getJrnlTags gets the text's '@tags' in the order they first appear
+func getJrnlTags(text string) []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, matches := range jrnlTagRegex.FindAllStringSubmatch(text, -1) {
+		tag := strings.ToLower(matches[1])
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
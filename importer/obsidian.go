@@ -0,0 +1,222 @@
+package importer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	obsidianNoteExtension   = ".md"
+	obsidianFrontMatterLine = "---"
+	obsidianTagPrefix       = "#"
+)
+
+// Front matter fields that hold the note's timestamp, in order of preference
+var obsidianTimestampFields = []string{"date", "created"}
+
+// Front matter fields that hold the note's tags
+var obsidianTagFields = []string{"tags", "tag"}
+
+var obsidianTimestampFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Obsidian tags can't be only digits
+var obsidianInlineTagRegex = regexp.MustCompile(`(?:^|\s)#([\w/-]*[a-zA-Z_/-][\w/-]*)`)
+
+// Daily notes are named after their date
+var obsidianDailyNoteNameRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+/*
+ReadObsidianVault reads every note in an Obsidian vault, skipping hidden directories like '.obsidian'
+
+A note's timestamp comes from its front matter's 'date' or 'created' field, then its name if it's a daily note, and
+otherwise the file's modification time. Its tags are its front matter tags, then its inline '#tags', then the folder
+it's in, so mapping rules can use any of them.
+*/
+func ReadObsidianVault(vaultDirpath string) ([]ImportedEntry, error) {
+	result := make([]ImportedEntry, 0)
+	err := filepath.WalkDir(vaultDirpath, func(filepathStr string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(dirEntry.Name(), ".") && filepathStr != vaultDirpath {
+			if dirEntry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if dirEntry.IsDir() || filepath.Ext(dirEntry.Name()) != obsidianNoteExtension {
+			return nil
+		}
+
+		relativeFilepath, err := filepath.Rel(vaultDirpath, filepathStr)
+		if err != nil {
+			return err
+		}
+		entry, err := readObsidianNote(filepathStr, filepath.ToSlash(relativeFilepath))
+		if err != nil {
+			return err
+		}
+		result = append(result, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred reading Obsidian vault '%s': %w", vaultDirpath, err)
+	}
+	return result, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func readObsidianNote(filepathStr string, relativeFilepath string) (ImportedEntry, error) {
+	contents, err := os.ReadFile(filepathStr)
+	if err != nil {
+		return ImportedEntry{}, err
+	}
+	fileInfo, err := os.Stat(filepathStr)
+	if err != nil {
+		return ImportedEntry{}, err
+	}
+
+	frontMatter, body := splitFrontMatter(string(contents))
+	title := strings.TrimSuffix(path.Base(relativeFilepath), obsidianNoteExtension)
+
+	timestamp := fileInfo.ModTime()
+	if obsidianDailyNoteNameRegex.MatchString(title) {
+		if parsed, err := time.ParseInLocation("2006-01-02", title, time.Local); err == nil {
+			timestamp = parsed
+		}
+	}
+	for _, field := range obsidianTimestampFields {
+		if parsed, found := parseObsidianTimestamp(frontMatter[field]); found {
+			timestamp = parsed
+			break
+		}
+	}
+
+	tags := make([]string, 0)
+	for _, field := range obsidianTagFields {
+		tags = append(tags, parseFrontMatterList(frontMatter[field])...)
+	}
+	for _, matches := range obsidianInlineTagRegex.FindAllStringSubmatch(body, -1) {
+		tags = append(tags, matches[1])
+	}
+	if folder := path.Dir(relativeFilepath); folder != "." {
+		tags = append(tags, folder)
+	}
+
+	return ImportedEntry{
+		Timestamp:     timestamp,
+		Title:         title,
+		Body:          strings.TrimSpace(body) + "\n",
+		Tags:          dedupeTags(tags),
+		AreTagsInBody: false,
+		Source:        relativeFilepath,
+	}, nil
+}
+
+/*
+splitFrontMatter splits a note into its front matter fields and the rest of the note
+
+Only the simple YAML that Obsidian writes is understood: 'key: value' lines, where the value may be an inline list
+('[a, b]') or followed by '- item' lines. List values are kept as newline-separated strings.
+*/
+func splitFrontMatter(contents string) (map[string]string, string) {
+	fields := make(map[string]string)
+	lines := strings.Split(contents, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != obsidianFrontMatterLine {
+		return fields, contents
+	}
+
+	currentKey := ""
+	for idx := 1; idx < len(lines); idx++ {
+		line := strings.TrimRight(lines[idx], "\r")
+		if strings.TrimSpace(line) == obsidianFrontMatterLine {
+			return fields, strings.Join(lines[idx+1:], "\n")
+		}
+
+		trimmedLine := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmedLine, "- ") && len(currentKey) > 0 {
+			fields[currentKey] = strings.TrimSpace(fields[currentKey] + "\n" + unquoteYAML(strings.TrimPrefix(trimmedLine, "- ")))
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		currentKey = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			items := make([]string, 0)
+			for _, item := range strings.Split(strings.Trim(value, "[]"), ",") {
+				items = append(items, unquoteYAML(strings.TrimSpace(item)))
+			}
+			value = strings.Join(items, "\n")
+		}
+		fields[currentKey] = unquoteYAML(value)
+	}
+
+	// No closing line, so it wasn't front matter after all
+	return map[string]string{}, contents
+}
+
+// parseFrontMatterList splits a front matter value into its items, which may also be separated by spaces or commas
+func parseFrontMatterList(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == '\n' || r == ',' || r == ' '
+	}) {
+		if item = strings.TrimPrefix(item, obsidianTagPrefix); len(item) > 0 {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func parseObsidianTimestamp(value string) (time.Time, bool) {
+	if len(value) == 0 {
+		return time.Time{}, false
+	}
+	for _, format := range obsidianTimestampFormats {
+		if timestamp, err := time.ParseInLocation(format, value, time.Local); err == nil {
+			return timestamp, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func unquoteYAML(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func dedupeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"strings"
+)
+
+const (
+	tagMappingRuleSeparator = "="
+	tagMappingWildcard      = "*"
+)
+
+// A rule turning an imported tag into a journal tag
+// If the imported pattern ends in a '*' it matches every tag starting with what's before it, and a '*' in the journal
+// tag is replaced with the rest of the imported tag
+type tagMappingRule struct {
+	importedPattern string
+	journalTag      string
+}
+
+/*
+TagMapper decides which of an imported entry's tags becomes its tag in the journal, since entries have only one
+
+The entry's tags are tried in order against the rules in order, and the first match wins. If no rule matches any of
+them, the default tag is used if there is one, and otherwise the entry's first tag (as-is) or no tag if it has none.
+*/
+type TagMapper struct {
+	rules      []tagMappingRule
+	defaultTag string
+}
+
+// NewTagMapper parses rules of the form 'IMPORTED=JOURNAL' (e.g. 'wealthdraft=project-support/wealthdraft' or
+// 'area/*=areas/*'); an empty journal tag means entries with the imported tag end up untagged
+func NewTagMapper(ruleStrs []string, defaultTag string) (TagMapper, error) {
+	rules := make([]tagMappingRule, 0, len(ruleStrs))
+	for _, ruleStr := range ruleStrs {
+		importedPattern, journalTag, found := strings.Cut(ruleStr, tagMappingRuleSeparator)
+		if !found || len(importedPattern) == 0 {
+			return TagMapper{}, fmt.Errorf("tag mapping rule '%s' isn't of the form 'IMPORTED%sJOURNAL'", ruleStr, tagMappingRuleSeparator)
+		}
+		if strings.Contains(strings.TrimSuffix(importedPattern, tagMappingWildcard), tagMappingWildcard) {
+			return TagMapper{}, fmt.Errorf("tag mapping rule '%s' can only have a '%s' at the end of the imported tag", ruleStr, tagMappingWildcard)
+		}
+		if err := journal_store.ValidateTag(strings.ReplaceAll(journalTag, tagMappingWildcard, "x")); err != nil {
+			return TagMapper{}, fmt.Errorf("tag mapping rule '%s' maps to an invalid tag: %w", ruleStr, err)
+		}
+		rules = append(rules, tagMappingRule{importedPattern: importedPattern, journalTag: journalTag})
+	}
+
+	if err := journal_store.ValidateTag(defaultTag); err != nil {
+		return TagMapper{}, fmt.Errorf("the default tag is invalid: %w", err)
+	}
+	return TagMapper{
+		rules:      rules,
+		defaultTag: defaultTag,
+	}, nil
+}
+
+// GetTag gets the journal tag for an entry with the given imported tags
+func (mapper TagMapper) GetTag(importedTags []string) (string, error) {
+	for _, importedTag := range importedTags {
+		for _, rule := range mapper.rules {
+			journalTag, isMatch := rule.apply(importedTag)
+			if !isMatch {
+				continue
+			}
+			if err := journal_store.ValidateTag(journalTag); err != nil {
+				return "", fmt.Errorf("imported tag '%s' maps to an invalid tag: %w", importedTag, err)
+			}
+			return journalTag, nil
+		}
+	}
+
+	if len(mapper.defaultTag) > 0 || len(importedTags) == 0 {
+		return mapper.defaultTag, nil
+	}
+	if err := journal_store.ValidateTag(importedTags[0]); err != nil {
+		return "", fmt.Errorf("imported tag '%s' can't be used as a tag, so it needs a mapping rule: %w", importedTags[0], err)
+	}
+	return importedTags[0], nil
+}
+
+func (rule tagMappingRule) apply(importedTag string) (string, bool) {
+	if !strings.HasSuffix(rule.importedPattern, tagMappingWildcard) {
+		return rule.journalTag, importedTag == rule.importedPattern
+	}
+
+	prefix := strings.TrimSuffix(rule.importedPattern, tagMappingWildcard)
+	if !strings.HasPrefix(importedTag, prefix) {
+		return "", false
+	}
+	return strings.ReplaceAll(rule.journalTag, tagMappingWildcard, strings.TrimPrefix(importedTag, prefix)), true
+}
@@ -0,0 +1,100 @@
+package journal_store
+
+import (
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/entry_crypto"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+const (
+	editCopyDirPattern = "cli-journal-edit-"
+	editCopyFilePerms  = 0600
+)
+
+// OpenForEditing gets a file that another program (e.g. an editor) can edit the entry in, along with a function to
+// call once it's done
+// Plaintext entries are edited in place. Encrypted ones are decrypted into a copy in a private temporary directory
+// (kept in memory where the system allows), which the done function encrypts back into the entry before removing it.
+func (store Store) OpenForEditing(entryPath string) (string, func() error, error) {
+	contents, err := store.readContents(entryPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if !entry_crypto.IsEncrypted(contents) {
+		done := func() error {
+			store.NotifyEdited(entryPath)
+			return nil
+		}
+		return store.GetAbsolutePath(entryPath), done, nil
+	}
+
+	body, err := store.decryptContents(entryPath, contents)
+	if err != nil {
+		return "", nil, err
+	}
+	copyDirpath, err := makePrivateTempDir()
+	if err != nil {
+		return "", nil, err
+	}
+	// The copy keeps the entry's filename, so editors recognize it the same way
+	copyFilepath := filepath.Join(copyDirpath, path.Base(entryPath))
+	if err := os.WriteFile(copyFilepath, []byte(body), editCopyFilePerms); err != nil {
+		os.RemoveAll(copyDirpath)
+		return "", nil, fmt.Errorf("an error occurred writing the decrypted copy of entry '%s': %w", entryPath, err)
+	}
+
+	done := func() error {
+		editedBody, err := os.ReadFile(copyFilepath)
+		if err != nil {
+			return fmt.Errorf("an error occurred reading the edited copy of entry '%s' at '%s': %w", entryPath, copyFilepath, err)
+		}
+		if string(editedBody) != body {
+			if _, err := store.Write(entryPath, string(editedBody)); err != nil {
+				// The copy is left behind so the edits aren't lost
+				return fmt.Errorf("the edits couldn't be saved, and are still in '%s': %w", copyFilepath, err)
+			}
+		}
+		return removePrivateTempDir(copyDirpath, copyFilepath)
+	}
+	return copyFilepath, done, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// makePrivateTempDir makes a directory that only the user can read, preferring ones backed by memory (tmpfs) so that
+// decrypted entries never reach the disk
+func makePrivateTempDir() (string, error) {
+	candidateDirpaths := []string{os.Getenv("XDG_RUNTIME_DIR"), "/dev/shm", os.TempDir()}
+	for _, candidateDirpath := range candidateDirpaths {
+		if len(candidateDirpath) == 0 {
+			continue
+		}
+		if info, err := os.Stat(candidateDirpath); err != nil || !info.IsDir() {
+			continue
+		}
+		// Created with permissions 0700
+		dirpath, err := os.MkdirTemp(candidateDirpath, editCopyDirPattern)
+		if err == nil {
+			return dirpath, nil
+		}
+	}
+	return "", fmt.Errorf("couldn't make a private temporary directory to edit the decrypted entry in")
+}
+
+// removePrivateTempDir overwrites the decrypted copy before removing it, in case the directory is on disk after all
+func removePrivateTempDir(dirpath string, copyFilepath string) error {
+	if info, err := os.Stat(copyFilepath); err == nil {
+		if err := os.WriteFile(copyFilepath, make([]byte, info.Size()), editCopyFilePerms); err != nil {
+			return fmt.Errorf("an error occurred overwriting the decrypted copy at '%s': %w", copyFilepath, err)
+		}
+	}
+	if err := os.RemoveAll(dirpath); err != nil {
+		return fmt.Errorf("an error occurred removing the decrypted copy at '%s': %w", copyFilepath, err)
+	}
+	return nil
+}
@@ -0,0 +1,563 @@
+package journal_store
+
+import (
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/entry_crypto"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// Entries carry their creation time at the start of their filename, since filesystems don't reliably keep it
+	timestampPrefixFormat    = "20060102-150405"
+	timestampPrefixSeparator = "_"
+
+	// Previews longer than this get cut off at a word boundary
+	maxPreviewLength = 200
+
+	entryFilePerms      = 0644
+	entryDirPerms       = 0755
+	hiddenEntryPrefix   = "."
+	sentenceTerminators = ".!?"
+)
+
+// Names are kept to characters that are safe in filenames & shell scripts
+var acceptableEntryNameRegex = regexp.MustCompile("^[a-zA-Z0-9.-]+$")
+var invalidEntryNameCharsRegex = regexp.MustCompile("[^a-zA-Z0-9.]+")
+
+/*
+Store reads & writes the journal's entries, which live as files under the journal root
+
+An entry's tag is the directory it's in (relative to the root), so each entry has at most one tag. Files & directories
+starting with a '.' (e.g. '.git') aren't entries.
+
+Entries can be encrypted on disk, which the store takes care of: their bodies are read & written as plaintext while
+the journal's keyring is unlocked. Entries in encrypted tags are always encrypted, and other entries are encrypted if
+they've been encrypted with SetEncrypted.
+*/
+type Store struct {
+	rootDirpath string
+
+	// Nil if nothing is listening
+	changeListener ChangeListener
+
+	// Shared by every copy of the store, so unlocking one unlocks them all
+	keyring *entry_crypto.Keyring
+
+	// Entries with these tags (or tags inside them) are encrypted
+	encryptedTags []string
+}
+
+// ChangeListener is told about every change the store makes to entries (e.g. to record them in version control)
+type ChangeListener interface {
+	// OnEntriesChanged gets the root-relative paths that were created, written, or removed
+	OnEntriesChanged(entryPaths ...string)
+}
+
+func New(rootDirpath string) Store {
+	return Store{
+		rootDirpath:    rootDirpath,
+		changeListener: nil,
+		keyring:        entry_crypto.NewKeyring(rootDirpath),
+		encryptedTags:  []string{},
+	}
+}
+
+// WithChangeListener gets a store for the same journal that tells the listener about the changes it makes
+func (store Store) WithChangeListener(listener ChangeListener) Store {
+	store.changeListener = listener
+	return store
+}
+
+// WithEncryptedTags gets a store for the same journal that encrypts the entries it creates or writes with the given
+// tags (or tags inside them)
+func (store Store) WithEncryptedTags(tags []string) Store {
+	store.encryptedTags = tags
+	return store
+}
+
+// GetKeyring gets the keyring that the journal's encrypted entries are read & written with, for locking & unlocking
+func (store Store) GetKeyring() *entry_crypto.Keyring {
+	return store.keyring
+}
+
+// IsEncryptedTag gets whether entries with the tag are always encrypted
+func (store Store) IsEncryptedTag(tag string) bool {
+	for _, encryptedTag := range store.encryptedTags {
+		if tag == encryptedTag || strings.HasPrefix(tag, encryptedTag+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyEdited tells the change listener about an entry that was changed without going through the store (e.g. in an
+// editor)
+func (store Store) NotifyEdited(entryPath string) {
+	store.notifyChanged(entryPath)
+}
+
+func (store Store) GetRootDirpath() string {
+	return store.rootDirpath
+}
+
+// GetAbsolutePath gets the location on disk of the entry at the given root-relative path
+func (store Store) GetAbsolutePath(entryPath string) string {
+	return filepath.Join(store.rootDirpath, filepath.FromSlash(entryPath))
+}
+
+// List gets every entry in the journal, newest first
+func (store Store) List() ([]content_item.ContentItem, error) {
+	result := make([]content_item.ContentItem, 0)
+	err := filepath.WalkDir(store.rootDirpath, func(absPath string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if absPath == store.rootDirpath {
+			return nil
+		}
+		if strings.HasPrefix(dirEntry.Name(), hiddenEntryPrefix) {
+			if dirEntry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if dirEntry.IsDir() || !dirEntry.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(store.rootDirpath, absPath)
+		if err != nil {
+			return fmt.Errorf("an error occurred getting the path of '%s' relative to the journal root: %w", absPath, err)
+		}
+		item, err := store.Get(filepath.ToSlash(relPath))
+		if err != nil {
+			return err
+		}
+		result = append(result, item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred listing the entries in journal '%s': %w", store.rootDirpath, err)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.After(result[j].Timestamp)
+	})
+	return result, nil
+}
+
+// Get reads the entry at the given root-relative path
+func (store Store) Get(entryPath string) (content_item.ContentItem, error) {
+	absPath := store.GetAbsolutePath(entryPath)
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return content_item.ContentItem{}, fmt.Errorf("an error occurred getting info for entry '%s': %w", entryPath, err)
+	}
+	contents, err := store.readContents(entryPath)
+	if err != nil {
+		return content_item.ContentItem{}, err
+	}
+	isEncrypted := entry_crypto.IsEncrypted(contents)
+	body := string(contents)
+	if isEncrypted {
+		// Locked entries are listed by name alone, rather than asking for the passphrase just to list them
+		body = ""
+		if store.keyring.IsUnlocked() {
+			if body, err = store.decryptContents(entryPath, contents); err != nil {
+				return content_item.ContentItem{}, err
+			}
+		}
+	}
+
+	tag := path.Dir(entryPath)
+	tags := []string{}
+	if tag != "." {
+		tags = []string{tag}
+	}
+
+	name, timestamp, found := parseFilename(path.Base(entryPath))
+	if !found {
+		timestamp = info.ModTime()
+	}
+
+	return content_item.ContentItem{
+		Timestamp:    timestamp,
+		LastModified: info.ModTime(),
+		Name:         name,
+		Tags:         tags,
+		Preview:      getPreview(body),
+		WordCount:    len(strings.Fields(body)),
+		IsEncrypted:  isEncrypted,
+		Path:         entryPath,
+	}, nil
+}
+
+// Read gets the body of the entry at the given root-relative path, decrypting it if it's encrypted
+func (store Store) Read(entryPath string) (string, error) {
+	contents, err := store.readContents(entryPath)
+	if err != nil {
+		return "", err
+	}
+	return store.decryptContents(entryPath, contents)
+}
+
+// DecryptContents gets the body from what was in the entry's file at some point (e.g. in an older version of it),
+// decrypting it if it's encrypted
+func (store Store) DecryptContents(entryPath string, contents string) (string, error) {
+	return store.decryptContents(entryPath, []byte(contents))
+}
+
+// Create writes a new entry, refusing to overwrite an existing one
+// An empty tag puts the entry at the journal root
+func (store Store) Create(timestamp time.Time, name string, tag string, body string) (content_item.ContentItem, error) {
+	entryPath, err := GetEntryPath(timestamp, name, tag)
+	if err != nil {
+		return content_item.ContentItem{}, err
+	}
+	return store.CreateAtPath(entryPath, body)
+}
+
+// CreateAtPath writes a new entry at the given root-relative path, refusing to overwrite an existing one
+func (store Store) CreateAtPath(entryPath string, body string) (content_item.ContentItem, error) {
+	return store.createAtPath(entryPath, body, store.IsEncryptedTag(path.Dir(entryPath)))
+}
+
+// CreateEncryptedAtPath is like CreateAtPath, but encrypts the entry whatever its tag
+func (store Store) CreateEncryptedAtPath(entryPath string, body string) (content_item.ContentItem, error) {
+	return store.createAtPath(entryPath, body, true)
+}
+
+// Write replaces the body of an existing entry, keeping it encrypted if it was
+func (store Store) Write(entryPath string, body string) (content_item.ContentItem, error) {
+	oldContents, err := store.readContents(entryPath)
+	if err != nil {
+		return content_item.ContentItem{}, err
+	}
+	isEncrypted := entry_crypto.IsEncrypted(oldContents) || store.IsEncryptedTag(path.Dir(entryPath))
+	return store.writeContents(entryPath, body, isEncrypted)
+}
+
+// SetEncrypted encrypts or decrypts the entry on disk, refusing to decrypt entries in encrypted tags
+func (store Store) SetEncrypted(entryPath string, isEncrypted bool) (content_item.ContentItem, error) {
+	tag := path.Dir(entryPath)
+	if !isEncrypted && store.IsEncryptedTag(tag) {
+		return content_item.ContentItem{}, fmt.Errorf("entries tagged '%s' are always encrypted", tag)
+	}
+	body, err := store.Read(entryPath)
+	if err != nil {
+		return content_item.ContentItem{}, err
+	}
+	return store.writeContents(entryPath, body, isEncrypted)
+}
+
+// SetLastModified sets the entry's modification time, e.g. to put back the one it had before being deleted & recreated
+func (store Store) SetLastModified(entryPath string, lastModified time.Time) error {
+	if err := os.Chtimes(store.GetAbsolutePath(entryPath), lastModified, lastModified); err != nil {
+		return fmt.Errorf("an error occurred setting the modification time of entry '%s': %w", entryPath, err)
+	}
+	return nil
+}
+
+// Move gives the entry a new tag and/or name (keeping its timestamp), refusing to overwrite an existing entry
+func (store Store) Move(entryPath string, newTag string, newName string) (content_item.ContentItem, error) {
+	newEntryPath, err := GetMovedEntryPath(entryPath, newTag, newName)
+	if err != nil {
+		return content_item.ContentItem{}, err
+	}
+	if newEntryPath == entryPath {
+		return store.Get(entryPath)
+	}
+
+	if store.Exists(newEntryPath) {
+		return content_item.ContentItem{}, fmt.Errorf("can't move entry '%s' to '%s' because an entry already exists there", entryPath, newEntryPath)
+	}
+
+	// Entries moved into an encrypted tag get encrypted, so the passphrase is needed before anything is moved
+	body := ""
+	needsEncrypting := false
+	if store.IsEncryptedTag(newTag) {
+		contents, err := store.readContents(entryPath)
+		if err != nil {
+			return content_item.ContentItem{}, err
+		}
+		needsEncrypting = !entry_crypto.IsEncrypted(contents)
+		body = string(contents)
+	}
+	if needsEncrypting {
+		if err := store.keyring.EnsureUnlocked(); err != nil {
+			return content_item.ContentItem{}, fmt.Errorf("entries tagged '%s' are encrypted: %w", newTag, err)
+		}
+	}
+
+	if needsEncrypting {
+		// Encrypted into the new file before the old one is removed, so that a failure part-way never leaves the entry
+		// in plaintext in the encrypted tag
+		contents, err := store.encodeBody(newEntryPath, body, true)
+		if err != nil {
+			return content_item.ContentItem{}, err
+		}
+		if err := store.createFile(newEntryPath, contents); err != nil {
+			return content_item.ContentItem{}, err
+		}
+		if err := os.Remove(store.GetAbsolutePath(entryPath)); err != nil {
+			_ = os.Remove(store.GetAbsolutePath(newEntryPath))
+			store.removeEmptyTagDirs(newTag)
+			return content_item.ContentItem{}, fmt.Errorf("an error occurred removing entry '%s' after encrypting it into '%s': %w", entryPath, newEntryPath, err)
+		}
+	} else {
+		newAbsPath := store.GetAbsolutePath(newEntryPath)
+		if err := os.MkdirAll(filepath.Dir(newAbsPath), entryDirPerms); err != nil {
+			return content_item.ContentItem{}, fmt.Errorf("an error occurred creating the directory for entry '%s': %w", newEntryPath, err)
+		}
+		if err := os.Rename(store.GetAbsolutePath(entryPath), newAbsPath); err != nil {
+			return content_item.ContentItem{}, fmt.Errorf("an error occurred moving entry '%s' to '%s': %w", entryPath, newEntryPath, err)
+		}
+	}
+	store.removeEmptyTagDirs(path.Dir(entryPath))
+	store.notifyChanged(entryPath, newEntryPath)
+	return store.Get(newEntryPath)
+}
+
+// Exists gets whether there's an entry (or anything else) at the given root-relative path
+func (store Store) Exists(entryPath string) bool {
+	_, err := os.Stat(store.GetAbsolutePath(entryPath))
+	return err == nil
+}
+
+// Delete removes the entry, along with its tag directory if that leaves it empty
+func (store Store) Delete(entryPath string) error {
+	if err := os.Remove(store.GetAbsolutePath(entryPath)); err != nil {
+		return fmt.Errorf("an error occurred deleting entry '%s': %w", entryPath, err)
+	}
+	store.removeEmptyTagDirs(path.Dir(entryPath))
+	store.notifyChanged(entryPath)
+	return nil
+}
+
+// GetEntryPath gets the root-relative path that an entry with the given properties lives at
+func GetEntryPath(timestamp time.Time, name string, tag string) (string, error) {
+	return getUntimestampedEntryPath(timestamp.Format(timestampPrefixFormat)+timestampPrefixSeparator+name, tag)
+}
+
+// GetMovedEntryPath gets the root-relative path that the entry would live at if it was given the tag and name, keeping
+// its timestamp
+func GetMovedEntryPath(entryPath string, newTag string, newName string) (string, error) {
+	_, timestamp, found := parseFilename(path.Base(entryPath))
+	if !found {
+		// Keep the entry looking like it did, rather than giving it a timestamp it didn't have
+		return getUntimestampedEntryPath(newName, newTag)
+	}
+	return GetEntryPath(timestamp, newName, newTag)
+}
+
+// IsValidEntryName gets whether the name is one that an entry can have
+func IsValidEntryName(name string) bool {
+	return acceptableEntryNameRegex.MatchString(name)
+}
+
+// ToEntryName turns free text (e.g. the first line of a note) into a valid entry name, returning false if the text has
+// nothing usable
+func ToEntryName(text string, maxLength int) (string, bool) {
+	name := strings.ToLower(strings.TrimSpace(text))
+	name = invalidEntryNameCharsRegex.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-.")
+	if len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-.")
+	}
+	return name, IsValidEntryName(name)
+}
+
+// DescribeTag gets how a tag is shown to the user, e.g. "#work", or "untagged" for entries at the journal root
+func DescribeTag(tag string) string {
+	if len(tag) == 0 {
+		return "untagged"
+	}
+	return "#" + tag
+}
+
+// ValidateTag checks that the tag can be used as a directory inside the journal root
+func ValidateTag(tag string) error {
+	if len(tag) == 0 {
+		return nil
+	}
+	if strings.HasPrefix(tag, "/") || strings.HasSuffix(tag, "/") {
+		return fmt.Errorf("tag '%s' can't start or end with a '/'", tag)
+	}
+	for _, component := range strings.Split(tag, "/") {
+		if len(component) == 0 || component == "." || component == ".." || strings.HasPrefix(component, hiddenEntryPrefix) {
+			return fmt.Errorf("tag '%s' has invalid part '%s'", tag, component)
+		}
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getUntimestampedEntryPath(filename string, tag string) (string, error) {
+	if len(filename) == 0 || strings.ContainsAny(filename, "/\\") || strings.HasPrefix(filename, hiddenEntryPrefix) {
+		return "", fmt.Errorf("'%s' isn't a valid entry filename", filename)
+	}
+	if err := ValidateTag(tag); err != nil {
+		return "", err
+	}
+	return path.Join(tag, filename), nil
+}
+
+// parseFilename splits the entry's filename into its name and timestamp, returning false if it has no timestamp
+func parseFilename(filename string) (string, time.Time, bool) {
+	prefixLength := len(timestampPrefixFormat) + len(timestampPrefixSeparator)
+	if len(filename) <= prefixLength || filename[len(timestampPrefixFormat):prefixLength] != timestampPrefixSeparator {
+		return filename, time.Time{}, false
+	}
+	timestamp, err := time.ParseInLocation(timestampPrefixFormat, filename[:len(timestampPrefixFormat)], time.Local)
+	if err != nil {
+		return filename, time.Time{}, false
+	}
+	return filename[prefixLength:], timestamp, true
+}
+
+func (store Store) createAtPath(entryPath string, body string, isEncrypted bool) (content_item.ContentItem, error) {
+	// Encrypted first, so nothing is created if the journal is locked
+	contents, err := store.encodeBody(entryPath, body, isEncrypted)
+	if err != nil {
+		return content_item.ContentItem{}, err
+	}
+
+	if err := store.createFile(entryPath, contents); err != nil {
+		return content_item.ContentItem{}, err
+	}
+	store.notifyChanged(entryPath)
+
+	return store.Get(entryPath)
+}
+
+// createFile writes the contents to a new file for the entry, refusing to overwrite an existing one
+func (store Store) createFile(entryPath string, contents []byte) error {
+	absPath := store.GetAbsolutePath(entryPath)
+	if err := os.MkdirAll(filepath.Dir(absPath), entryDirPerms); err != nil {
+		return fmt.Errorf("an error occurred creating the directory for entry '%s': %w", entryPath, err)
+	}
+	file, err := os.OpenFile(absPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, entryFilePerms)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("entry '%s' already exists", entryPath)
+		}
+		return fmt.Errorf("an error occurred creating entry '%s': %w", entryPath, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(contents); err != nil {
+		// Don't leave a partly-written entry behind
+		_ = os.Remove(absPath)
+		return fmt.Errorf("an error occurred writing entry '%s': %w", entryPath, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("an error occurred closing entry '%s': %w", entryPath, err)
+	}
+	return nil
+}
+
+func (store Store) readContents(entryPath string) ([]byte, error) {
+	contents, err := os.ReadFile(store.GetAbsolutePath(entryPath))
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred reading entry '%s': %w", entryPath, err)
+	}
+	return contents, nil
+}
+
+func (store Store) decryptContents(entryPath string, contents []byte) (string, error) {
+	if !entry_crypto.IsEncrypted(contents) {
+		return string(contents), nil
+	}
+	plaintext, err := store.keyring.Decrypt(contents)
+	if err != nil {
+		return "", fmt.Errorf("an error occurred decrypting entry '%s': %w", entryPath, err)
+	}
+	return string(plaintext), nil
+}
+
+// encodeBody gets what goes in the entry's file for the body
+func (store Store) encodeBody(entryPath string, body string, isEncrypted bool) ([]byte, error) {
+	if !isEncrypted {
+		return []byte(body), nil
+	}
+	contents, err := store.keyring.Encrypt([]byte(body))
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred encrypting entry '%s': %w", entryPath, err)
+	}
+	return contents, nil
+}
+
+// writeContents replaces the body of an existing entry, encrypting it or not
+func (store Store) writeContents(entryPath string, body string, isEncrypted bool) (content_item.ContentItem, error) {
+	contents, err := store.encodeBody(entryPath, body, isEncrypted)
+	if err != nil {
+		return content_item.ContentItem{}, err
+	}
+	if err := os.WriteFile(store.GetAbsolutePath(entryPath), contents, entryFilePerms); err != nil {
+		return content_item.ContentItem{}, fmt.Errorf("an error occurred writing entry '%s': %w", entryPath, err)
+	}
+	store.notifyChanged(entryPath)
+	return store.Get(entryPath)
+}
+
+func (store Store) notifyChanged(entryPaths ...string) {
+	if store.changeListener != nil {
+		store.changeListener.OnEntriesChanged(entryPaths...)
+	}
+}
+
+// removeEmptyTagDirs removes the tag's directory if it's empty, and then its parents, stopping at the journal root
+func (store Store) removeEmptyTagDirs(tag string) {
+	for tag != "." && tag != "/" && len(tag) > 0 {
+		// Fails if the directory isn't empty, which is what we want
+		if err := os.Remove(store.GetAbsolutePath(tag)); err != nil {
+			return
+		}
+		tag = path.Dir(tag)
+	}
+}
+
+// getPreview gets the first sentence of the body, skipping blank lines and markdown heading markers
+func getPreview(body string) string {
+	firstLine := ""
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		if len(line) > 0 {
+			firstLine = line
+			break
+		}
+	}
+
+	preview := firstLine
+	for idx, char := range firstLine {
+		if !strings.ContainsRune(sentenceTerminators, char) {
+			continue
+		}
+		nextIdx := idx + 1
+		if nextIdx == len(firstLine) || unicode.IsSpace(rune(firstLine[nextIdx])) {
+			preview = firstLine[:nextIdx]
+			break
+		}
+	}
+
+	if previewRunes := []rune(preview); len(previewRunes) > maxPreviewLength {
+		cutPreview := string(previewRunes[:maxPreviewLength])
+		if spaceIdx := strings.LastIndex(cutPreview, " "); spaceIdx > 0 {
+			cutPreview = cutPreview[:spaceIdx]
+		}
+		preview = strings.TrimSpace(cutPreview) + "…"
+	}
+	return preview
+}
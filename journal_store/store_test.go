@@ -0,0 +1,145 @@
+package journal_store
+
+import (
+	"errors"
+	"github.com/mieubrisse/cli-journal-go/entry_crypto"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreatedEntriesKeepTheirTimestampAndTag(t *testing.T) {
+	store := New(t.TempDir())
+	timestamp := time.Date(2023, time.April, 15, 10, 15, 0, 0, time.Local)
+	if _, err := store.Create(timestamp, "standup.md", "work/meetings", "Talked about things. Then more."); err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+
+	items, err := store.List()
+	if err != nil {
+		t.Fatalf("Expected no error listing entries but got: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 entry but got %d", len(items))
+	}
+	item := items[0]
+	if item.Name != "standup.md" || !item.Timestamp.Equal(timestamp) {
+		t.Errorf("Expected entry 'standup.md' at %v but got '%s' at %v", timestamp, item.Name, item.Timestamp)
+	}
+	if len(item.Tags) != 1 || item.Tags[0] != "work/meetings" {
+		t.Errorf("Expected tag 'work/meetings' but got %v", item.Tags)
+	}
+	if item.Preview != "Talked about things." || item.WordCount != 5 {
+		t.Errorf("Expected the first sentence & 5 words but got preview '%s' with %d words", item.Preview, item.WordCount)
+	}
+}
+
+func TestMoveRefusesToOverwrite(t *testing.T) {
+	store := New(t.TempDir())
+	timestamp := time.Date(2023, time.April, 15, 10, 15, 0, 0, time.Local)
+	first, err := store.Create(timestamp, "notes.md", "a", "first")
+	if err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+	if _, err := store.Create(timestamp, "notes.md", "b", "second"); err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+
+	if _, err := store.Move(first.Path, "b", "notes.md"); err == nil {
+		t.Errorf("Expected moving onto an existing entry to fail")
+	}
+	body, err := store.Read(first.Path)
+	if err != nil || body != "first" {
+		t.Errorf("Expected the entry to be left alone, but got body '%s' and error: %v", body, err)
+	}
+}
+
+func TestEntriesInEncryptedTagsAreUnreadableOnDisk(t *testing.T) {
+	store := New(t.TempDir()).WithEncryptedTags([]string{"health"})
+	if err := store.GetKeyring().SetPassphrase("hunter2"); err != nil {
+		t.Fatalf("Expected no error setting the passphrase but got: %v", err)
+	}
+	timestamp := time.Date(2023, time.April, 15, 10, 15, 0, 0, time.Local)
+	item, err := store.Create(timestamp, "checkup.md", "health/doctor", "Blood pressure is fine.")
+	if err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+
+	onDisk, err := os.ReadFile(store.GetAbsolutePath(item.Path))
+	if err != nil {
+		t.Fatalf("Expected no error reading the entry's file but got: %v", err)
+	}
+	if !item.IsEncrypted || strings.Contains(string(onDisk), "Blood") {
+		t.Fatalf("Expected the entry to be encrypted on disk but got:\n%s", onDisk)
+	}
+
+	editPath, done, err := store.OpenForEditing(item.Path)
+	if err != nil {
+		t.Fatalf("Expected no error opening the entry for editing but got: %v", err)
+	}
+	if err := os.WriteFile(editPath, []byte("Blood pressure is a bit high."), 0600); err != nil {
+		t.Fatalf("Expected no error editing the copy but got: %v", err)
+	}
+	if err := done(); err != nil {
+		t.Fatalf("Expected no error saving the edits but got: %v", err)
+	}
+	if _, err := os.Stat(editPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the decrypted copy to be removed, but got: %v", err)
+	}
+
+	store.GetKeyring().Lock()
+	locked, err := store.Get(item.Path)
+	if err != nil {
+		t.Fatalf("Expected locked entries to still be listed but got: %v", err)
+	}
+	if locked.Name != "checkup.md" || len(locked.Preview) > 0 {
+		t.Errorf("Expected the name without a preview while locked, but got '%s' with preview '%s'", locked.Name, locked.Preview)
+	}
+	if _, err := store.Read(item.Path); !errors.Is(err, entry_crypto.ErrLocked) {
+		t.Errorf("Expected reading while locked to fail with ErrLocked but got: %v", err)
+	}
+
+	if err := store.GetKeyring().Unlock("hunter2"); err != nil {
+		t.Fatalf("Expected no error unlocking but got: %v", err)
+	}
+	body, err := store.Read(item.Path)
+	if err != nil || body != "Blood pressure is a bit high." {
+		t.Errorf("Expected the edited body but got '%s' and error: %v", body, err)
+	}
+}
+
+func TestMovingIntoAnEncryptedTagNeverLeavesPlaintextThere(t *testing.T) {
+	store := New(t.TempDir()).WithEncryptedTags([]string{"health"})
+	if err := store.GetKeyring().SetPassphrase("hunter2"); err != nil {
+		t.Fatalf("Expected no error setting the passphrase but got: %v", err)
+	}
+	timestamp := time.Date(2023, time.April, 15, 10, 15, 0, 0, time.Local)
+	item, err := store.Create(timestamp, "checkup.md", "inbox", "Blood pressure is fine.")
+	if err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+
+	store.GetKeyring().Lock()
+	if _, err := store.Move(item.Path, "health", "checkup.md"); !errors.Is(err, entry_crypto.ErrLocked) {
+		t.Fatalf("Expected moving into an encrypted tag while locked to fail with ErrLocked but got: %v", err)
+	}
+	if !store.Exists(item.Path) || store.Exists("health") {
+		t.Errorf("Expected the failed move to leave the entry where it was, and nothing in the encrypted tag")
+	}
+
+	if err := store.GetKeyring().Unlock("hunter2"); err != nil {
+		t.Fatalf("Expected no error unlocking but got: %v", err)
+	}
+	moved, err := store.Move(item.Path, "health", "checkup.md")
+	if err != nil {
+		t.Fatalf("Expected no error moving the entry but got: %v", err)
+	}
+	onDisk, err := os.ReadFile(store.GetAbsolutePath(moved.Path))
+	if err != nil {
+		t.Fatalf("Expected no error reading the moved entry's file but got: %v", err)
+	}
+	if !moved.IsEncrypted || strings.Contains(string(onDisk), "Blood") || store.Exists(item.Path) {
+		t.Errorf("Expected the entry to have been moved & encrypted, but got:\n%s", onDisk)
+	}
+}
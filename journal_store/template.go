@@ -0,0 +1,77 @@
+package journal_store
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"text/template"
+	"time"
+)
+
+// Hidden, so it isn't listed as an entry
+const templateFilename = ".template"
+
+// TemplateData is what a template can refer to, e.g. "# {{.Name}} ({{.Timestamp.Format "2006-01-02"}})"
+type TemplateData struct {
+	Name      string
+	Tag       string
+	Timestamp time.Time
+}
+
+/*
+RenderTemplate gets the body that a new entry starts with
+
+The template is the '.template' file in the tag's directory, or in the nearest parent directory that has one (so a
+'.template' at the journal root applies to every entry). It's a Go text/template, filled in with TemplateData. Entries
+with no template start out empty.
+*/
+func (store Store) RenderTemplate(timestamp time.Time, name string, tag string) (string, error) {
+	if err := ValidateTag(tag); err != nil {
+		return "", err
+	}
+
+	templatePath, found := store.findTemplate(tag)
+	if !found {
+		return "", nil
+	}
+	templateBytes, err := os.ReadFile(store.GetAbsolutePath(templatePath))
+	if err != nil {
+		return "", fmt.Errorf("an error occurred reading template '%s': %w", templatePath, err)
+	}
+	parsedTemplate, err := template.New(templatePath).Parse(string(templateBytes))
+	if err != nil {
+		return "", fmt.Errorf("template '%s' isn't valid: %w", templatePath, err)
+	}
+
+	var body bytes.Buffer
+	data := TemplateData{
+		Name:      name,
+		Tag:       tag,
+		Timestamp: timestamp,
+	}
+	if err := parsedTemplate.Execute(&body, data); err != nil {
+		return "", fmt.Errorf("an error occurred filling in template '%s': %w", templatePath, err)
+	}
+	return body.String(), nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// findTemplate gets the root-relative path of the template that applies to entries with the tag
+func (store Store) findTemplate(tag string) (string, bool) {
+	dirpath := tag
+	for {
+		templatePath := path.Join(dirpath, templateFilename)
+		if info, err := os.Stat(store.GetAbsolutePath(templatePath)); err == nil && info.Mode().IsRegular() {
+			return templatePath, true
+		}
+		if dirpath == "" || dirpath == "." {
+			return "", false
+		}
+		dirpath = path.Dir(dirpath)
+	}
+}
@@ -1,32 +1,52 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	tea "github.com/charmbracelet/bubbletea"
-	"github.com/mieubrisse/cli-journal-go/app_components/app_model"
-	"github.com/mieubrisse/cli-journal-go/app_components/entry_item"
+	"github.com/mieubrisse/cli-journal-go/cli"
+	"github.com/mieubrisse/cli-journal-go/config"
 	"os"
-	"regexp"
-	"time"
+	"strings"
 )
 
-var acceptableFormFieldRegex = regexp.MustCompile("^[a-zA-Z0-9.-]+$")
-
 func main() {
-	// TODO set up more items and deal with pagination
-	content := []entry_item.Component{
-		entry_item.New(time.Now(), "scenarios.yml", []string{"general-reference/wealthdraft"}),
-		entry_item.New(time.Now(), "projections.yml", []string{"project-support/wealthdraft"}),
-		entry_item.New(time.Now(), "starlark-exploration.md", []string{"project-support/starlark"}),
-		entry_item.New(time.Now(), "journalling-about-things.md", []string{}),
+	journalName := flag.String("journal", "", "Name of the journal to use, from the 'journals' in the config (the one at 'journal_root' is used if not given)")
+	isMouseEnabled := flag.Bool("mouse", false, "Enables scrolling & clicking with the mouse (which stops the terminal's own text selection from working)")
+	var nameFilters cli.RepeatedStringFlag
+	flag.Var(&nameFilters, "filter", "Start with a filter on entry names (can be repeated)")
+	var tagFilters cli.RepeatedStringFlag
+	flag.Var(&tagFilters, "tag", "Start with a filter on entry tags (can be repeated)")
+	filterText := flag.String("filter-text", "", "Start with this text in the filter pane: one filter per line, with tag filters starting with '#'")
+	flag.Usage = func() {
+		cli.PrintUsage(flag.CommandLine.Output())
+		fmt.Fprintln(flag.CommandLine.Output())
+		fmt.Fprintln(flag.CommandLine.Output(), "Flags:")
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	topLevelModel := app_model.New(content)
+	cfg, err := config.Load(*journalName)
+	if err != nil {
+		fmt.Println("Error loading the config:", err)
+		os.Exit(1)
+	}
 
-	p := tea.NewProgram(topLevelModel, tea.WithAltScreen())
+	// Any args left after the flags are a command to run without the UI
+	if flag.NArg() > 0 {
+		os.Exit(cli.Run(cfg, flag.Args(), os.Stdin, os.Stdout, os.Stderr))
+	}
+
+	initialFilterLines := make([]string, 0)
+	if len(strings.TrimSpace(*filterText)) > 0 {
+		initialFilterLines = append(initialFilterLines, strings.TrimRight(*filterText, "\n"))
+	}
+	initialFilterLines = append(initialFilterLines, nameFilters...)
+	for _, tag := range tagFilters {
+		initialFilterLines = append(initialFilterLines, "#"+strings.TrimPrefix(tag, "#"))
+	}
 
-	if _, err := p.Run(); err != nil {
-		fmt.Println("Error running program:", err)
+	if err := cli.RunUI(cfg, strings.Join(initialFilterLines, "\n"), *isMouseEnabled); err != nil {
+		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
 }
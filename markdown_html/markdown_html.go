@@ -0,0 +1,177 @@
+package markdown_html
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+const codeFence = "```"
+
+var headingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+var unorderedListItemRegex = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+var orderedListItemRegex = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+var taskRegex = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+var horizontalRuleRegex = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+var blockquoteRegex = regexp.MustCompile(`^\s*>\s?(.*)$`)
+
+var codeSpanRegex = regexp.MustCompile("`([^`]+)`")
+
+// These run on text that's already been HTML-escaped
+var linkRegex = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+var boldRegex = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+var italicRegex = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+var underscoreItalicRegex = regexp.MustCompile(`(^|[^\w])_([^_]+)_([^\w]|$)`)
+
+// Links with other schemes (e.g. 'javascript:') are left as text
+var safeLinkRegex = regexp.MustCompile(`^(https?:|mailto:|#|/|\./|\.\./|[\w-]+(\.|/|$))`)
+
+/*
+ToHTML renders the markdown of an entry as HTML, with everything in the entry escaped
+
+This covers the markdown that's common in notes (headings, paragraphs, lists & task checkboxes, block quotes, code,
+rules, emphasis and links) rather than all of CommonMark, since entries are written by hand and only need to be readable.
+*/
+func ToHTML(markdown string) string {
+	builder := &strings.Builder{}
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	paragraphLines := make([]string, 0)
+	flushParagraph := func() {
+		if len(paragraphLines) == 0 {
+			return
+		}
+		builder.WriteString("<p>" + renderInline(strings.Join(paragraphLines, "\n")) + "</p>\n")
+		paragraphLines = paragraphLines[:0]
+	}
+
+	// The tag of the list being written, if any
+	openListTag := ""
+	closeList := func() {
+		if len(openListTag) == 0 {
+			return
+		}
+		builder.WriteString("</" + openListTag + ">\n")
+		openListTag = ""
+	}
+	writeListItem := func(listTag string, itemText string) {
+		if openListTag != listTag {
+			closeList()
+			builder.WriteString("<" + listTag + ">\n")
+			openListTag = listTag
+		}
+		if matches := taskRegex.FindStringSubmatch(itemText); matches != nil {
+			checkedAttr := ""
+			if matches[1] != " " {
+				checkedAttr = " checked"
+			}
+			builder.WriteString(`<li class="task"><input type="checkbox" disabled` + checkedAttr + "> " + renderInline(matches[2]) + "</li>\n")
+			return
+		}
+		builder.WriteString("<li>" + renderInline(itemText) + "</li>\n")
+	}
+
+	for idx := 0; idx < len(lines); idx++ {
+		line := lines[idx]
+
+		if strings.HasPrefix(strings.TrimSpace(line), codeFence) {
+			flushParagraph()
+			closeList()
+			codeLines := make([]string, 0)
+			for idx++; idx < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[idx]), codeFence); idx++ {
+				codeLines = append(codeLines, lines[idx])
+			}
+			builder.WriteString("<pre><code>" + html.EscapeString(strings.Join(codeLines, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		if len(strings.TrimSpace(line)) == 0 {
+			flushParagraph()
+			closeList()
+			continue
+		}
+
+		if matches := headingRegex.FindStringSubmatch(line); matches != nil {
+			flushParagraph()
+			closeList()
+			level := string(rune('0' + len(matches[1])))
+			builder.WriteString("<h" + level + ">" + renderInline(matches[2]) + "</h" + level + ">\n")
+			continue
+		}
+
+		if horizontalRuleRegex.MatchString(line) {
+			flushParagraph()
+			closeList()
+			builder.WriteString("<hr>\n")
+			continue
+		}
+
+		if matches := unorderedListItemRegex.FindStringSubmatch(line); matches != nil {
+			flushParagraph()
+			writeListItem("ul", matches[1])
+			continue
+		}
+		if matches := orderedListItemRegex.FindStringSubmatch(line); matches != nil {
+			flushParagraph()
+			writeListItem("ol", matches[1])
+			continue
+		}
+
+		if blockquoteRegex.MatchString(line) {
+			flushParagraph()
+			closeList()
+			quoteLines := make([]string, 0)
+			for ; idx < len(lines); idx++ {
+				matches := blockquoteRegex.FindStringSubmatch(lines[idx])
+				if matches == nil {
+					break
+				}
+				quoteLines = append(quoteLines, matches[1])
+			}
+			idx--
+			builder.WriteString("<blockquote>\n" + ToHTML(strings.Join(quoteLines, "\n")) + "</blockquote>\n")
+			continue
+		}
+
+		closeList()
+		paragraphLines = append(paragraphLines, strings.TrimSpace(line))
+	}
+	flushParagraph()
+	closeList()
+
+	return builder.String()
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// renderInline renders the emphasis, links and code in a block's text
+func renderInline(text string) string {
+	builder := &strings.Builder{}
+	lastEnd := 0
+	for _, loc := range codeSpanRegex.FindAllStringSubmatchIndex(text, -1) {
+		builder.WriteString(renderFormatting(text[lastEnd:loc[0]]))
+		builder.WriteString("<code>" + html.EscapeString(text[loc[2]:loc[3]]) + "</code>")
+		lastEnd = loc[1]
+	}
+	builder.WriteString(renderFormatting(text[lastEnd:]))
+	return builder.String()
+}
+
+func renderFormatting(text string) string {
+	result := html.EscapeString(text)
+	result = linkRegex.ReplaceAllStringFunc(result, func(link string) string {
+		matches := linkRegex.FindStringSubmatch(link)
+		// The URL was escaped along with the rest of the text, so it's safe to put in the attribute
+		if !safeLinkRegex.MatchString(html.UnescapeString(matches[2])) {
+			return link
+		}
+		return `<a href="` + matches[2] + `">` + matches[1] + "</a>"
+	})
+	result = boldRegex.ReplaceAllString(result, "<strong>$1</strong>")
+	result = italicRegex.ReplaceAllString(result, "<em>$1</em>")
+	result = underscoreItalicRegex.ReplaceAllString(result, "$1<em>$2</em>$3")
+	return strings.ReplaceAll(result, "\n", "<br>\n")
+}
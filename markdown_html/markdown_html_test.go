@@ -0,0 +1,34 @@
+package markdown_html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToHTMLRendersCommonBlocks(t *testing.T) {
+	markdown := "# Plans\n\nSome **bold** and `co*de*` text\n\n- [x] done\n- [ ] todo\n\n```\n<b>raw</b>\n```\n"
+	expected := "<h1>Plans</h1>\n" +
+		"<p>Some <strong>bold</strong> and <code>co*de*</code> text</p>\n" +
+		"<ul>\n" +
+		"<li class=\"task\"><input type=\"checkbox\" disabled checked> done</li>\n" +
+		"<li class=\"task\"><input type=\"checkbox\" disabled> todo</li>\n" +
+		"</ul>\n" +
+		"<pre><code>&lt;b&gt;raw&lt;/b&gt;</code></pre>\n"
+
+	if actual := ToHTML(markdown); actual != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
+
+func TestToHTMLEscapesHTMLAndUnsafeLinks(t *testing.T) {
+	actual := ToHTML("<script>alert(1)</script> [ok](https://example.com) [bad](javascript:alert(1))")
+	if strings.Contains(actual, "<script>") {
+		t.Errorf("Expected the HTML in the entry to be escaped but got: %s", actual)
+	}
+	if !strings.Contains(actual, `<a href="https://example.com">ok</a>`) {
+		t.Errorf("Expected the https link to be rendered but got: %s", actual)
+	}
+	if strings.Contains(actual, `href="javascript`) {
+		t.Errorf("Expected the javascript link to be left as text but got: %s", actual)
+	}
+}
@@ -0,0 +1,399 @@
+package site_generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"github.com/mieubrisse/cli-journal-go/markdown_html"
+	"html/template"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// Marks a directory as one that Generate made, so it can be safely replaced by the next run
+	siteMarkerFilename = ".cli-journal-site"
+
+	indexFilename        = "index.html"
+	searchFilename       = "search.html"
+	searchIndexFilename  = "search-index.js"
+	searchScriptFilename = "search.js"
+	stylesheetFilename   = "style.css"
+	tagsDirname          = "tags"
+	entriesDirname       = "entries"
+	entryPageExtension   = ".html"
+
+	dayHeaderFormat   = "Monday, January 2, 2006"
+	monthHeaderFormat = "January 2006"
+	entryTimeFormat   = "15:04"
+	entryDateFormat   = "2006-01-02 15:04"
+
+	siteDirPerms  = 0755
+	siteFilePerms = 0644
+)
+
+/*
+Generate writes a static site for the entries at the given paths into the output directory, which must be empty, not
+exist, or have been made by an earlier Generate (in which case it's replaced)
+
+The site has an index of the entries by date, a page for each tag (and each parent of a tag, listing everything under
+it), a page for each entry, and a search page. It's plain files with relative links and no network access, so it can be
+opened straight from disk.
+*/
+func Generate(store journal_store.Store, entryPaths []string, title string, outputDirpath string) error {
+	if err := prepareOutputDir(outputDirpath); err != nil {
+		return err
+	}
+
+	entries := make([]content_item.ContentItem, 0, len(entryPaths))
+	bodies := make(map[string]string, len(entryPaths))
+	for _, entryPath := range entryPaths {
+		entry, err := store.Get(entryPath)
+		if err != nil {
+			return err
+		}
+		body, err := store.Read(entryPath)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		bodies[entryPath] = body
+	}
+	// Newest first, like the journal's default sort
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+
+	site := siteWriter{
+		outputDirpath: outputDirpath,
+		title:         title,
+	}
+	if err := site.writeFile(stylesheetFilename, []byte(stylesheet)); err != nil {
+		return err
+	}
+	if err := site.writeFile(searchScriptFilename, []byte(searchScript)); err != nil {
+		return err
+	}
+	if err := site.writeSearchIndex(entries, bodies); err != nil {
+		return err
+	}
+	if err := site.writePage(searchFilename, searchTemplate, "Search", nil); err != nil {
+		return err
+	}
+	if err := site.writePage(indexFilename, entryListTemplate, title, getEntryListData(entries, "", nil)); err != nil {
+		return err
+	}
+
+	tags := getTagHierarchy(entries)
+	if err := site.writePage(path.Join(tagsDirname, indexFilename), tagsTemplate, "Tags", getTagTree(tags, "")); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		tagEntries := make([]content_item.ContentItem, 0)
+		for _, entry := range entries {
+			if isUnderTag(getEntryTag(entry), tag) {
+				tagEntries = append(tagEntries, entry)
+			}
+		}
+		pageData := getEntryListData(tagEntries, tag, getTagTree(tags, tag))
+		if err := site.writePage(getTagPagePath(tag), entryListTemplate, "#"+tag, pageData); err != nil {
+			return err
+		}
+	}
+
+	for idx, entry := range entries {
+		pageData := entryPageData{
+			Entry: getEntrySummary(entry),
+			Body:  template.HTML(markdown_html.ToHTML(bodies[entry.Path])),
+			Newer: nil,
+			Older: nil,
+		}
+		if idx > 0 {
+			newer := getEntrySummary(entries[idx-1])
+			pageData.Newer = &newer
+		}
+		if idx < len(entries)-1 {
+			older := getEntrySummary(entries[idx+1])
+			pageData.Older = &older
+		}
+		if err := site.writePage(getEntryPagePath(entry.Path), entryTemplate, entry.Name, pageData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+type siteWriter struct {
+	outputDirpath string
+	title         string
+}
+
+// The data every page gets, wrapping what's specific to the page
+type pageData struct {
+	SiteTitle string
+	PageTitle string
+
+	// Gets from the page back to the site root, for relative links
+	RootPath string
+
+	Content interface{}
+}
+
+type entrySummary struct {
+	Name string
+	Date string
+	Time string
+
+	// Relative to the site root
+	PagePath string
+	Tags     []tagLink
+}
+
+type tagLink struct {
+	Tag string
+
+	// Relative to the site root
+	PagePath string
+}
+
+type entryListData struct {
+	Tag        string
+	ParentTag  *tagLink
+	Subtags    []tagTreeNode
+	Months     []monthGroup
+	NumEntries int
+}
+
+type monthGroup struct {
+	Month string
+	Days  []dayGroup
+}
+
+type dayGroup struct {
+	Day     string
+	Entries []entrySummary
+}
+
+type tagTreeNode struct {
+	Link     tagLink
+	Children []tagTreeNode
+}
+
+type entryPageData struct {
+	Entry entrySummary
+	Body  template.HTML
+	Newer *entrySummary
+	Older *entrySummary
+}
+
+// The record for an entry in the search index, which the search page loads as a script so it works from disk
+type searchIndexRecord struct {
+	Name     string   `json:"name"`
+	Date     string   `json:"date"`
+	Tags     []string `json:"tags"`
+	PagePath string   `json:"path"`
+	Text     string   `json:"text"`
+}
+
+// writePage renders the page to the given path, which is relative to the site root and escaped like in links
+func (site siteWriter) writePage(relativeFilepath string, pageTemplate *template.Template, pageTitle string, content interface{}) error {
+	depth := strings.Count(relativeFilepath, "/")
+	rootPath := strings.Repeat("../", depth)
+	if len(rootPath) == 0 {
+		rootPath = "./"
+	}
+
+	builder := &strings.Builder{}
+	data := pageData{
+		SiteTitle: site.title,
+		PageTitle: pageTitle,
+		RootPath:  rootPath,
+		Content:   content,
+	}
+	if err := pageTemplate.Execute(builder, data); err != nil {
+		return fmt.Errorf("an error occurred rendering page '%s': %w", relativeFilepath, err)
+	}
+
+	// Page paths are escaped for links, but the files need the real names
+	unescapedFilepath, err := url.PathUnescape(relativeFilepath)
+	if err != nil {
+		return fmt.Errorf("page path '%s' is invalid: %w", relativeFilepath, err)
+	}
+	return site.writeFile(unescapedFilepath, []byte(builder.String()))
+}
+
+func (site siteWriter) writeSearchIndex(entries []content_item.ContentItem, bodies map[string]string) error {
+	records := make([]searchIndexRecord, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, searchIndexRecord{
+			Name:     entry.Name,
+			Date:     entry.Timestamp.Format(entryDateFormat),
+			Tags:     entry.Tags,
+			PagePath: getEntryPagePath(entry.Path),
+			Text:     bodies[entry.Path],
+		})
+	}
+	recordsJSON, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("an error occurred serializing the search index: %w", err)
+	}
+	return site.writeFile(searchIndexFilename, []byte("var searchIndex = "+string(recordsJSON)+";\n"))
+}
+
+func (site siteWriter) writeFile(relativeFilepath string, contents []byte) error {
+	absFilepath := filepath.Join(site.outputDirpath, filepath.FromSlash(relativeFilepath))
+	if err := os.MkdirAll(filepath.Dir(absFilepath), siteDirPerms); err != nil {
+		return fmt.Errorf("an error occurred creating the directory for '%s': %w", relativeFilepath, err)
+	}
+	if err := os.WriteFile(absFilepath, contents, siteFilePerms); err != nil {
+		return fmt.Errorf("an error occurred writing '%s': %w", relativeFilepath, err)
+	}
+	return nil
+}
+
+// prepareOutputDir empties the output directory if an earlier run made it, and refuses to touch it otherwise
+func prepareOutputDir(outputDirpath string) error {
+	dirEntries, err := os.ReadDir(outputDirpath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("an error occurred reading output directory '%s': %w", outputDirpath, err)
+	}
+	if len(dirEntries) > 0 {
+		if _, err := os.Stat(filepath.Join(outputDirpath, siteMarkerFilename)); err != nil {
+			return fmt.Errorf("output directory '%s' isn't empty and wasn't made by an earlier site generation, so it won't be replaced", outputDirpath)
+		}
+		for _, dirEntry := range dirEntries {
+			if err := os.RemoveAll(filepath.Join(outputDirpath, dirEntry.Name())); err != nil {
+				return fmt.Errorf("an error occurred clearing the previous site from '%s': %w", outputDirpath, err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(outputDirpath, siteDirPerms); err != nil {
+		return fmt.Errorf("an error occurred creating output directory '%s': %w", outputDirpath, err)
+	}
+	return os.WriteFile(filepath.Join(outputDirpath, siteMarkerFilename), []byte{}, siteFilePerms)
+}
+
+func getEntryListData(entries []content_item.ContentItem, tag string, subtags []tagTreeNode) entryListData {
+	result := entryListData{
+		Tag:        tag,
+		ParentTag:  nil,
+		Subtags:    subtags,
+		Months:     make([]monthGroup, 0),
+		NumEntries: len(entries),
+	}
+	if parentTag := path.Dir(tag); len(tag) > 0 && parentTag != "." {
+		result.ParentTag = &tagLink{Tag: parentTag, PagePath: getTagPagePath(parentTag)}
+	}
+
+	// The entries are newest first, so each month & day is a run of them
+	for _, entry := range entries {
+		month := entry.Timestamp.Format(monthHeaderFormat)
+		if len(result.Months) == 0 || result.Months[len(result.Months)-1].Month != month {
+			result.Months = append(result.Months, monthGroup{Month: month, Days: make([]dayGroup, 0)})
+		}
+		monthGroup := &result.Months[len(result.Months)-1]
+
+		day := entry.Timestamp.Format(dayHeaderFormat)
+		if len(monthGroup.Days) == 0 || monthGroup.Days[len(monthGroup.Days)-1].Day != day {
+			monthGroup.Days = append(monthGroup.Days, dayGroup{Day: day, Entries: make([]entrySummary, 0)})
+		}
+		dayGroup := &monthGroup.Days[len(monthGroup.Days)-1]
+		dayGroup.Entries = append(dayGroup.Entries, getEntrySummary(entry))
+	}
+	return result
+}
+
+func getEntrySummary(entry content_item.ContentItem) entrySummary {
+	tagLinks := make([]tagLink, 0, len(entry.Tags))
+	for _, tag := range entry.Tags {
+		tagLinks = append(tagLinks, tagLink{Tag: tag, PagePath: getTagPagePath(tag)})
+	}
+	return entrySummary{
+		Name:     entry.Name,
+		Date:     entry.Timestamp.Format(entryDateFormat),
+		Time:     entry.Timestamp.Format(entryTimeFormat),
+		PagePath: getEntryPagePath(entry.Path),
+		Tags:     tagLinks,
+	}
+}
+
+// getTagHierarchy gets the entries' tags along with all their parents (so 'work/meetings' brings 'work'), sorted
+func getTagHierarchy(entries []content_item.ContentItem) []string {
+	deduplicatedTags := make(map[string]bool)
+	for _, entry := range entries {
+		for tag := getEntryTag(entry); len(tag) > 0 && tag != "."; tag = path.Dir(tag) {
+			deduplicatedTags[tag] = true
+		}
+	}
+
+	result := make([]string, 0, len(deduplicatedTags))
+	for tag := range deduplicatedTags {
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// getTagTree gets the tree of the tags under the parent tag (or all of them, if the parent is empty)
+func getTagTree(sortedTags []string, parentTag string) []tagTreeNode {
+	result := make([]tagTreeNode, 0)
+	for _, tag := range sortedTags {
+		if tag == parentTag || !isUnderTag(tag, parentTag) {
+			continue
+		}
+		// Only the direct children, which get their own children recursively
+		tagParent := path.Dir(tag)
+		if tagParent == "." {
+			tagParent = ""
+		}
+		if tagParent != parentTag {
+			continue
+		}
+		result = append(result, tagTreeNode{
+			Link:     tagLink{Tag: tag, PagePath: getTagPagePath(tag)},
+			Children: getTagTree(sortedTags, tag),
+		})
+	}
+	return result
+}
+
+// isUnderTag gets whether the tag is the parent tag or one of its descendants; everything is under the empty tag
+func isUnderTag(tag string, parentTag string) bool {
+	return len(parentTag) == 0 || tag == parentTag || strings.HasPrefix(tag, parentTag+"/")
+}
+
+// Entries have at most one tag, since their tag is the directory they're in
+func getEntryTag(entry content_item.ContentItem) string {
+	if len(entry.Tags) == 0 {
+		return ""
+	}
+	return entry.Tags[0]
+}
+
+// The page paths are used in links, so each part is escaped
+func getTagPagePath(tag string) string {
+	return path.Join(tagsDirname, escapePath(tag), indexFilename)
+}
+
+func getEntryPagePath(entryPath string) string {
+	return path.Join(entriesDirname, escapePath(entryPath)+entryPageExtension)
+}
+
+func escapePath(pathStr string) string {
+	components := strings.Split(pathStr, "/")
+	for idx, component := range components {
+		components[idx] = url.PathEscape(component)
+	}
+	return strings.Join(components, "/")
+}
@@ -0,0 +1,82 @@
+package site_generator
+
+import (
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateWritesPagesForEntriesAndTagHierarchy(t *testing.T) {
+	store := journal_store.New(t.TempDir())
+	entry, err := store.Create(time.Date(2023, time.March, 4, 10, 30, 0, 0, time.Local), "db-notes", "general-reference/databases", "# Indexes\n\nUse <b>them</b>")
+	if err != nil {
+		t.Fatalf("Creating an entry failed: %v", err)
+	}
+
+	outputDirpath := filepath.Join(t.TempDir(), "site")
+	if err := Generate(store, []string{entry.Path}, "Reference", outputDirpath); err != nil {
+		t.Fatalf("Generating the site failed: %v", err)
+	}
+
+	expectedFilepaths := []string{
+		"index.html",
+		"search.html",
+		"search-index.js",
+		"tags/index.html",
+		"tags/general-reference/index.html",
+		"tags/general-reference/databases/index.html",
+		"entries/general-reference/databases/20230304-103000_db-notes.html",
+	}
+	for _, relativeFilepath := range expectedFilepaths {
+		if _, err := os.Stat(filepath.Join(outputDirpath, relativeFilepath)); err != nil {
+			t.Errorf("Expected '%s' to be generated but got: %v", relativeFilepath, err)
+		}
+	}
+
+	entryPage := readFile(t, filepath.Join(outputDirpath, expectedFilepaths[6]))
+	if !strings.Contains(entryPage, "<h1>Indexes</h1>") || strings.Contains(entryPage, "<b>them</b>") {
+		t.Errorf("Expected the entry's markdown to be rendered with its HTML escaped but got:\n%s", entryPage)
+	}
+	if !strings.Contains(entryPage, `href="../../../style.css"`) {
+		t.Errorf("Expected the entry page to link to the stylesheet relatively but got:\n%s", entryPage)
+	}
+	parentTagPage := readFile(t, filepath.Join(outputDirpath, expectedFilepaths[4]))
+	if !strings.Contains(parentTagPage, "db-notes") {
+		t.Errorf("Expected the parent tag's page to list the entry in its child tag but got:\n%s", parentTagPage)
+	}
+}
+
+func TestGenerateOnlyReplacesItsOwnOutput(t *testing.T) {
+	store := journal_store.New(t.TempDir())
+	outputDirpath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDirpath, "important.txt"), []byte("keep me"), 0644); err != nil {
+		t.Fatalf("Writing a file failed: %v", err)
+	}
+
+	if err := Generate(store, nil, "Journal", outputDirpath); err == nil {
+		t.Errorf("Expected generating into a directory with other files in it to fail")
+	}
+
+	siteDirpath := filepath.Join(t.TempDir(), "site")
+	for i := 0; i < 2; i++ {
+		if err := Generate(store, nil, "Journal", siteDirpath); err != nil {
+			t.Fatalf("Expected generating run #%d to succeed but got: %v", i+1, err)
+		}
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func readFile(t *testing.T, filepathStr string) string {
+	contents, err := os.ReadFile(filepathStr)
+	if err != nil {
+		t.Fatalf("Reading '%s' failed: %v", filepathStr, err)
+	}
+	return string(contents)
+}
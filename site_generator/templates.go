@@ -0,0 +1,162 @@
+package site_generator
+
+import "html/template"
+
+// The header & footer every page shares; each page template fills in the "content" block
+const layoutTemplateText = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.PageTitle}} · {{.SiteTitle}}</title>
+<link rel="stylesheet" href="{{.RootPath}}style.css">
+</head>
+<body>
+<nav>
+<a class="site-title" href="{{.RootPath}}index.html">{{.SiteTitle}}</a>
+<a href="{{.RootPath}}tags/index.html">Tags</a>
+<form action="{{.RootPath}}search.html"><input type="search" name="q" placeholder="Search"></form>
+</nav>
+<main>
+{{template "content" .}}
+</main>
+</body>
+</html>
+`
+
+const entryListTemplateText = `{{define "content"}}{{$root := .RootPath}}{{with .Content}}
+{{if .Tag}}<h1>#{{.Tag}}</h1>
+{{if .ParentTag}}<p class="parent-tag">In <a href="{{$root}}{{.ParentTag.PagePath}}">#{{.ParentTag.Tag}}</a></p>{{end}}
+{{if .Subtags}}<section class="subtags"><h2>Tags</h2>{{template "tag-tree" dict "Nodes" .Subtags "Root" $root}}</section>{{end}}
+{{end}}<p class="count">{{.NumEntries}} entries</p>
+{{range .Months}}<section class="month">
+<h2>{{.Month}}</h2>
+{{range .Days}}<h3>{{.Day}}</h3>
+<ul class="entries">
+{{range .Entries}}<li>{{template "entry-summary" dict "Entry" . "Root" $root}}</li>
+{{end}}</ul>
+{{end}}</section>
+{{end}}{{end}}{{end}}`
+
+const tagsTemplateText = `{{define "content"}}<h1>Tags</h1>
+{{template "tag-tree" dict "Nodes" .Content "Root" .RootPath}}
+{{end}}`
+
+const entryTemplateText = `{{define "content"}}{{$root := .RootPath}}{{with .Content}}<article>
+<h1>{{.Entry.Name}}</h1>
+<p class="meta">{{.Entry.Date}}{{range .Entry.Tags}} <a class="tag" href="{{$root}}{{.PagePath}}">#{{.Tag}}</a>{{end}}</p>
+{{.Body}}
+</article>
+<nav class="neighbors">
+{{with .Newer}}<a href="{{$root}}{{.PagePath}}">← {{.Name}}</a>{{end}}
+{{with .Older}}<a class="older" href="{{$root}}{{.PagePath}}">{{.Name}} →</a>{{end}}
+</nav>
+{{end}}{{end}}`
+
+const searchTemplateText = `{{define "content"}}<h1>Search</h1>
+<input id="search-input" type="search" placeholder="Words to find in names, tags & text" autofocus>
+<p id="search-count" class="count"></p>
+<ul id="search-results" class="entries"></ul>
+<script>var siteRoot = "{{.RootPath}}";</script>
+<script src="{{.RootPath}}search-index.js"></script>
+<script src="{{.RootPath}}search.js"></script>
+{{end}}`
+
+// Shared pieces, used by the page templates
+const partialsTemplateText = `{{define "entry-summary"}}<span class="time">{{.Entry.Time}}</span> <a href="{{.Root}}{{.Entry.PagePath}}">{{.Entry.Name}}</a>{{range .Entry.Tags}} <a class="tag" href="{{$.Root}}{{.PagePath}}">#{{.Tag}}</a>{{end}}{{end}}
+{{define "tag-tree"}}<ul class="tag-tree">
+{{range .Nodes}}<li><a href="{{$.Root}}{{.Link.PagePath}}">#{{.Link.Tag}}</a>{{if .Children}}{{template "tag-tree" dict "Nodes" .Children "Root" $.Root}}{{end}}</li>
+{{end}}</ul>{{end}}`
+
+var entryListTemplate = newPageTemplate("entry-list", entryListTemplateText)
+var tagsTemplate = newPageTemplate("tags", tagsTemplateText)
+var entryTemplate = newPageTemplate("entry", entryTemplateText)
+var searchTemplate = newPageTemplate("search", searchTemplateText)
+
+const stylesheet = `body { margin: 0; font-family: sans-serif; line-height: 1.5; color: #222; }
+nav { display: flex; gap: 1em; align-items: center; padding: 0.5em 1em; border-bottom: 1px solid #ddd; }
+nav form { margin-left: auto; }
+.site-title { font-weight: bold; }
+main { max-width: 48em; margin: 1em auto; padding: 0 1em; }
+a { color: #2a5db0; text-decoration: none; }
+a:hover { text-decoration: underline; }
+.tag { color: #777; font-size: 0.9em; }
+.time, .count, .meta, .parent-tag { color: #777; }
+ul.entries { list-style: none; padding-left: 0; }
+ul.tag-tree { padding-left: 1.25em; }
+pre { background: #f4f4f4; padding: 0.75em; overflow-x: auto; }
+li.task { list-style: none; }
+blockquote { border-left: 3px solid #ddd; margin-left: 0; padding-left: 1em; color: #555; }
+.neighbors { border: none; border-top: 1px solid #ddd; margin-top: 2em; padding: 0.5em 0; }
+.neighbors .older { margin-left: auto; }
+#search-input { width: 100%; font-size: 1.1em; padding: 0.3em; }
+`
+
+// Finds the entries containing every word of the query (from the input, or the 'q' URL parameter that the search box in
+// the nav sends), using the index that search-index.js defines
+const searchScript = `(function () {
+  var input = document.getElementById("search-input");
+  var count = document.getElementById("search-count");
+  var results = document.getElementById("search-results");
+
+  function search() {
+    var words = input.value.toLowerCase().split(/\s+/).filter(function (word) { return word.length > 0; });
+    results.innerHTML = "";
+    if (words.length === 0) {
+      count.textContent = "";
+      return;
+    }
+
+    var matches = searchIndex.filter(function (record) {
+      var haystack = (record.name + " " + record.tags.map(function (tag) { return "#" + tag; }).join(" ") + " " + record.text).toLowerCase();
+      return words.every(function (word) { return haystack.indexOf(word) !== -1; });
+    });
+    count.textContent = matches.length + " entries";
+    matches.forEach(function (record) {
+      var item = document.createElement("li");
+      var date = document.createElement("span");
+      date.className = "time";
+      date.textContent = record.date + " ";
+      var link = document.createElement("a");
+      link.href = siteRoot + record.path;
+      link.textContent = record.name;
+      item.appendChild(date);
+      item.appendChild(link);
+      record.tags.forEach(function (tag) {
+        var tagElem = document.createElement("span");
+        tagElem.className = "tag";
+        tagElem.textContent = " #" + tag;
+        item.appendChild(tagElem);
+      });
+      results.appendChild(item);
+    });
+  }
+
+  var query = new URLSearchParams(window.location.search).get("q");
+  if (query) {
+    input.value = query;
+  }
+  input.addEventListener("input", search);
+  search();
+})();
+`
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newPageTemplate(name string, contentTemplateText string) *template.Template {
+	return template.Must(template.Must(template.Must(template.New(name).Funcs(template.FuncMap{
+		"dict": dict,
+	}).Parse(layoutTemplateText)).Parse(partialsTemplateText)).Parse(contentTemplateText))
+}
+
+// dict lets a template pass several values to another template, as alternating keys & values
+func dict(keysAndValues ...interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(keysAndValues)/2)
+	for idx := 0; idx+1 < len(keysAndValues); idx += 2 {
+		result[keysAndValues[idx].(string)] = keysAndValues[idx+1]
+	}
+	return result
+}
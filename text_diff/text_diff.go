@@ -0,0 +1,93 @@
+package text_diff
+
+import "strings"
+
+type LineKind int
+
+const (
+	Unchanged LineKind = iota
+	Added
+	Removed
+)
+
+// Line is a line of a diff
+type Line struct {
+	Kind LineKind
+	Text string
+}
+
+/*
+Diff gets the lines that turn the old text into the new text, as a list of unchanged, removed and added lines in the
+order they'd be read
+
+It finds the longest common subsequence of lines, which is quadratic in the number of lines; that's fine for journal
+entries, which are short.
+*/
+func Diff(oldText string, newText string) []Line {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	// commonLengths[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	commonLengths := make([][]int, len(oldLines)+1)
+	for i := range commonLengths {
+		commonLengths[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				commonLengths[i][j] = commonLengths[i+1][j+1] + 1
+			} else if commonLengths[i+1][j] >= commonLengths[i][j+1] {
+				commonLengths[i][j] = commonLengths[i+1][j]
+			} else {
+				commonLengths[i][j] = commonLengths[i][j+1]
+			}
+		}
+	}
+
+	result := make([]Line, 0, len(oldLines)+len(newLines))
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			result = append(result, Line{Kind: Unchanged, Text: oldLines[i]})
+			i++
+			j++
+		case commonLengths[i+1][j] >= commonLengths[i][j+1]:
+			result = append(result, Line{Kind: Removed, Text: oldLines[i]})
+			i++
+		default:
+			result = append(result, Line{Kind: Added, Text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		result = append(result, Line{Kind: Removed, Text: oldLines[i]})
+	}
+	for ; j < len(newLines); j++ {
+		result = append(result, Line{Kind: Added, Text: newLines[j]})
+	}
+	return result
+}
+
+// HasChanges gets whether the diff has any added or removed lines
+func HasChanges(lines []Line) bool {
+	for _, line := range lines {
+		if line.Kind != Unchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// splitLines splits the text into lines, not counting the newline at the end of the last line as starting another
+func splitLines(text string) []string {
+	if len(text) == 0 {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,28 @@
+package text_diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldText := "a\nb\nc\n"
+	newText := "a\nc\nd\n"
+	expected := []Line{
+		{Kind: Unchanged, Text: "a"},
+		{Kind: Removed, Text: "b"},
+		{Kind: Unchanged, Text: "c"},
+		{Kind: Added, Text: "d"},
+	}
+
+	actual := Diff(oldText, newText)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v but got %+v", expected, actual)
+	}
+	if !HasChanges(actual) {
+		t.Errorf("Expected the diff to have changes")
+	}
+	if HasChanges(Diff(oldText, oldText)) {
+		t.Errorf("Expected diffing a text against itself to have no changes")
+	}
+}
@@ -0,0 +1,120 @@
+package wiki_links
+
+import (
+	"errors"
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"github.com/mieubrisse/cli-journal-go/entry_crypto"
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+)
+
+// ResolvedLink is a link along with the entry it goes to
+type ResolvedLink struct {
+	Link
+
+	// Empty if the link is broken
+	TargetPath string
+}
+
+func (link ResolvedLink) IsBroken() bool {
+	return len(link.TargetPath) == 0
+}
+
+// BrokenLink is a link to an entry that doesn't exist
+type BrokenLink struct {
+	SourcePath string
+	Target     string
+}
+
+// Index is every link between the journal's entries, as they were when it was built
+type Index struct {
+	// Newest first, as links are resolved
+	items []content_item.ContentItem
+
+	linksBySourcePath map[string][]ResolvedLink
+
+	// Encrypted entries that couldn't be read because the journal is locked, so their links are missing
+	unreadableEntryPaths []string
+}
+
+// BuildIndex reads every entry's links, skipping encrypted entries if the journal is locked
+func BuildIndex(store journal_store.Store) (*Index, error) {
+	items, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{
+		items:                items,
+		linksBySourcePath:    make(map[string][]ResolvedLink, len(items)),
+		unreadableEntryPaths: []string{},
+	}
+	for _, item := range items {
+		body, err := store.Read(item.Path)
+		if errors.Is(err, entry_crypto.ErrLocked) {
+			index.unreadableEntryPaths = append(index.unreadableEntryPaths, item.Path)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		links := make([]ResolvedLink, 0)
+		for _, link := range Parse(body) {
+			resolved := ResolvedLink{Link: link, TargetPath: ""}
+			if target, found := Resolve(link.Target, items); found {
+				resolved.TargetPath = target.Path
+			}
+			links = append(links, resolved)
+		}
+		index.linksBySourcePath[item.Path] = links
+	}
+	return index, nil
+}
+
+// GetLinks gets the links in the entry, in the order they appear
+func (index Index) GetLinks(entryPath string) []ResolvedLink {
+	return index.linksBySourcePath[entryPath]
+}
+
+// GetBacklinks gets the entries that link to the entry, newest first
+func (index Index) GetBacklinks(entryPath string) []content_item.ContentItem {
+	result := make([]content_item.ContentItem, 0)
+	for _, item := range index.items {
+		for _, link := range index.linksBySourcePath[item.Path] {
+			if link.TargetPath == entryPath {
+				result = append(result, item)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// GetBrokenLinks gets the links to entries that don't exist, by source entry (newest first) and then in the order
+// they appear
+func (index Index) GetBrokenLinks() []BrokenLink {
+	result := make([]BrokenLink, 0)
+	for _, item := range index.items {
+		for _, link := range index.linksBySourcePath[item.Path] {
+			if link.IsBroken() {
+				result = append(result, BrokenLink{SourcePath: item.Path, Target: link.Target})
+			}
+		}
+	}
+	return result
+}
+
+// GetItem gets the entry at the path, returning false if it wasn't in the journal when the index was built
+func (index Index) GetItem(entryPath string) (content_item.ContentItem, bool) {
+	for _, item := range index.items {
+		if item.Path == entryPath {
+			return item, true
+		}
+	}
+	return content_item.ContentItem{}, false
+}
+
+// GetNumUnreadableEntries gets how many encrypted entries were left out because the journal is locked
+func (index Index) GetNumUnreadableEntries() int {
+	return len(index.unreadableEntryPaths)
+}
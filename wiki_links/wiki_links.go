@@ -0,0 +1,149 @@
+package wiki_links
+
+import (
+	"github.com/mieubrisse/cli-journal-go/data_structures/content_item"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Links can leave this off the end of the name they link to
+	defaultEntryExtension = ".md"
+
+	codeFenceMarker = "```"
+)
+
+// Matches "[[target]]" and "[[target|label]]"
+var linkRegex = regexp.MustCompile(`\[\[([^\[\]|\n]+)(\|[^\[\]\n]*)?\]\]`)
+
+// Link is a "[[target]]" link in an entry's body
+type Link struct {
+	// What's between the brackets, minus any "|label"
+	// This is the linked entry's name (optionally without its ".md"), preceded by its tag & a '/' if it needs telling
+	// apart from other entries with the same name
+	Target string
+
+	// Byte offsets of the target within the body, for rewriting it
+	targetStart int
+	targetEnd   int
+}
+
+// Parse finds the links in the body, ignoring any in fenced code blocks
+func Parse(body string) []Link {
+	result := make([]Link, 0)
+	isInCodeFence := false
+	lineStart := 0
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), codeFenceMarker) {
+			isInCodeFence = !isInCodeFence
+		}
+		if !isInCodeFence {
+			for _, match := range linkRegex.FindAllStringSubmatchIndex(line, -1) {
+				target := line[match[2]:match[3]]
+				if len(strings.TrimSpace(target)) == 0 {
+					continue
+				}
+				result = append(result, Link{
+					Target:      strings.TrimSpace(target),
+					targetStart: lineStart + match[2],
+					targetEnd:   lineStart + match[3],
+				})
+			}
+		}
+		lineStart += len(line)
+	}
+	return result
+}
+
+// Matches gets whether the link target refers to the entry, ignoring case
+func Matches(target string, item content_item.ContentItem) bool {
+	tag, name := splitTarget(target)
+	if tag != nil && !strings.EqualFold(*tag, getTag(item)) {
+		return false
+	}
+	return strings.EqualFold(name, item.Name) || strings.EqualFold(name+defaultEntryExtension, item.Name)
+}
+
+// Resolve gets the entry that the link target refers to, out of entries ordered newest first, returning false if
+// there's none
+// When entries share a name, the newest one is linked to unless the target gives the tag.
+func Resolve(target string, items []content_item.ContentItem) (content_item.ContentItem, bool) {
+	for _, item := range items {
+		if Matches(target, item) {
+			return item, true
+		}
+	}
+	return content_item.ContentItem{}, false
+}
+
+// GetTarget gets the target that links to the entry, leaving off the ".md" for brevity
+func GetTarget(item content_item.ContentItem) string {
+	return strings.TrimSuffix(item.Name, defaultEntryExtension)
+}
+
+// Rewrite points the body's links to the old entry at the new one (e.g. after it was renamed), returning the new body
+// and how many links were changed
+// The links are the body's links as resolved by an index built before the rename, so that only the links that went to
+// the old entry are rewritten (and not ones to other entries with the same name). Each rewritten link keeps its label,
+// and keeps giving the tag or leaving off the ".md" if it did before.
+func Rewrite(body string, links []ResolvedLink, oldPath string, newItem content_item.ContentItem) (string, int) {
+	var result strings.Builder
+	numRewritten := 0
+	lastEnd := 0
+	for _, link := range links {
+		if link.TargetPath != oldPath || !isLinkAt(body, link.Link, lastEnd) {
+			continue
+		}
+		result.WriteString(body[lastEnd:link.targetStart])
+		result.WriteString(getRewrittenTarget(link.Target, newItem))
+		lastEnd = link.targetEnd
+		numRewritten++
+	}
+	result.WriteString(body[lastEnd:])
+	return result.String(), numRewritten
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// splitTarget splits "tag/name" targets into their parts; the tag is nil if the target doesn't give one
+func splitTarget(target string) (*string, string) {
+	target = strings.TrimSpace(target)
+	slashIdx := strings.LastIndex(target, "/")
+	if slashIdx < 0 {
+		return nil, target
+	}
+	tag := strings.TrimPrefix(target[:slashIdx], "#")
+	return &tag, target[slashIdx+1:]
+}
+
+// isLinkAt checks that the link is still where it was found in the body, after the given offset
+func isLinkAt(body string, link Link, minStart int) bool {
+	if link.targetStart < minStart || link.targetEnd > len(body) || link.targetStart > link.targetEnd {
+		return false
+	}
+	return strings.TrimSpace(body[link.targetStart:link.targetEnd]) == link.Target
+}
+
+func getRewrittenTarget(oldTarget string, newItem content_item.ContentItem) string {
+	tag, name := splitTarget(oldTarget)
+	newName := newItem.Name
+	if !strings.HasSuffix(strings.ToLower(name), defaultEntryExtension) {
+		newName = GetTarget(newItem)
+	}
+	if tag == nil {
+		return newName
+	}
+	return path.Join(getTag(newItem), newName)
+}
+
+// Entries have at most one tag, since their tag is the directory they're in
+func getTag(item content_item.ContentItem) string {
+	if len(item.Tags) == 0 {
+		return ""
+	}
+	return item.Tags[0]
+}
@@ -0,0 +1,107 @@
+package wiki_links
+
+import (
+	"github.com/mieubrisse/cli-journal-go/journal_store"
+	"testing"
+	"time"
+)
+
+func TestLinksResolveAndBreakAcrossTheJournal(t *testing.T) {
+	store := journal_store.New(t.TempDir())
+	timestamp := time.Date(2023, time.April, 15, 10, 15, 0, 0, time.Local)
+	mustCreate := func(offset time.Duration, name string, tag string, body string) string {
+		item, err := store.Create(timestamp.Add(offset), name, tag, body)
+		if err != nil {
+			t.Fatalf("Expected no error creating entry '%s' but got: %v", name, err)
+		}
+		return item.Path
+	}
+	olderStandupPath := mustCreate(0, "standup.md", "work", "Old standup")
+	standupPath := mustCreate(time.Hour, "standup.md", "work", "New standup")
+	planPath := mustCreate(2*time.Hour, "plan.md", "", "See [[standup]] and [[Standup.md|yesterday]], plus [[work/missing]].\n```\n[[ignored]]\n```\n")
+
+	index, err := BuildIndex(store)
+	if err != nil {
+		t.Fatalf("Expected no error building the index but got: %v", err)
+	}
+
+	links := index.GetLinks(planPath)
+	if len(links) != 3 {
+		t.Fatalf("Expected 3 links outside the code block but got %d: %+v", len(links), links)
+	}
+	if links[0].TargetPath != standupPath || links[1].TargetPath != standupPath {
+		t.Errorf("Expected both standup links to go to the newest standup (%s) but got %+v", standupPath, links)
+	}
+	if !links[2].IsBroken() {
+		t.Errorf("Expected the link to a missing entry to be broken but got %+v", links[2])
+	}
+
+	if backlinks := index.GetBacklinks(standupPath); len(backlinks) != 1 || backlinks[0].Path != planPath {
+		t.Errorf("Expected the plan to be the standup's only backlink but got %+v", backlinks)
+	}
+	if backlinks := index.GetBacklinks(olderStandupPath); len(backlinks) != 0 {
+		t.Errorf("Expected no backlinks to the older standup but got %+v", backlinks)
+	}
+	if broken := index.GetBrokenLinks(); len(broken) != 1 || broken[0].Target != "work/missing" {
+		t.Errorf("Expected the one broken link to be 'work/missing' but got %+v", broken)
+	}
+}
+
+func TestRewriteKeepsEachLinksStyle(t *testing.T) {
+	store := journal_store.New(t.TempDir())
+	timestamp := time.Date(2023, time.April, 15, 10, 15, 0, 0, time.Local)
+	oldItem, err := store.Create(timestamp, "standup.md", "work", "")
+	if err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+	body := "[[standup]], [[standup.md|notes]], [[work/Standup]] and [[other]]"
+	notes, err := store.Create(timestamp.Add(time.Hour), "notes.md", "", body)
+	if err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+	index, err := BuildIndex(store)
+	if err != nil {
+		t.Fatalf("Expected no error building the index but got: %v", err)
+	}
+	newItem, err := store.Move(oldItem.Path, "work", "daily.md")
+	if err != nil {
+		t.Fatalf("Expected no error renaming the entry but got: %v", err)
+	}
+
+	rewritten, numRewritten := Rewrite(body, index.GetLinks(notes.Path), oldItem.Path, newItem)
+	expected := "[[daily]], [[daily.md|notes]], [[work/daily]] and [[other]]"
+	if rewritten != expected || numRewritten != 3 {
+		t.Errorf("Expected '%s' with 3 links rewritten but got '%s' with %d", expected, rewritten, numRewritten)
+	}
+}
+
+func TestRewriteLeavesLinksToOtherEntriesWithTheSameName(t *testing.T) {
+	store := journal_store.New(t.TempDir())
+	timestamp := time.Date(2023, time.April, 15, 10, 15, 0, 0, time.Local)
+	personalStandup, err := store.Create(timestamp, "standup.md", "personal", "")
+	if err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+	if _, err := store.Create(timestamp.Add(time.Hour), "standup.md", "work", ""); err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+	body := "[[standup]] and [[personal/standup]]"
+	notes, err := store.Create(timestamp.Add(2*time.Hour), "notes.md", "", body)
+	if err != nil {
+		t.Fatalf("Expected no error creating the entry but got: %v", err)
+	}
+	index, err := BuildIndex(store)
+	if err != nil {
+		t.Fatalf("Expected no error building the index but got: %v", err)
+	}
+	newItem, err := store.Move(personalStandup.Path, "personal", "daily.md")
+	if err != nil {
+		t.Fatalf("Expected no error renaming the entry but got: %v", err)
+	}
+
+	rewritten, numRewritten := Rewrite(body, index.GetLinks(notes.Path), personalStandup.Path, newItem)
+	expected := "[[standup]] and [[personal/daily]]"
+	if rewritten != expected || numRewritten != 1 {
+		t.Errorf("Expected '%s' with only the link to the renamed entry rewritten, but got '%s' with %d", expected, rewritten, numRewritten)
+	}
+}